@@ -1,14 +1,24 @@
 package handler
 
 import (
+	"context"
 	"intern-project-v2/config"
 	_ "intern-project-v2/docs"
+	"intern-project-v2/domain"
 	appHandler "intern-project-v2/handler"
+	"intern-project-v2/logger"
+	"intern-project-v2/media"
 	"intern-project-v2/middleware"
+	"intern-project-v2/notification"
+	"intern-project-v2/payment"
+	"intern-project-v2/repository/memory"
 	"intern-project-v2/repository/mongodb"
 	"intern-project-v2/usecase"
+	"intern-project-v2/utils"
+	"intern-project-v2/webhook"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -35,6 +45,13 @@ func initializeApp() {
 	// Set production mode cho Vercel
 	gin.SetMode(gin.ReleaseMode)
 
+	// Load the token signing keys; without them no login works
+	jwtKeys, err := config.JWTKeysFromEnv()
+	if err != nil {
+		panic("Failed to load JWT signing keys: " + err.Error())
+	}
+	utils.SetKeySet(jwtKeys)
+
 	// Initialize database connection
 	db, err := config.ConnectDB()
 	if err != nil {
@@ -45,7 +62,12 @@ func initializeApp() {
 	config.InitCache()
 
 	// Setup dependencies
-	deps := setupDependencies(db)
+	// Background jobs are not started here: serverless instances are frozen
+	// between requests and do not live long enough to run them. Events stay
+	// in the outbox, webhooks are not sent and deleted records are not purged
+	// until the app server (main.go) runs against the same database.
+	deps := setupDependencies(db, jwtKeys)
+	logger.Warn("Background jobs do not run on serverless instances, run the app server to relay events, send webhooks, purge deleted records and detect abandoned carts")
 
 	// Setup router
 	router = setupRouter(deps)
@@ -58,6 +80,7 @@ type Dependencies struct {
 		Create(c *gin.Context)
 		Update(c *gin.Context)
 		Delete(c *gin.Context)
+		Restore(c *gin.Context)
 	}
 	ProductHandler interface {
 		GetAll(c *gin.Context)
@@ -65,6 +88,15 @@ type Dependencies struct {
 		Create(c *gin.Context)
 		Update(c *gin.Context)
 		Delete(c *gin.Context)
+		Restore(c *gin.Context)
+		Search(c *gin.Context)
+		SetOptions(c *gin.Context)
+		UpdateVariant(c *gin.Context)
+		AddImage(c *gin.Context)
+		DeleteImage(c *gin.Context)
+		ReorderImages(c *gin.Context)
+		Import(c *gin.Context)
+		Export(c *gin.Context)
 	}
 	OrderHandler interface {
 		GetAll(c *gin.Context)
@@ -72,6 +104,9 @@ type Dependencies struct {
 		Create(c *gin.Context)
 		Update(c *gin.Context)
 		Delete(c *gin.Context)
+		Restore(c *gin.Context)
+		Reorder(c *gin.Context)
+		GetByCustomer(c *gin.Context)
 	}
 	CartHandler interface {
 		AddToCart(c *gin.Context)
@@ -79,45 +114,266 @@ type Dependencies struct {
 		UpdateCartItem(c *gin.Context)
 		RemoveCartItem(c *gin.Context)
 		ClearCart(c *gin.Context)
+		BatchUpdate(c *gin.Context)
 	}
 	AuthHandler interface {
 		Register(c *gin.Context)
 		Login(c *gin.Context)
+		VerifyMFA(c *gin.Context)
+		ForgotPassword(c *gin.Context)
+		ResetPassword(c *gin.Context)
+		VerifyEmail(c *gin.Context)
+		ResendVerification(c *gin.Context)
+	}
+	ReturnHandler interface {
+		RequestReturn(c *gin.Context)
+		GetByOrderID(c *gin.Context)
+		GetByID(c *gin.Context)
+		GetAll(c *gin.Context)
+		Approve(c *gin.Context)
+		Reject(c *gin.Context)
+	}
+	CategoryHandler interface {
+		GetAll(c *gin.Context)
+		GetByID(c *gin.Context)
+		GetProducts(c *gin.Context)
+		Create(c *gin.Context)
+		Update(c *gin.Context)
+		Delete(c *gin.Context)
+	}
+	WishlistHandler interface {
+		Get(c *gin.Context)
+		AddItem(c *gin.Context)
+		RemoveItem(c *gin.Context)
+		MoveToCart(c *gin.Context)
+		SaveForLater(c *gin.Context)
+	}
+	ReviewHandler interface {
+		GetByProduct(c *gin.Context)
+		Submit(c *gin.Context)
+		Update(c *gin.Context)
+		Delete(c *gin.Context)
+		GetAll(c *gin.Context)
+		Moderate(c *gin.Context)
+	}
+	ReportHandler interface {
+		Revenue(c *gin.Context)
+		TopProducts(c *gin.Context)
+		OrderValue(c *gin.Context)
+		CustomerMix(c *gin.Context)
+		LowStock(c *gin.Context)
+	}
+	InventoryHandler interface {
+		Adjust(c *gin.Context)
+		GetMovements(c *gin.Context)
+		Verify(c *gin.Context)
+		Reconcile(c *gin.Context)
+		SetReorderThreshold(c *gin.Context)
+		Transfer(c *gin.Context)
+	}
+	WarehouseHandler interface {
+		GetAll(c *gin.Context)
+		GetByID(c *gin.Context)
+		Create(c *gin.Context)
+		Update(c *gin.Context)
+	}
+	WebhookHandler interface {
+		GetAll(c *gin.Context)
+		GetByID(c *gin.Context)
+		Create(c *gin.Context)
+		Update(c *gin.Context)
+		Delete(c *gin.Context)
+		GetDeliveries(c *gin.Context)
+		SendTest(c *gin.Context)
+		Retry(c *gin.Context)
+	}
+	AccountHandler interface {
+		Get(c *gin.Context)
+		UpdateProfile(c *gin.Context)
+		ChangePassword(c *gin.Context)
+		ChangeEmail(c *gin.Context)
+		Delete(c *gin.Context)
+		EnrollMFA(c *gin.Context)
+		ConfirmMFA(c *gin.Context)
+		DisableMFA(c *gin.Context)
+		RegenerateRecoveryCodes(c *gin.Context)
+	}
+	PrivacyHandler interface {
+		Export(c *gin.Context)
+		Erase(c *gin.Context)
 	}
+	JWKSHandler interface {
+		Get(c *gin.Context)
+	}
+	APIKeyHandler interface {
+		GetAll(c *gin.Context)
+		GetByID(c *gin.Context)
+		Create(c *gin.Context)
+		Revoke(c *gin.Context)
+	}
+	// Sessions rejects tokens of deleted accounts and signed-out sessions.
+	Sessions domain.SessionValidator
+	// APIKeys authenticates server-to-server clients.
+	APIKeys domain.APIKeyAuthenticator
+	// MediaRoot is the directory uploaded media is served from.
+	MediaRoot string
 }
 
-func setupDependencies(db *config.Database) *Dependencies {
-	// Customer dependencies
+func setupDependencies(db *config.Database, jwtKeys *utils.KeySet) *Dependencies {
+	// Repositories
 	customerRepo := mongodb.NewCustomerRepository(db.DB)
-	customerUsecase := usecase.NewCustomerUsecase(customerRepo)
+	productRepo := mongodb.NewProductRepository(db.DB)
+	orderRepo := mongodb.NewOrderRepository(db.DB)
+	cartRepo := mongodb.NewCartRepository(db.DB)
+	authRepo := mongodb.NewAuthRepository(db.DB)
+	authTokenRepo := mongodb.NewAuthTokenRepository(db.DB)
+	returnRepo := mongodb.NewReturnRepository(db.DB)
+	categoryRepo := mongodb.NewCategoryRepository(db.DB)
+	wishlistRepo := mongodb.NewWishlistRepository(db.DB)
+	reviewRepo := mongodb.NewReviewRepository(db.DB)
+	reportRepo := mongodb.NewReportRepository(db.DB)
+	inventoryRepo := mongodb.NewInventoryRepository(db.DB)
+	warehouseRepo := mongodb.NewWarehouseRepository(db.DB)
+	webhookRepo := mongodb.NewWebhookRepository(db.DB)
+	outboxRepo := mongodb.NewOutboxRepository(db.DB)
+	apiKeyRepo := mongodb.NewAPIKeyRepository(db.DB)
+
+	// Event dependencies
+	if err := mongodb.EnsureOutboxIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Outbox index is missing", "error", err)
+	}
+	eventPublisher := mongodb.NewEventPublisher(db.DB)
+	transactor := mongodb.NewTransactor(context.Background(), db.Client)
+
+	// Email dependencies
+	emailNotifier := notification.NewEmailNotifier(notification.SenderFromEnv())
+	emailNotifier.Start(context.Background(), 2)
+
+	// Auth token dependencies
+	if err := mongodb.EnsureAuthTokenIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Auth token indexes are missing, expired links are not cleaned up", "error", err)
+	}
+	authPolicy := config.AuthPolicyFromEnv()
+
+	// Customer dependencies
+	customerUsecase := usecase.NewCustomerUsecase(customerRepo, orderRepo)
 	customerHandler := appHandler.NewCustomerHandler(customerUsecase)
 
+	// Cart dependencies
+	cartUsecase := usecase.NewCartUsecase(cartRepo, productRepo, customerRepo, eventPublisher, transactor)
+	cartHandler := appHandler.NewCartHandler(cartUsecase)
+
+	// Wishlist dependencies
+	if err := mongodb.EnsureWishlistIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Wishlist indexes are missing", "error", err)
+	}
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepo, productRepo, customerRepo, cartUsecase, notification.NewLogNotifier())
+	wishlistHandler := appHandler.NewWishlistHandler(wishlistUsecase)
+
+	// Warehouse dependencies
+	if err := mongodb.EnsureWarehouseIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Warehouse code index is missing", "error", err)
+	}
+	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo)
+	warehouseHandler := appHandler.NewWarehouseHandler(warehouseUsecase)
+
+	// Inventory dependencies
+	if err := mongodb.EnsureInventoryIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Stock movement index is missing", "error", err)
+	}
+	inventoryUsecase := usecase.NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, wishlistUsecase, notification.NewLogNotifier(), eventPublisher, transactor)
+	inventoryHandler := appHandler.NewInventoryHandler(inventoryUsecase)
+
 	// Product dependencies
-	productRepo := mongodb.NewProductRepository(db.DB)
-	productUsecase := usecase.NewProductUsecase(productRepo)
+	if err := mongodb.EnsureProductIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Product SKU index is missing, imports may create duplicates", "error", err)
+	}
+	productSearcher, err := mongodb.NewProductSearcher(context.Background(), db.DB)
+	if err != nil {
+		logger.Warn("Falling back to in-memory product search", "error", err)
+		productSearcher = memory.NewProductSearcher(productRepo)
+	}
+	mediaStore, err := media.NewFilesystemStore(media.RootFromEnv(), "/media")
+	if err != nil {
+		panic("Failed to initialize media store: " + err.Error())
+	}
+	productUsecase := usecase.NewProductUsecase(productRepo, cartRepo, categoryRepo, productSearcher, mediaStore, inventoryUsecase)
 	productHandler := appHandler.NewProductHandler(productUsecase)
 
 	// Order dependencies
-	orderRepo := mongodb.NewOrderRepository(db.DB)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo)
+	if err := mongodb.EnsureOrderIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Order history index is missing", "error", err)
+	}
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, customerRepo, productRepo, cartUsecase, inventoryUsecase, authPolicy, emailNotifier, eventPublisher, transactor)
 	orderHandler := appHandler.NewOrderHandler(orderUsecase)
 
-	// Cart dependencies
-	cartRepo := mongodb.NewCartRepository(db.DB)
-	cartUsecase := usecase.NewCartUsecase(cartRepo, productRepo, customerRepo)
-	cartHandler := appHandler.NewCartHandler(cartUsecase)
-
 	// Auth dependencies
-	authRepo := mongodb.NewAuthRepository(db.DB)
-	authUsecase := usecase.NewAuthUsecase(authRepo)
+	authUsecase := usecase.NewAuthUsecase(authRepo, authTokenRepo, authPolicy, emailNotifier, eventPublisher, transactor)
 	authHandler := appHandler.NewAuthHandler(authUsecase)
+	jwksHandler := appHandler.NewJWKSHandler(jwtKeys)
+
+	// Privacy dependencies
+	privacyUsecase := usecase.NewPrivacyUsecase(customerRepo, orderRepo, returnRepo, reviewRepo, cartRepo, wishlistRepo, authTokenRepo, outboxRepo, webhookRepo, logger.NewFileSearcher(), transactor)
+	privacyHandler := appHandler.NewPrivacyHandler(privacyUsecase)
+
+	// Account dependencies
+	accountUsecase := usecase.NewAccountUsecase(authRepo, customerRepo, authTokenRepo, authPolicy, emailNotifier, privacyUsecase)
+	accountHandler := appHandler.NewAccountHandler(accountUsecase)
+
+	// Return dependencies
+	returnUsecase := usecase.NewReturnUsecase(returnRepo, orderRepo, payment.NewFakeGateway(), inventoryUsecase)
+	returnHandler := appHandler.NewReturnHandler(returnUsecase)
+
+	// Category dependencies
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, productRepo)
+	categoryHandler := appHandler.NewCategoryHandler(categoryUsecase)
+
+	// Review dependencies
+	if err := mongodb.EnsureReviewIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Review indexes are missing, customers may review a product twice", "error", err)
+	}
+	reviewUsecase := usecase.NewReviewUsecase(reviewRepo, productRepo, orderRepo)
+	reviewHandler := appHandler.NewReviewHandler(reviewUsecase)
+
+	// Report dependencies
+	reportUsecase := usecase.NewReportUsecase(reportRepo)
+	reportHandler := appHandler.NewReportHandler(reportUsecase)
+
+	// Webhook dependencies
+	if err := mongodb.EnsureWebhookIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Webhook delivery indexes are missing, events may be sent twice", "error", err)
+	}
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender())
+	webhookHandler := appHandler.NewWebhookHandler(webhookUsecase)
+
+	// API key dependencies
+	if err := mongodb.EnsureAPIKeyIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("API key prefix index is missing", "error", err)
+	}
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	apiKeyHandler := appHandler.NewAPIKeyHandler(apiKeyUsecase)
 
 	return &Dependencies{
-		CustomerHandler: customerHandler,
-		ProductHandler:  productHandler,
-		OrderHandler:    orderHandler,
-		CartHandler:     cartHandler,
-		AuthHandler:     authHandler,
+		CustomerHandler:  customerHandler,
+		ProductHandler:   productHandler,
+		OrderHandler:     orderHandler,
+		CartHandler:      cartHandler,
+		AuthHandler:      authHandler,
+		ReturnHandler:    returnHandler,
+		CategoryHandler:  categoryHandler,
+		WishlistHandler:  wishlistHandler,
+		ReviewHandler:    reviewHandler,
+		ReportHandler:    reportHandler,
+		InventoryHandler: inventoryHandler,
+		WarehouseHandler: warehouseHandler,
+		WebhookHandler:   webhookHandler,
+		AccountHandler:   accountHandler,
+		PrivacyHandler:   privacyHandler,
+		APIKeyHandler:    apiKeyHandler,
+		JWKSHandler:      jwksHandler,
+		Sessions:         authUsecase,
+		APIKeys:          apiKeyUsecase,
+		MediaRoot:        mediaStore.Root(),
 	}
 }
 
@@ -149,7 +405,9 @@ func setupRouter(deps *Dependencies) *gin.Engine {
 		})
 	})
 
+	router.GET("/.well-known/jwks.json", deps.JWKSHandler.Get)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.Static("/media", deps.MediaRoot)
 
 	// Setup API routes
 	setupAPIRoutes(router, deps)
@@ -159,12 +417,19 @@ func setupRouter(deps *Dependencies) *gin.Engine {
 
 func setupAPIRoutes(router *gin.Engine, deps *Dependencies) {
 	api := router.Group("/api")
+	api.Use(middleware.APIKeyAuth(deps.APIKeys))
+	api.Use(middleware.OptionalJWTAuth(deps.Sessions))
 	{
 		// Auth routes
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", deps.AuthHandler.Register)
 			auth.POST("/login", deps.AuthHandler.Login)
+			auth.POST("/forgot-password", deps.AuthHandler.ForgotPassword)
+			auth.POST("/reset-password", deps.AuthHandler.ResetPassword)
+			auth.GET("/verify-email", deps.AuthHandler.VerifyEmail)
+			auth.POST("/resend-verification", deps.AuthHandler.ResendVerification)
+			auth.POST("/mfa/verify", deps.AuthHandler.VerifyMFA)
 		}
 
 		// Customer routes
@@ -173,18 +438,23 @@ func setupAPIRoutes(router *gin.Engine, deps *Dependencies) {
 			customers.GET("/", deps.CustomerHandler.GetAll)
 			customers.GET("/:id", deps.CustomerHandler.GetByID)
 			customers.POST("/", deps.CustomerHandler.Create)
-			customers.PUT("/:id", deps.CustomerHandler.Update)
-			customers.DELETE("/:id", deps.CustomerHandler.Delete)
 		}
 
 		// Product routes
 		products := api.Group("/products")
 		{
-			products.GET("/", middleware.CacheMiddleware(15*60, deps.ProductHandler.GetAll))
-			products.GET("/:id", middleware.CacheMiddleware(15*60, deps.ProductHandler.GetByID))
-			products.POST("/", deps.ProductHandler.Create)
-			products.PUT("/:id", deps.ProductHandler.Update)
-			products.DELETE("/:id", deps.ProductHandler.Delete)
+			products.GET("/", middleware.CacheMiddleware(15*time.Minute, deps.ProductHandler.GetAll))
+			products.GET("/search", middleware.CacheMiddleware(5*time.Minute, deps.ProductHandler.Search))
+			products.GET("/:id", middleware.CacheMiddleware(15*time.Minute, deps.ProductHandler.GetByID))
+			products.GET("/:id/reviews", deps.ReviewHandler.GetByProduct)
+		}
+
+		// Category routes
+		categories := api.Group("/categories")
+		{
+			categories.GET("/", deps.CategoryHandler.GetAll)
+			categories.GET("/:id", deps.CategoryHandler.GetByID)
+			categories.GET("/:id/products", deps.CategoryHandler.GetProducts)
 		}
 
 		// Order routes
@@ -207,10 +477,94 @@ func setupAPIRoutes(router *gin.Engine, deps *Dependencies) {
 
 		// Protected routes
 		protected := api.Group("/")
-		protected.Use(middleware.JWTAuth())
+		protected.Use(middleware.JWTAuth(deps.Sessions))
 		{
 			protected.GET("/customers/:id/cart", deps.CartHandler.GetCartByCustomerId)
 			protected.POST("/customers/:id/cart/item", deps.CartHandler.AddToCart)
+			protected.PUT("/customers/:id/cart", deps.CartHandler.BatchUpdate)
+			protected.POST("/customers/:id/cart/save-for-later", deps.WishlistHandler.SaveForLater)
+			protected.GET("/customers/:id/wishlist", deps.WishlistHandler.Get)
+			protected.POST("/customers/:id/wishlist/items", deps.WishlistHandler.AddItem)
+			protected.DELETE("/customers/:id/wishlist/items/:product_id", deps.WishlistHandler.RemoveItem)
+			protected.POST("/customers/:id/wishlist/move-to-cart", deps.WishlistHandler.MoveToCart)
+			protected.POST("/products/:id/reviews", deps.ReviewHandler.Submit)
+			protected.PUT("/reviews/:id", deps.ReviewHandler.Update)
+			protected.DELETE("/reviews/:id", deps.ReviewHandler.Delete)
+			protected.GET("/customers/:id/orders", deps.OrderHandler.GetByCustomer)
+			protected.GET("/customers/:id/export", deps.PrivacyHandler.Export)
+			protected.POST("/orders/:id/reorder", deps.OrderHandler.Reorder)
+			protected.POST("/orders/:id/returns", deps.ReturnHandler.RequestReturn)
+			protected.GET("/orders/:id/returns", deps.ReturnHandler.GetByOrderID)
+			protected.GET("/returns/:id", deps.ReturnHandler.GetByID)
+			protected.GET("/me", deps.AccountHandler.Get)
+			protected.PUT("/me", deps.AccountHandler.UpdateProfile)
+			protected.PUT("/me/password", deps.AccountHandler.ChangePassword)
+			protected.PUT("/me/email", deps.AccountHandler.ChangeEmail)
+			protected.DELETE("/me", deps.AccountHandler.Delete)
+			protected.POST("/me/mfa/enroll", deps.AccountHandler.EnrollMFA)
+			protected.POST("/me/mfa/confirm", deps.AccountHandler.ConfirmMFA)
+			protected.DELETE("/me/mfa", deps.AccountHandler.DisableMFA)
+			protected.POST("/me/mfa/recovery-codes", deps.AccountHandler.RegenerateRecoveryCodes)
+		}
+
+		// Admin routes
+		admin := protected.Group("/")
+		admin.Use(middleware.RequireRole(domain.RoleAdmin))
+		{
+			admin.GET("/returns", deps.ReturnHandler.GetAll)
+			admin.POST("/returns/:id/approve", deps.ReturnHandler.Approve)
+			admin.POST("/returns/:id/reject", deps.ReturnHandler.Reject)
+			admin.GET("/reviews", deps.ReviewHandler.GetAll)
+			admin.POST("/reviews/:id/moderate", deps.ReviewHandler.Moderate)
+			admin.GET("/admin/reports/revenue", deps.ReportHandler.Revenue)
+			admin.GET("/admin/reports/top-products", deps.ReportHandler.TopProducts)
+			admin.GET("/admin/reports/order-value", deps.ReportHandler.OrderValue)
+			admin.GET("/admin/reports/customers", deps.ReportHandler.CustomerMix)
+			admin.GET("/admin/reports/low-stock", deps.ReportHandler.LowStock)
+			admin.PUT("/customers/:id", deps.CustomerHandler.Update)
+			admin.DELETE("/customers/:id", deps.CustomerHandler.Delete)
+			admin.POST("/customers/:id/restore", deps.CustomerHandler.Restore)
+			admin.POST("/customers/:id/erase", deps.PrivacyHandler.Erase)
+			admin.POST("/products/", deps.ProductHandler.Create)
+			admin.PUT("/products/:id", deps.ProductHandler.Update)
+			admin.DELETE("/products/:id", deps.ProductHandler.Delete)
+			admin.POST("/products/:id/restore", deps.ProductHandler.Restore)
+			admin.PUT("/products/:id/options", deps.ProductHandler.SetOptions)
+			admin.PATCH("/products/:id/variants/:variant_id", deps.ProductHandler.UpdateVariant)
+			admin.POST("/products/:id/images", deps.ProductHandler.AddImage)
+			admin.PUT("/products/:id/images/order", deps.ProductHandler.ReorderImages)
+			admin.DELETE("/products/:id/images/:image_id", deps.ProductHandler.DeleteImage)
+			admin.POST("/products/import", deps.ProductHandler.Import)
+			admin.GET("/products/export", deps.ProductHandler.Export)
+			admin.POST("/products/:id/inventory/adjustments", deps.InventoryHandler.Adjust)
+			admin.GET("/products/:id/inventory/movements", deps.InventoryHandler.GetMovements)
+			admin.GET("/products/:id/inventory/verify", deps.InventoryHandler.Verify)
+			admin.POST("/products/:id/inventory/reconcile", deps.InventoryHandler.Reconcile)
+			admin.PUT("/products/:id/inventory/threshold", deps.InventoryHandler.SetReorderThreshold)
+			admin.POST("/products/:id/inventory/transfers", deps.InventoryHandler.Transfer)
+
+			admin.GET("/warehouses", deps.WarehouseHandler.GetAll)
+			admin.POST("/warehouses", deps.WarehouseHandler.Create)
+			admin.GET("/warehouses/:id", deps.WarehouseHandler.GetByID)
+			admin.PUT("/warehouses/:id", deps.WarehouseHandler.Update)
+
+			admin.GET("/webhooks", deps.WebhookHandler.GetAll)
+			admin.POST("/webhooks", deps.WebhookHandler.Create)
+			admin.GET("/webhooks/:id", deps.WebhookHandler.GetByID)
+			admin.PUT("/webhooks/:id", deps.WebhookHandler.Update)
+			admin.DELETE("/webhooks/:id", deps.WebhookHandler.Delete)
+			admin.GET("/webhooks/:id/deliveries", deps.WebhookHandler.GetDeliveries)
+			admin.POST("/webhooks/:id/test", deps.WebhookHandler.SendTest)
+			admin.POST("/webhooks/:id/deliveries/:deliveryId/retry", deps.WebhookHandler.Retry)
+
+			admin.GET("/api-keys", deps.APIKeyHandler.GetAll)
+			admin.POST("/api-keys", deps.APIKeyHandler.Create)
+			admin.GET("/api-keys/:id", deps.APIKeyHandler.GetByID)
+			admin.POST("/api-keys/:id/revoke", deps.APIKeyHandler.Revoke)
+			admin.POST("/orders/:id/restore", deps.OrderHandler.Restore)
+			admin.POST("/categories", deps.CategoryHandler.Create)
+			admin.PUT("/categories/:id", deps.CategoryHandler.Update)
+			admin.DELETE("/categories/:id", deps.CategoryHandler.Delete)
 		}
 	}
 }
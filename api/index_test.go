@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"intern-project-v2/config"
+	"intern-project-v2/domain"
+	"intern-project-v2/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// stubHandler stands in for every handler, so the routes can be registered
+// without a database. It answers 200 to anything that reaches it.
+type stubHandler struct{}
+
+func (stubHandler) AddImage(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) AddItem(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) AddToCart(c *gin.Context)               { c.Status(http.StatusOK) }
+func (stubHandler) Adjust(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) Approve(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) BatchUpdate(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) ChangeEmail(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) ChangePassword(c *gin.Context)          { c.Status(http.StatusOK) }
+func (stubHandler) ClearCart(c *gin.Context)               { c.Status(http.StatusOK) }
+func (stubHandler) ConfirmMFA(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) Create(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) CustomerMix(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) Delete(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) DeleteImage(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) DisableMFA(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) EnrollMFA(c *gin.Context)               { c.Status(http.StatusOK) }
+func (stubHandler) Erase(c *gin.Context)                   { c.Status(http.StatusOK) }
+func (stubHandler) Export(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) ForgotPassword(c *gin.Context)          { c.Status(http.StatusOK) }
+func (stubHandler) Get(c *gin.Context)                     { c.Status(http.StatusOK) }
+func (stubHandler) GetAll(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) GetByCustomer(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) GetByID(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) GetByOrderID(c *gin.Context)            { c.Status(http.StatusOK) }
+func (stubHandler) GetByProduct(c *gin.Context)            { c.Status(http.StatusOK) }
+func (stubHandler) GetCartByCustomerId(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandler) GetDeliveries(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) GetMovements(c *gin.Context)            { c.Status(http.StatusOK) }
+func (stubHandler) GetProducts(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) Import(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) Login(c *gin.Context)                   { c.Status(http.StatusOK) }
+func (stubHandler) LowStock(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) Moderate(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) MoveToCart(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) OrderValue(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) Reconcile(c *gin.Context)               { c.Status(http.StatusOK) }
+func (stubHandler) RegenerateRecoveryCodes(c *gin.Context) { c.Status(http.StatusOK) }
+func (stubHandler) Register(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) Reject(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) RemoveCartItem(c *gin.Context)          { c.Status(http.StatusOK) }
+func (stubHandler) RemoveItem(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) Reorder(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) ReorderImages(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) RequestReturn(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) ResendVerification(c *gin.Context)      { c.Status(http.StatusOK) }
+func (stubHandler) ResetPassword(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) Restore(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) Retry(c *gin.Context)                   { c.Status(http.StatusOK) }
+func (stubHandler) Revenue(c *gin.Context)                 { c.Status(http.StatusOK) }
+func (stubHandler) Revoke(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) SaveForLater(c *gin.Context)            { c.Status(http.StatusOK) }
+func (stubHandler) Search(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) SendTest(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) SetOptions(c *gin.Context)              { c.Status(http.StatusOK) }
+func (stubHandler) SetReorderThreshold(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandler) Submit(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) TopProducts(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) Transfer(c *gin.Context)                { c.Status(http.StatusOK) }
+func (stubHandler) Update(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) UpdateCartItem(c *gin.Context)          { c.Status(http.StatusOK) }
+func (stubHandler) UpdateProfile(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) UpdateVariant(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandler) Verify(c *gin.Context)                  { c.Status(http.StatusOK) }
+func (stubHandler) VerifyEmail(c *gin.Context)             { c.Status(http.StatusOK) }
+func (stubHandler) VerifyMFA(c *gin.Context)               { c.Status(http.StatusOK) }
+
+// roleProductHandler answers the product listing with the role of the caller,
+// so a test can tell whose response it was served.
+type roleProductHandler struct {
+	stubHandler
+}
+
+func (roleProductHandler) GetAll(c *gin.Context) {
+	c.String(http.StatusOK, "role=%s", c.GetString("role"))
+}
+
+// stubAPIKeys accepts one key, scoped to reading products.
+type stubAPIKeys struct{}
+
+const testAPIKey = domain.APIKeyPrefix + "test_secret"
+
+func (stubAPIKeys) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	if key != testAPIKey {
+		return nil, domain.ErrUnauthorized
+	}
+	return &domain.APIKey{Id: bson.NewObjectID(), Prefix: domain.APIKeyPrefix + "test", Scopes: []string{"products:read"}, RateLimit: domain.MaxAPIKeyRateLimit}, nil
+}
+
+func newTestRouter(t *testing.T, configure func(*Dependencies)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := utils.NewKeySet(&utils.SigningKey{ID: "test", Private: private})
+	assert.NoError(t, err)
+	utils.SetKeySet(keys)
+	t.Cleanup(func() { utils.SetKeySet(nil) })
+
+	stub := stubHandler{}
+	deps := &Dependencies{
+		CustomerHandler:  stub,
+		ProductHandler:   stub,
+		OrderHandler:     stub,
+		CartHandler:      stub,
+		AuthHandler:      stub,
+		ReturnHandler:    stub,
+		CategoryHandler:  stub,
+		WishlistHandler:  stub,
+		ReviewHandler:    stub,
+		ReportHandler:    stub,
+		InventoryHandler: stub,
+		WarehouseHandler: stub,
+		WebhookHandler:   stub,
+		AccountHandler:   stub,
+		PrivacyHandler:   stub,
+		JWKSHandler:      stub,
+		APIKeyHandler:    stub,
+	}
+	if configure != nil {
+		configure(deps)
+	}
+	config.InitCache()
+	router := gin.New()
+	setupAPIRoutes(router, deps)
+	return router
+}
+
+func TestSetupAPIRoutes_AdminOnlyProductRoutes(t *testing.T) {
+	router := newTestRouter(t, nil)
+	customerToken, err := utils.GenerateJWT("customer-1", "john@example.com", domain.RoleCustomer, false)
+	assert.NoError(t, err)
+	adminToken, err := utils.GenerateJWT("admin-1", "admin@example.com", domain.RoleAdmin, true)
+	assert.NoError(t, err)
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/products/"},
+		{http.MethodPut, "/api/products/p1"},
+		{http.MethodDelete, "/api/products/p1"},
+		{http.MethodPost, "/api/products/p1/images"},
+		{http.MethodPut, "/api/products/p1/images/order"},
+		{http.MethodDelete, "/api/products/p1/images/i1"},
+		{http.MethodPut, "/api/products/p1/options"},
+		{http.MethodPatch, "/api/products/p1/variants/v1"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			tests := []struct {
+				name           string
+				token          string
+				expectedStatus int
+			}{
+				{name: "No token", expectedStatus: http.StatusUnauthorized},
+				{name: "Customer token", token: customerToken, expectedStatus: http.StatusForbidden},
+				{name: "Admin token", token: adminToken, expectedStatus: http.StatusOK},
+			}
+			for _, tt := range tests {
+				// Arrange
+				req := httptest.NewRequest(route.method, route.path, nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				rec := httptest.NewRecorder()
+
+				// Act
+				router.ServeHTTP(rec, req)
+
+				// Assert
+				assert.Equal(t, tt.expectedStatus, rec.Code, tt.name)
+			}
+		})
+	}
+}
+
+func TestSetupAPIRoutes_CredentialedRequestsBypassPageCache(t *testing.T) {
+	router := newTestRouter(t, func(deps *Dependencies) {
+		deps.ProductHandler = roleProductHandler{}
+		deps.APIKeys = stubAPIKeys{}
+	})
+	path := "/api/products/?include_deleted=true"
+
+	// Arrange
+	withKey := httptest.NewRequest(http.MethodGet, path, nil)
+	withKey.Header.Set(domain.APIKeyHeader, testAPIKey)
+	anonymous := httptest.NewRequest(http.MethodGet, path, nil)
+
+	// Act
+	keyRec := httptest.NewRecorder()
+	router.ServeHTTP(keyRec, withKey)
+	anonymousRec := httptest.NewRecorder()
+	router.ServeHTTP(anonymousRec, anonymous)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, keyRec.Code)
+	assert.Equal(t, "role=admin", keyRec.Body.String())
+	assert.Equal(t, http.StatusOK, anonymousRec.Code)
+	assert.Equal(t, "role=", anonymousRec.Body.String())
+}
@@ -1,12 +1,23 @@
 package app
 
 import (
+	"context"
 	"intern-project-v2/config"
 	_ "intern-project-v2/docs"
+	"intern-project-v2/domain"
+	"intern-project-v2/events"
 	"intern-project-v2/handler"
+	"intern-project-v2/logger"
+	"intern-project-v2/media"
 	"intern-project-v2/middleware"
+	"intern-project-v2/notification"
+	"intern-project-v2/payment"
+	"intern-project-v2/repository/memory"
 	"intern-project-v2/repository/mongodb"
 	"intern-project-v2/usecase"
+	"intern-project-v2/utils"
+	"intern-project-v2/webhook"
+	"intern-project-v2/worker"
 	"os"
 	"time"
 
@@ -31,6 +42,12 @@ func init() {
 
 func Run() {
 
+	jwtKeys, err := config.JWTKeysFromEnv()
+	if err != nil {
+		panic("Failed to load JWT signing keys: " + err.Error())
+	}
+	utils.SetKeySet(jwtKeys)
+
 	db, err := config.ConnectDB()
 	{
 		if err != nil {
@@ -38,24 +55,125 @@ func Run() {
 		}
 	}
 	customerRepo := mongodb.NewCustomerRepository(db.DB)
-	customerUsecase := usecase.NewCustomerUsecase(customerRepo)
+	productRepo := mongodb.NewProductRepository(db.DB)
+	orderRepo := mongodb.NewOrderRepository(db.DB)
+	cartRepo := mongodb.NewCartRepository(db.DB)
+	authRepo := mongodb.NewAuthRepository(db.DB)
+	authTokenRepo := mongodb.NewAuthTokenRepository(db.DB)
+	returnRepo := mongodb.NewReturnRepository(db.DB)
+	categoryRepo := mongodb.NewCategoryRepository(db.DB)
+	wishlistRepo := mongodb.NewWishlistRepository(db.DB)
+	reviewRepo := mongodb.NewReviewRepository(db.DB)
+	reportRepo := mongodb.NewReportRepository(db.DB)
+	inventoryRepo := mongodb.NewInventoryRepository(db.DB)
+	warehouseRepo := mongodb.NewWarehouseRepository(db.DB)
+	outboxRepo := mongodb.NewOutboxRepository(db.DB)
+	webhookRepo := mongodb.NewWebhookRepository(db.DB)
+	apiKeyRepo := mongodb.NewAPIKeyRepository(db.DB)
+
+	if err := mongodb.EnsureOutboxIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Outbox index is missing", "error", err)
+	}
+	eventPublisher := mongodb.NewEventPublisher(db.DB)
+	transactor := mongodb.NewTransactor(context.Background(), db.Client)
+	emailNotifier := notification.NewEmailNotifier(notification.SenderFromEnv())
+	emailNotifier.Start(context.Background(), 2)
+
+	if err := mongodb.EnsureAuthTokenIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Auth token indexes are missing, expired links are not cleaned up", "error", err)
+	}
+	authPolicy := config.AuthPolicyFromEnv()
+
+	customerUsecase := usecase.NewCustomerUsecase(customerRepo, orderRepo)
 	customerHandler := handler.NewCustomerHandler(customerUsecase)
 
-	productRepo := mongodb.NewProductRepository(db.DB)
-	productUsecase := usecase.NewProductUsecase(productRepo)
+	cartUsecase := usecase.NewCartUsecase(cartRepo, productRepo, customerRepo, eventPublisher, transactor)
+	cartHandler := handler.NewCartHandler(cartUsecase)
+
+	if err := mongodb.EnsureWishlistIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Wishlist indexes are missing", "error", err)
+	}
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepo, productRepo, customerRepo, cartUsecase, notification.NewLogNotifier())
+	wishlistHandler := handler.NewWishlistHandler(wishlistUsecase)
+
+	if err := mongodb.EnsureWarehouseIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Warehouse code index is missing", "error", err)
+	}
+	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseUsecase)
+
+	if err := mongodb.EnsureInventoryIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Stock movement index is missing", "error", err)
+	}
+	inventoryUsecase := usecase.NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, wishlistUsecase, notification.NewLogNotifier(), eventPublisher, transactor)
+	inventoryHandler := handler.NewInventoryHandler(inventoryUsecase)
+
+	if err := mongodb.EnsureProductIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Product SKU index is missing, imports may create duplicates", "error", err)
+	}
+	productSearcher, err := mongodb.NewProductSearcher(context.Background(), db.DB)
+	if err != nil {
+		logger.Warn("Falling back to in-memory product search", "error", err)
+		productSearcher = memory.NewProductSearcher(productRepo)
+	}
+	mediaStore, err := media.NewFilesystemStore(media.RootFromEnv(), "/media")
+	if err != nil {
+		panic("Failed to initialize media store: " + err.Error())
+	}
+	productUsecase := usecase.NewProductUsecase(productRepo, cartRepo, categoryRepo, productSearcher, mediaStore, inventoryUsecase)
 	productHandler := handler.NewProductHandler(productUsecase)
 
-	orderRepo := mongodb.NewOrderRepository(db.DB)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo)
+	if err := mongodb.EnsureOrderIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Order history index is missing", "error", err)
+	}
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, customerRepo, productRepo, cartUsecase, inventoryUsecase, authPolicy, emailNotifier, eventPublisher, transactor)
 	orderHandler := handler.NewOrderHandler(orderUsecase)
 
-	cartRepo := mongodb.NewCartRepository(db.DB)
-	cartUsecase := usecase.NewCartUsecase(cartRepo, productRepo, customerRepo)
-	cartHandler := handler.NewCartHandler(cartUsecase)
-
-	authRepo := mongodb.NewAuthRepository(db.DB)
-	authUsecase := usecase.NewAuthUsecase(authRepo)
+	authUsecase := usecase.NewAuthUsecase(authRepo, authTokenRepo, authPolicy, emailNotifier, eventPublisher, transactor)
 	authHandler := handler.NewAuthHandler(authUsecase)
+	jwksHandler := handler.NewJWKSHandler(jwtKeys)
+
+	privacyUsecase := usecase.NewPrivacyUsecase(customerRepo, orderRepo, returnRepo, reviewRepo, cartRepo, wishlistRepo, authTokenRepo, outboxRepo, webhookRepo, logger.NewFileSearcher(), transactor)
+	privacyHandler := handler.NewPrivacyHandler(privacyUsecase)
+
+	accountUsecase := usecase.NewAccountUsecase(authRepo, customerRepo, authTokenRepo, authPolicy, emailNotifier, privacyUsecase)
+	accountHandler := handler.NewAccountHandler(accountUsecase)
+
+	returnUsecase := usecase.NewReturnUsecase(returnRepo, orderRepo, payment.NewFakeGateway(), inventoryUsecase)
+	returnHandler := handler.NewReturnHandler(returnUsecase)
+
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, productRepo)
+	categoryHandler := handler.NewCategoryHandler(categoryUsecase)
+
+	if err := mongodb.EnsureReviewIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Review indexes are missing, customers may review a product twice", "error", err)
+	}
+	reviewUsecase := usecase.NewReviewUsecase(reviewRepo, productRepo, orderRepo)
+	reviewHandler := handler.NewReviewHandler(reviewUsecase)
+
+	reportUsecase := usecase.NewReportUsecase(reportRepo)
+	reportHandler := handler.NewReportHandler(reportUsecase)
+
+	if err := mongodb.EnsureWebhookIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("Webhook delivery indexes are missing, events may be sent twice", "error", err)
+	}
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender())
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+
+	if err := mongodb.EnsureAPIKeyIndexes(context.Background(), db.DB); err != nil {
+		logger.Warn("API key prefix index is missing", "error", err)
+	}
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUsecase)
+
+	worker.StartPurgeJob(context.Background(), time.Hour, worker.RetentionFromEnv(), map[string]worker.Purger{
+		"customers": customerUsecase,
+		"products":  productUsecase,
+		"orders":    orderUsecase,
+	})
+	worker.StartAbandonedCartJob(context.Background(), 15*time.Minute, worker.CartIdleFromEnv(), cartUsecase)
+	worker.StartOutboxRelay(context.Background(), 5*time.Second, worker.NewOutboxRelay(outboxRepo, append(events.SinksFromEnv(), events.NewSubscriptionSink(webhookUsecase))...))
+	worker.StartWebhookJob(context.Background(), 10*time.Second, webhookUsecase)
 
 	router := gin.Default()
 
@@ -73,33 +191,45 @@ func Run() {
 			"message": "API is running",
 		})
 	})
+	router.GET("/.well-known/jwks.json", jwksHandler.Get)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.Static("/media", mediaStore.Root())
 
 	config.InitCache() // Initialize cache store
 
 	api := router.Group("/api")
+	api.Use(middleware.APIKeyAuth(apiKeyUsecase))
+	api.Use(middleware.OptionalJWTAuth(authUsecase))
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.GET("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
 		}
 		customers := api.Group("/customers")
 		{
 			customers.GET("/", customerHandler.GetAll)
 			customers.GET("/:id", customerHandler.GetByID)
 			customers.POST("/", customerHandler.Create)
-			customers.PUT("/:id", customerHandler.Update)
-			customers.DELETE("/:id", customerHandler.Delete)
 		}
 		products := api.Group("/products")
 
 		{
 			products.GET("/", middleware.CacheMiddleware(time.Minute*15, productHandler.GetAll))
+			products.GET("/search", middleware.CacheMiddleware(time.Minute*5, productHandler.Search))
 			products.GET("/:id", middleware.CacheMiddleware(time.Minute*15, productHandler.GetByID))
-			products.POST("/", productHandler.Create)
-			products.PUT("/:id", productHandler.Update)
-			products.DELETE("/:id", productHandler.Delete)
+			products.GET("/:id/reviews", reviewHandler.GetByProduct)
+		}
+		categories := api.Group("/categories")
+		{
+			categories.GET("/", categoryHandler.GetAll)
+			categories.GET("/:id", categoryHandler.GetByID)
+			categories.GET("/:id/products", categoryHandler.GetProducts)
 		}
 		orders := api.Group("/orders")
 		{
@@ -119,10 +249,92 @@ func Run() {
 		}
 	}
 	protected := api.Group("/")
-	protected.Use(middleware.JWTAuth())
+	protected.Use(middleware.JWTAuth(authUsecase))
 	{
 		protected.GET("/customers/:id/cart", cartHandler.GetCartByCustomerId)
 		protected.POST("/customers/:id/cart/item", cartHandler.AddToCart)
+		protected.PUT("/customers/:id/cart", cartHandler.BatchUpdate)
+		protected.POST("/customers/:id/cart/save-for-later", wishlistHandler.SaveForLater)
+		protected.GET("/customers/:id/wishlist", wishlistHandler.Get)
+		protected.POST("/customers/:id/wishlist/items", wishlistHandler.AddItem)
+		protected.DELETE("/customers/:id/wishlist/items/:product_id", wishlistHandler.RemoveItem)
+		protected.POST("/customers/:id/wishlist/move-to-cart", wishlistHandler.MoveToCart)
+		protected.POST("/products/:id/reviews", reviewHandler.Submit)
+		protected.PUT("/reviews/:id", reviewHandler.Update)
+		protected.DELETE("/reviews/:id", reviewHandler.Delete)
+		protected.GET("/customers/:id/orders", orderHandler.GetByCustomer)
+		protected.GET("/customers/:id/export", privacyHandler.Export)
+		protected.POST("/orders/:id/reorder", orderHandler.Reorder)
+		protected.POST("/orders/:id/returns", returnHandler.RequestReturn)
+		protected.GET("/orders/:id/returns", returnHandler.GetByOrderID)
+		protected.GET("/returns/:id", returnHandler.GetByID)
+		protected.GET("/me", accountHandler.Get)
+		protected.PUT("/me", accountHandler.UpdateProfile)
+		protected.PUT("/me/password", accountHandler.ChangePassword)
+		protected.PUT("/me/email", accountHandler.ChangeEmail)
+		protected.DELETE("/me", accountHandler.Delete)
+		protected.POST("/me/mfa/enroll", accountHandler.EnrollMFA)
+		protected.POST("/me/mfa/confirm", accountHandler.ConfirmMFA)
+		protected.DELETE("/me/mfa", accountHandler.DisableMFA)
+		protected.POST("/me/mfa/recovery-codes", accountHandler.RegenerateRecoveryCodes)
+	}
+	admin := protected.Group("/")
+	admin.Use(middleware.RequireRole(domain.RoleAdmin))
+	{
+		admin.GET("/returns", returnHandler.GetAll)
+		admin.POST("/returns/:id/approve", returnHandler.Approve)
+		admin.POST("/returns/:id/reject", returnHandler.Reject)
+		admin.GET("/reviews", reviewHandler.GetAll)
+		admin.POST("/reviews/:id/moderate", reviewHandler.Moderate)
+		admin.GET("/admin/reports/revenue", reportHandler.Revenue)
+		admin.GET("/admin/reports/top-products", reportHandler.TopProducts)
+		admin.GET("/admin/reports/order-value", reportHandler.OrderValue)
+		admin.GET("/admin/reports/customers", reportHandler.CustomerMix)
+		admin.GET("/admin/reports/low-stock", reportHandler.LowStock)
+		admin.PUT("/customers/:id", customerHandler.Update)
+		admin.DELETE("/customers/:id", customerHandler.Delete)
+		admin.POST("/customers/:id/restore", customerHandler.Restore)
+		admin.POST("/customers/:id/erase", privacyHandler.Erase)
+		admin.POST("/products/", productHandler.Create)
+		admin.PUT("/products/:id", productHandler.Update)
+		admin.DELETE("/products/:id", productHandler.Delete)
+		admin.POST("/products/:id/restore", productHandler.Restore)
+		admin.PUT("/products/:id/options", productHandler.SetOptions)
+		admin.PATCH("/products/:id/variants/:variant_id", productHandler.UpdateVariant)
+		admin.POST("/products/:id/images", productHandler.AddImage)
+		admin.PUT("/products/:id/images/order", productHandler.ReorderImages)
+		admin.DELETE("/products/:id/images/:image_id", productHandler.DeleteImage)
+		admin.POST("/products/import", productHandler.Import)
+		admin.GET("/products/export", productHandler.Export)
+		admin.POST("/products/:id/inventory/adjustments", inventoryHandler.Adjust)
+		admin.GET("/products/:id/inventory/movements", inventoryHandler.GetMovements)
+		admin.GET("/products/:id/inventory/verify", inventoryHandler.Verify)
+		admin.POST("/products/:id/inventory/reconcile", inventoryHandler.Reconcile)
+		admin.PUT("/products/:id/inventory/threshold", inventoryHandler.SetReorderThreshold)
+		admin.POST("/products/:id/inventory/transfers", inventoryHandler.Transfer)
+
+		admin.GET("/warehouses", warehouseHandler.GetAll)
+		admin.POST("/warehouses", warehouseHandler.Create)
+		admin.GET("/warehouses/:id", warehouseHandler.GetByID)
+		admin.PUT("/warehouses/:id", warehouseHandler.Update)
+
+		admin.GET("/webhooks", webhookHandler.GetAll)
+		admin.POST("/webhooks", webhookHandler.Create)
+		admin.GET("/webhooks/:id", webhookHandler.GetByID)
+		admin.PUT("/webhooks/:id", webhookHandler.Update)
+		admin.DELETE("/webhooks/:id", webhookHandler.Delete)
+		admin.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+		admin.POST("/webhooks/:id/test", webhookHandler.SendTest)
+		admin.POST("/webhooks/:id/deliveries/:deliveryId/retry", webhookHandler.Retry)
+
+		admin.GET("/api-keys", apiKeyHandler.GetAll)
+		admin.POST("/api-keys", apiKeyHandler.Create)
+		admin.GET("/api-keys/:id", apiKeyHandler.GetByID)
+		admin.POST("/api-keys/:id/revoke", apiKeyHandler.Revoke)
+		admin.POST("/orders/:id/restore", orderHandler.Restore)
+		admin.POST("/categories", categoryHandler.Create)
+		admin.PUT("/categories/:id", categoryHandler.Update)
+		admin.DELETE("/categories/:id", categoryHandler.Delete)
 	}
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"intern-project-v2/domain"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthPolicyFromEnv builds the auth policy from the environment:
+//   - PASSWORD_RESET_URL is the page that asks for the new password, by
+//     default /reset-password on FE_DOMAIN;
+//   - API_URL is the public URL of this API, used for verification links,
+//     by default http://localhost:8080;
+//   - PASSWORD_RESET_TTL_MINUTES defaults to 60 and
+//     EMAIL_VERIFICATION_TTL_HOURS to 48;
+//   - REQUIRE_VERIFIED_EMAIL=true blocks checkout for unverified customers;
+//   - MFA_ISSUER names the shop in authenticator apps, by default
+//     "Order Management", and MFA_CHALLENGE_TTL_MINUTES defaults to 5.
+func AuthPolicyFromEnv() *domain.AuthPolicy {
+	policy := &domain.AuthPolicy{
+		PasswordResetURL:     os.Getenv("PASSWORD_RESET_URL"),
+		VerificationURL:      baseURL(os.Getenv("API_URL"), "http://localhost:8080") + "/api/auth/verify-email",
+		ResetTokenTTL:        time.Hour,
+		VerificationTokenTTL: 48 * time.Hour,
+		MFAIssuer:            os.Getenv("MFA_ISSUER"),
+		MFAChallengeTTL:      5 * time.Minute,
+	}
+	if policy.PasswordResetURL == "" {
+		policy.PasswordResetURL = baseURL(os.Getenv("FE_DOMAIN"), "http://localhost:3000") + "/reset-password"
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES")); err == nil && minutes > 0 {
+		policy.ResetTokenTTL = time.Duration(minutes) * time.Minute
+	}
+	if hours, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL_HOURS")); err == nil && hours > 0 {
+		policy.VerificationTokenTTL = time.Duration(hours) * time.Hour
+	}
+	if policy.MFAIssuer == "" {
+		policy.MFAIssuer = "Order Management"
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("MFA_CHALLENGE_TTL_MINUTES")); err == nil && minutes > 0 {
+		policy.MFAChallengeTTL = time.Duration(minutes) * time.Minute
+	}
+	policy.RequireVerifiedEmail, _ = strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+	return policy
+}
+
+func baseURL(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return strings.TrimRight(value, "/")
+}
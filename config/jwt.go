@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"intern-project-v2/logger"
+	"intern-project-v2/utils"
+	"os"
+	"time"
+)
+
+// jwtKeyConfig is one entry of JWT_KEYS.
+type jwtKeyConfig struct {
+	ID         string     `json:"kid"`
+	File       string     `json:"file"`
+	PEM        string     `json:"pem"`
+	ActiveFrom *time.Time `json:"active_from"`
+	RetireAt   *time.Time `json:"retire_at"`
+}
+
+// JWTKeysFromEnv loads the keys tokens are signed with from JWT_KEYS, a JSON
+// list such as
+//
+//	[{"kid": "2026-10", "file": "/run/secrets/jwt-2026-10.pem", "retire_at": "2027-01-02T00:00:00Z"},
+//	 {"kid": "2027-01", "file": "/run/secrets/jwt-2027-01.pem", "active_from": "2027-01-01T00:00:00Z"}]
+//
+// Each key is an RSA or Ed25519 private key in PEM, read from file or given
+// inline as pem. Without active_from a key is active right away. It is an
+// error when no key is active now.
+func JWTKeysFromEnv() (*utils.KeySet, error) {
+	raw := os.Getenv("JWT_KEYS")
+	if raw == "" {
+		return nil, errors.New("JWT_KEYS is not set")
+	}
+	var configs []jwtKeyConfig
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		return nil, fmt.Errorf("JWT_KEYS is not a valid JSON list: %w", err)
+	}
+
+	keys := make([]*utils.SigningKey, 0, len(configs))
+	for _, cfg := range configs {
+		key, err := loadJWTKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	keySet, err := utils.NewKeySet(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	signer, err := keySet.Signer(now)
+	if err != nil {
+		return nil, err
+	}
+	if next := keySet.Next(now); next != nil {
+		logger.Info("JWT signing key loaded", "kid", signer.ID, "next_kid", next.ID, "next_active_from", next.ActiveFrom)
+	} else {
+		logger.Info("JWT signing key loaded", "kid", signer.ID)
+	}
+	return keySet, nil
+}
+
+func loadJWTKey(cfg jwtKeyConfig) (*utils.SigningKey, error) {
+	data := []byte(cfg.PEM)
+	if cfg.File != "" {
+		if cfg.PEM != "" {
+			return nil, fmt.Errorf("JWT key %q: set file or pem, not both", cfg.ID)
+		}
+		var err error
+		if data, err = os.ReadFile(cfg.File); err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", cfg.ID, err)
+		}
+	}
+	private, err := utils.ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %q: %w", cfg.ID, err)
+	}
+	key := &utils.SigningKey{ID: cfg.ID, Private: private}
+	if cfg.ActiveFrom != nil {
+		key.ActiveFrom = *cfg.ActiveFrom
+	}
+	if cfg.RetireAt != nil {
+		key.RetireAt = *cfg.RetireAt
+	}
+	return key, nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AnonymizedCustomerName replaces the name of customers who deleted their
+// account.
+const AnonymizedCustomerName = "Deleted customer"
+
+// ProfileRequest changes the caller's own profile. Empty fields are kept.
+type ProfileRequest struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeEmailRequest struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+// AccountSession is returned when a change replaces the caller's token.
+type AccountSession struct {
+	Customer *Customer `json:"customer"`
+	Token    string    `json:"token"`
+}
+
+// SessionValidator rejects login tokens of deleted customers and tokens
+// issued before the customer signed out their other sessions.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, customerID string, issuedAt time.Time) error
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKeyHeader is the request header API keys are sent in.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyPrefix starts every API key, so a leaked key is recognizable.
+const APIKeyPrefix = "omk_"
+
+const (
+	DefaultAPIKeyRateLimit = 60
+	MaxAPIKeyRateLimit     = 6000
+)
+
+// APIKeyResources are the parts of the API a key can be scoped to. A scope
+// is a resource followed by ":read" for GET requests or ":write" for any
+// other method, such as "orders:read".
+var APIKeyResources = []string{"products", "categories", "orders", "customers", "returns", "reviews", "inventory", "warehouses", "reports", "webhooks"}
+
+// APIKey lets a server-to-server client, such as an ERP integration, call
+// the API with admin access limited to its scopes. Only the SHA-256 hash of
+// the key is stored; Prefix identifies the key in listings and logs.
+// RateLimit is in requests per minute.
+type APIKey struct {
+	Id         bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name       string        `json:"name" bson:"name"`
+	Prefix     string        `json:"prefix" bson:"prefix"`
+	Hash       string        `json:"-" bson:"hash"`
+	Scopes     []string      `json:"scopes" bson:"scopes"`
+	RateLimit  int           `json:"rate_limit" bson:"rate_limit"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time    `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	CreatedBy  string        `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// Allows reports whether the key has a scope.
+func (k *APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// APIKeyRequest issues a key. A RateLimit of zero means
+// DefaultAPIKeyRateLimit and a key without ExpiresAt does not expire.
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rate_limit"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey is returned when a key is issued. The key itself is shown
+// only this once.
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyAuthenticator checks the key of a request.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*APIKey, error)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Purposes of auth tokens.
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+)
+
+// MinPasswordLength applies to passwords set through a reset.
+const MinPasswordLength = 8
+
+// AuthToken is a single-use token emailed to a customer. Only the SHA-256
+// hash of the token is stored, so a leaked collection cannot be replayed.
+type AuthToken struct {
+	Id         bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	CustomerID string        `json:"customer_id" bson:"customer_id"`
+	Purpose    string        `json:"purpose" bson:"purpose"`
+	Hash       string        `json:"-" bson:"hash"`
+	ExpiresAt  time.Time     `json:"expires_at" bson:"expires_at"`
+	UsedAt     *time.Time    `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// AuthPolicy configures the emailed links. The token is added to the reset
+// and verification URLs as the token query parameter. RequireVerifiedEmail
+// stops unverified customers from placing orders. MFAIssuer names the shop
+// in authenticator apps and MFAChallengeTTL is how long the second login
+// step may take.
+type AuthPolicy struct {
+	PasswordResetURL     string
+	VerificationURL      string
+	ResetTokenTTL        time.Duration
+	VerificationTokenTTL time.Duration
+	RequireVerifiedEmail bool
+	MFAIssuer            string
+	MFAChallengeTTL      time.Duration
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
@@ -3,6 +3,8 @@ package domain
 type CartItem struct {
 	ProductID    string  `json:"product_id" bson:"product_id"`
 	ProductName  string  `json:"product_name" bson:"product_name"`
+	VariantID    string  `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	SKU          string  `json:"sku,omitempty" bson:"sku,omitempty"`
 	ProductPrice float64 `json:"product_price" bson:"product_price"`
 	Quantity     int     `json:"quantity" bson:"quantity"`
 	Subtotal     float64 `json:"subtotal" bson:"subtotal"`
@@ -11,5 +13,6 @@ type CartItem struct {
 type CartItemRequest struct {
 	ProductID   string `json:"product_id" bson:"product_id"`
 	ProductName string `json:"product_name" bson:"product_name"`
+	VariantID   string `json:"variant_id" bson:"variant_id"`
 	Quantity    int    `json:"quantity" bson:"quantity"`
 }
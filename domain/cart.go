@@ -1,11 +1,45 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Cart holds what a customer is about to buy. UpdatedAt moves with every
+// change the customer makes; AbandonedAt is set once the cart has sat idle
+// long enough to be reported as abandoned, and cleared by the next change.
 type Cart struct {
-	Id         bson.ObjectID `json:"id" bson:"_id,omitempty"`
-	CustomerID string        `json:"customer_id" bson:"customer_id"`
-	Items      []*CartItem   `json:"items" bson:"items"`
-	TotalItems int           `json:"total_items" bson:"total_items"`
-	TotalPrice float64       `json:"total_price" bson:"total_price"`
+	Id          bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	CustomerID  string        `json:"customer_id" bson:"customer_id"`
+	Items       []*CartItem   `json:"items" bson:"items"`
+	TotalItems  int           `json:"total_items" bson:"total_items"`
+	TotalPrice  float64       `json:"total_price" bson:"total_price"`
+	Version     int64         `json:"version" bson:"version"`
+	UpdatedAt   time.Time     `json:"updated_at" bson:"updated_at"`
+	AbandonedAt *time.Time    `json:"abandoned_at,omitempty" bson:"abandoned_at,omitempty"`
+}
+
+const (
+	CartOpAdd    = "add"
+	CartOpUpdate = "update"
+	CartOpRemove = "remove"
+)
+
+// CartOperation changes one cart line. add increases the quantity, update
+// sets it (zero removes the line) and remove drops the line, or every line of
+// the product when no variant is given.
+type CartOperation struct {
+	Op        string `json:"op"`
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// CartBatchRequest either replaces the whole cart with Items, when the items
+// key is present, or applies Operations in order. Either way the change is
+// saved in one write or not at all.
+type CartBatchRequest struct {
+	Items      []*CartItemRequest `json:"items"`
+	Operations []*CartOperation   `json:"operations"`
 }
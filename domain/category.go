@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Category struct {
+	Id        bson.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name      string         `json:"name" bson:"name"`
+	Slug      string         `json:"slug" bson:"slug"`
+	ParentID  *bson.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
+}
+
+type CategoryRequest struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID string `json:"parent_id"`
+}
+
+// CategoryRef is the short form of a category used in breadcrumbs.
+type CategoryRef struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
@@ -1,16 +1,53 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Customer is a shop account. EmailVerified is set once the customer follows
+// a verification link or resets their password through an emailed link.
+// Login tokens issued before SessionsValidAfter are no longer accepted.
+// With MFAEnabled a login also needs a TOTP code for MFASecret or one of the
+// recovery codes, of which only hashes are kept; MFALastStep is the period
+// of the last code used, so a code cannot be replayed.
 type Customer struct {
-	Id       bson.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name     string        `json:"name"`
-	Email    string        `json:"email"`
-	Password string        `json:"-"`
-	Phone    string        `json:"phone"`
+	Id                 bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name               string        `json:"name"`
+	Email              string        `json:"email"`
+	Password           string        `json:"-"`
+	Phone              string        `json:"phone"`
+	Role               string        `json:"role"`
+	EmailVerified      bool          `json:"email_verified" bson:"email_verified"`
+	EmailVerifiedAt    *time.Time    `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	SessionsValidAfter *time.Time    `json:"-" bson:"sessions_valid_after,omitempty"`
+	MFAEnabled         bool          `json:"mfa_enabled" bson:"mfa_enabled"`
+	MFASecret          string        `json:"-" bson:"mfa_secret,omitempty"`
+	MFAPendingSecret   string        `json:"-" bson:"mfa_pending_secret,omitempty"`
+	MFARecoveryCodes   []string      `json:"-" bson:"mfa_recovery_codes,omitempty"`
+	MFALastStep        int64         `json:"-" bson:"mfa_last_step,omitempty"`
+	AnonymizedAt       *time.Time    `json:"anonymized_at,omitempty" bson:"anonymized_at,omitempty"`
+	DeletedAt          *time.Time    `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+// Actor identifies the authenticated caller of a usecase. MFA is set when
+// the caller logged in with a second factor.
+type Actor struct {
+	CustomerID string
+	Email      string
+	Role       string
+	MFA        bool
+}
+
+func (a *Actor) IsAdmin() bool {
+	return a != nil && a.Role == RoleAdmin
 }
 
 type CustomerRequest struct {
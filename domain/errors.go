@@ -0,0 +1,11 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflicting state")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+)
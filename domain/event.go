@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	EventOrderPlaced         = "order.placed"
+	EventOrderCancelled      = "order.cancelled"
+	EventCartAbandoned       = "cart.abandoned"
+	EventProductStockChanged = "product.stock_changed"
+	EventCustomerRegistered  = "customer.registered"
+)
+
+// EventTypes lists every domain event type.
+var EventTypes = []string{EventOrderPlaced, EventOrderCancelled, EventCartAbandoned, EventProductStockChanged, EventCustomerRegistered}
+
+// CustomerEventTypes lists the event types whose payload names a customer
+// in its customer_id. Their events are forgotten when the customer is erased.
+var CustomerEventTypes = []string{EventOrderPlaced, EventOrderCancelled, EventCartAbandoned, EventCustomerRegistered}
+
+// MaxEventAttempts is how often the relay tries to deliver an event before
+// leaving it in the outbox as dead.
+const MaxEventAttempts = 10
+
+// Event is a domain event as it is kept in the outbox and handed to sinks.
+// The id is assigned when the event is created, so sinks can use it to drop
+// the duplicates at-least-once delivery produces.
+type Event struct {
+	Id            bson.ObjectID   `json:"id" bson:"_id"`
+	Type          string          `json:"type" bson:"type"`
+	AggregateID   string          `json:"aggregate_id" bson:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload" bson:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at" bson:"occurred_at"`
+	Attempts      int             `json:"-" bson:"attempts"`
+	NextAttemptAt time.Time       `json:"-" bson:"next_attempt_at"`
+	PublishedAt   *time.Time      `json:"-" bson:"published_at,omitempty"`
+	LastError     string          `json:"-" bson:"last_error,omitempty"`
+}
+
+// NewEvent creates an event about the aggregate with the given id, encoding
+// payload as JSON.
+func NewEvent(eventType string, aggregateID string, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Event{
+		Id:            bson.NewObjectID(),
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+type OrderPlacedPayload struct {
+	OrderID     string             `json:"order_id"`
+	CustomerID  string             `json:"customer_id"`
+	TotalAmount float64            `json:"total_amount"`
+	ProductIDs  []string           `json:"product_ids,omitempty"`
+	Items       []*OrderItem       `json:"items,omitempty"`
+	Allocations []*StockAllocation `json:"allocations,omitempty"`
+}
+
+type OrderCancelledPayload struct {
+	OrderID     string  `json:"order_id"`
+	CustomerID  string  `json:"customer_id"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+type CartAbandonedPayload struct {
+	CartID     string    `json:"cart_id"`
+	CustomerID string    `json:"customer_id"`
+	TotalItems int       `json:"total_items"`
+	TotalPrice float64   `json:"total_price"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ProductStockChangedPayload describes one ledger movement. Stock is the
+// stock of the product, or of the variant, over all warehouses afterwards.
+type ProductStockChangedPayload struct {
+	ProductID   string `json:"product_id"`
+	VariantID   string `json:"variant_id,omitempty"`
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	Movement    string `json:"movement"`
+	Quantity    int    `json:"quantity"`
+	Stock       int    `json:"stock"`
+}
+
+type CustomerRegisteredPayload struct {
+	CustomerID string `json:"customer_id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+}
+
+// EventPublisher records domain events for delivery. Events published with
+// the context of a transaction are committed or rolled back with it.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...*Event) error
+}
+
+// Transactor runs fn in a transaction, passing it the context to do its
+// writes with. Calls nested in a running transaction join it.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventSink is a destination the outbox relay delivers events to. Delivery
+// is at least once; a sink may see the same event again after a failure.
+type EventSink interface {
+	Name() string
+	Deliver(ctx context.Context, event *Event) error
+}
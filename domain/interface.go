@@ -2,14 +2,27 @@ package domain
 
 import (
 	"context"
+	"io"
+	"time"
 )
 
 type ProductUsecase interface {
 	GetAll(ctx context.Context) ([]*Product, error)
 	GetByID(ctx context.Context, id string) (*Product, error)
 	Create(ctx context.Context, product *ProductRequest) (*Product, error)
-	Update(ctx context.Context, id string, productReq *ProductRequest) (*Product, error)
+	Update(ctx context.Context, actor *Actor, id string, productReq *ProductRequest) (*Product, error)
 	Delete(ctx context.Context, id string) (*Product, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Product, error)
+	Restore(ctx context.Context, id string) (*Product, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+	Search(ctx context.Context, query *ProductSearchQuery) (*ProductSearchResult, error)
+	SetOptions(ctx context.Context, id string, optionsReq *ProductOptionsRequest) (*Product, error)
+	UpdateVariant(ctx context.Context, actor *Actor, id string, variantID string, variantReq *ProductVariantRequest) (*Product, error)
+	AddImage(ctx context.Context, id string, content io.Reader) (*Product, error)
+	DeleteImage(ctx context.Context, id string, imageID string) (*Product, error)
+	ReorderImages(ctx context.Context, id string, orderReq *ProductImageOrderRequest) (*Product, error)
+	Import(ctx context.Context, actor *Actor, content io.Reader, format string, dryRun bool) (*ProductImportResult, error)
+	Export(ctx context.Context, w io.Writer, format string) error
 }
 
 type ProductRepository interface {
@@ -18,6 +31,26 @@ type ProductRepository interface {
 	Create(ctx context.Context, product *ProductRequest) (*Product, error)
 	Update(ctx context.Context, id string, productReq *ProductRequest) (*Product, error)
 	Delete(ctx context.Context, id string) (*Product, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Product, error)
+	Restore(ctx context.Context, id string) (*Product, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	AdjustStock(ctx context.Context, id string, delta int) (*Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*Product, error)
+	GetByCategoryIDs(ctx context.Context, categoryIDs []string) ([]*Product, error)
+	RemoveCategory(ctx context.Context, categoryID string) (int64, error)
+	SetVariants(ctx context.Context, id string, current *Product, options []*ProductOption, variants []*ProductVariant) (*Product, error)
+	UpdateVariant(ctx context.Context, id string, variant *ProductVariant) (*Product, error)
+	AdjustVariantStock(ctx context.Context, id string, variantID string, delta int) (*Product, error)
+	AddImage(ctx context.Context, id string, image *ProductImage) (*Product, error)
+	RemoveImage(ctx context.Context, id string, imageID string) (*Product, error)
+	SetImages(ctx context.Context, id string, images []*ProductImage) (*Product, error)
+	GetBySKUs(ctx context.Context, skus []string) ([]*Product, error)
+	UpsertBySKU(ctx context.Context, products []*ProductRequest) (created int64, updated int64, err error)
+	Each(ctx context.Context, fn func(*Product) error) error
+	AdjustRating(ctx context.Context, id string, sumDelta int, countDelta int) error
+	SetReorderThreshold(ctx context.Context, id string, threshold *int) (*Product, error)
+	AdjustWarehouseStock(ctx context.Context, id string, variantID string, warehouseID string, delta int) (*Product, error)
+	TransferStock(ctx context.Context, id string, variantID string, from string, to string, quantity int) (*Product, error)
 }
 
 type CustomerUsecase interface {
@@ -26,6 +59,9 @@ type CustomerUsecase interface {
 	Create(ctx context.Context, customer *CustomerRequest) (*Customer, error)
 	Update(ctx context.Context, id string, customerReq *CustomerRequest) (*Customer, error)
 	Delete(ctx context.Context, id string) (*Customer, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Customer, error)
+	Restore(ctx context.Context, id string) (*Customer, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
 }
 
 type CustomerRepository interface {
@@ -34,6 +70,11 @@ type CustomerRepository interface {
 	Create(ctx context.Context, customer *CustomerRequest) (*Customer, error)
 	Update(ctx context.Context, id string, customerReq *CustomerRequest) (*Customer, error)
 	Delete(ctx context.Context, id string) (*Customer, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Customer, error)
+	Restore(ctx context.Context, id string) (*Customer, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	Anonymize(ctx context.Context, id string, at time.Time) error
+	GetByIDIncludingDeleted(ctx context.Context, id string) (*Customer, error)
 }
 
 type OrderUsecase interface {
@@ -42,6 +83,11 @@ type OrderUsecase interface {
 	Create(ctx context.Context, order *OrderRequest) (*Order, error)
 	Update(ctx context.Context, id string, orderReq *OrderRequest) (*Order, error)
 	Delete(ctx context.Context, id string) (*Order, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Order, error)
+	Restore(ctx context.Context, id string) (*Order, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+	Reorder(ctx context.Context, actor *Actor, id string) (*ReorderResult, error)
+	GetByCustomer(ctx context.Context, actor *Actor, query *OrderHistoryQuery) (*OrderHistoryResult, error)
 }
 
 type OrderRepository interface {
@@ -50,30 +96,272 @@ type OrderRepository interface {
 	Create(ctx context.Context, order *OrderRequest) (*Order, error)
 	Update(ctx context.Context, id string, orderReq *OrderRequest) (*Order, error)
 	Delete(ctx context.Context, id string) (*Order, error)
+	GetAllIncludingDeleted(ctx context.Context) ([]*Order, error)
+	Restore(ctx context.Context, id string) (*Order, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ApplyRefund(ctx context.Context, id string, refundID string, amount float64) (*Order, error)
+	CountOpenByCustomer(ctx context.Context, customerID string) (int64, error)
+	HasPurchased(ctx context.Context, customerID string, productID string) (bool, error)
+	GetByCustomer(ctx context.Context, query *OrderHistoryQuery) ([]*Order, int64, error)
+	SetAllocations(ctx context.Context, id string, allocations []*StockAllocation) error
+	ClaimReturn(ctx context.Context, id string, returnsVersion int) error
+	AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error)
+	GetAllByCustomer(ctx context.Context, customerID string) ([]*Order, error)
 }
 
 type CartUsecase interface {
 	AddToCart(ctx context.Context, customerID string, cart *CartItemRequest) (*Cart, error)
 	GetCartByCustomerId(ctx context.Context, customerID string) (*Cart, error)
 	UpdateCartItem(ctx context.Context, customerID string, cartItem *CartItemRequest) (*Cart, error)
-	RemoveCartItem(ctx context.Context, customerID string, productID string) (*Cart, error)
+	RemoveCartItem(ctx context.Context, customerID string, productID string, variantID string) (*Cart, error)
 	ClearCart(ctx context.Context, customerID string) error
+	BatchUpdate(ctx context.Context, actor *Actor, customerID string, batchReq *CartBatchRequest) (*Cart, error)
+	DetectAbandoned(ctx context.Context, idle time.Duration) (int, error)
 }
 
 type CartRepository interface {
 	AddToCart(ctx context.Context, customerID string, cartItem *CartItem) (*Cart, error)
 	GetCartByCustomerId(ctx context.Context, customerID string) (*Cart, error)
 	UpdateCartItem(ctx context.Context, customerID string, cartItem *CartItem) (*Cart, error)
-	RemoveCartItem(ctx context.Context, customerID string, productID string) (*Cart, error)
+	RemoveCartItem(ctx context.Context, customerID string, productID string, variantID string) (*Cart, error)
 	ClearCart(ctx context.Context, customerID string) error
+	RemoveProductFromCarts(ctx context.Context, productID string) (int64, error)
+	Save(ctx context.Context, cart *Cart) (*Cart, error)
+	FindAbandoned(ctx context.Context, idleSince time.Time, limit int) ([]*Cart, error)
+	MarkAbandoned(ctx context.Context, cart *Cart) (bool, error)
+}
+
+type WishlistUsecase interface {
+	Get(ctx context.Context, actor *Actor, customerID string) (*Wishlist, error)
+	AddItem(ctx context.Context, actor *Actor, customerID string, itemReq *WishlistItemRequest) (*Wishlist, error)
+	RemoveItem(ctx context.Context, actor *Actor, customerID string, productID string, variantID string) (*Wishlist, error)
+	MoveToCart(ctx context.Context, actor *Actor, customerID string, moveReq *WishlistMoveRequest) (*WishlistTransfer, error)
+	SaveForLater(ctx context.Context, actor *Actor, customerID string, moveReq *WishlistMoveRequest) (*WishlistTransfer, error)
+	Restocked(ctx context.Context, restock *Restock)
+}
+
+type WishlistRepository interface {
+	GetByCustomerID(ctx context.Context, customerID string) (*Wishlist, error)
+	AddItem(ctx context.Context, customerID string, item *WishlistItem) (*Wishlist, error)
+	RemoveItem(ctx context.Context, customerID string, productID string, variantID string) (*Wishlist, error)
+	GetWatching(ctx context.Context, productID string) ([]*Wishlist, error)
+	Delete(ctx context.Context, customerID string) error
+}
+
+type ReviewUsecase interface {
+	GetByProduct(ctx context.Context, productID string) ([]*Review, error)
+	GetAll(ctx context.Context, status string) ([]*Review, error)
+	Submit(ctx context.Context, actor *Actor, productID string, reviewReq *ReviewRequest) (*Review, error)
+	Update(ctx context.Context, actor *Actor, id string, reviewReq *ReviewRequest) (*Review, error)
+	Delete(ctx context.Context, actor *Actor, id string) (*Review, error)
+	Moderate(ctx context.Context, actor *Actor, id string, moderation *ReviewModeration) (*Review, error)
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *Review) (*Review, error)
+	GetByID(ctx context.Context, id string) (*Review, error)
+	GetByCustomerAndProduct(ctx context.Context, customerID string, productID string) (*Review, error)
+	GetByProduct(ctx context.Context, productID string, status string) ([]*Review, error)
+	GetAll(ctx context.Context, status string) ([]*Review, error)
+	Replace(ctx context.Context, review *Review) (*Review, error)
+	Delete(ctx context.Context, id string) (*Review, error)
+	GetByCustomer(ctx context.Context, customerID string) ([]*Review, error)
+	AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error)
+}
+
+type ReportUsecase interface {
+	Revenue(ctx context.Context, query *ReportQuery) ([]*RevenuePoint, error)
+	TopProducts(ctx context.Context, query *ReportQuery) ([]*TopProduct, error)
+	OrderValue(ctx context.Context, query *ReportQuery) (*OrderValueSummary, error)
+	CustomerMix(ctx context.Context, query *ReportQuery) (*CustomerMix, error)
+	LowStock(ctx context.Context, threshold int, limit int) ([]*LowStockItem, error)
+}
+
+type ReportRepository interface {
+	Revenue(ctx context.Context, query *ReportQuery) ([]*RevenuePoint, error)
+	TopProducts(ctx context.Context, query *ReportQuery) ([]*TopProduct, error)
+	OrderValue(ctx context.Context, query *ReportQuery) (*OrderValueSummary, error)
+	CustomerMix(ctx context.Context, query *ReportQuery) (*CustomerMix, error)
+	LowStock(ctx context.Context, threshold int, limit int) ([]*LowStockItem, error)
+}
+
+type InventoryUsecase interface {
+	Adjust(ctx context.Context, actor *Actor, productID string, adjustmentReq *StockAdjustmentRequest) (*Product, error)
+	SetStock(ctx context.Context, actor *Actor, productID string, variantID string, stock int, reason string) (*Product, error)
+	RecordInitialStock(ctx context.Context, product *Product) error
+	RecordSale(ctx context.Context, order *Order) ([]*StockAllocation, error)
+	RecordCancellation(ctx context.Context, order *Order) error
+	RecordReturn(ctx context.Context, actor *Actor, ret *Return) error
+	GetMovements(ctx context.Context, query *StockMovementQuery) (*StockMovementResult, error)
+	Verify(ctx context.Context, productID string) (*StockVerification, error)
+	Reconcile(ctx context.Context, actor *Actor, productID string) (*StockVerification, error)
+	SetReorderThreshold(ctx context.Context, productID string, thresholdReq *ReorderThresholdRequest) (*Product, error)
+	Transfer(ctx context.Context, actor *Actor, productID string, transferReq *StockTransferRequest) (*Product, error)
+}
+
+type InventoryRepository interface {
+	Record(ctx context.Context, movement *StockMovement) (*StockMovement, error)
+	GetMovements(ctx context.Context, query *StockMovementQuery) ([]*StockMovement, int64, error)
+	Totals(ctx context.Context, productID string) (map[string]int, error)
+}
+
+type WarehouseUsecase interface {
+	GetAll(ctx context.Context) ([]*Warehouse, error)
+	GetByID(ctx context.Context, id string) (*Warehouse, error)
+	Create(ctx context.Context, warehouseReq *WarehouseRequest) (*Warehouse, error)
+	Update(ctx context.Context, id string, warehouseReq *WarehouseRequest) (*Warehouse, error)
+}
+
+type WarehouseRepository interface {
+	GetAll(ctx context.Context) ([]*Warehouse, error)
+	GetByID(ctx context.Context, id string) (*Warehouse, error)
+	Create(ctx context.Context, warehouse *Warehouse) (*Warehouse, error)
+	Update(ctx context.Context, warehouse *Warehouse) (*Warehouse, error)
+}
+
+type OutboxRepository interface {
+	Pending(ctx context.Context, now time.Time, limit int) ([]*Event, error)
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, lastError string, nextAttempt time.Time) error
+	Forget(ctx context.Context, eventTypes []string, match func(*Event) bool) ([]string, error)
+}
+
+type WebhookUsecase interface {
+	GetAll(ctx context.Context) ([]*WebhookSubscription, error)
+	GetByID(ctx context.Context, id string) (*WebhookSubscription, error)
+	Create(ctx context.Context, subscriptionReq *WebhookSubscriptionRequest) (*WebhookSubscription, error)
+	Update(ctx context.Context, id string, subscriptionReq *WebhookSubscriptionRequest) (*WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, query *WebhookDeliveryQuery) (*WebhookDeliveryResult, error)
+	SendTest(ctx context.Context, id string) (*WebhookDelivery, error)
+	Retry(ctx context.Context, subscriptionID string, deliveryID string) (*WebhookDelivery, error)
+	Enqueue(ctx context.Context, event *Event) error
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+type WebhookRepository interface {
+	GetAll(ctx context.Context) ([]*WebhookSubscription, error)
+	GetByID(ctx context.Context, id string) (*WebhookSubscription, error)
+	GetSubscribed(ctx context.Context, eventType string) ([]*WebhookSubscription, error)
+	Create(ctx context.Context, subscription *WebhookSubscription) (*WebhookSubscription, error)
+	Update(ctx context.Context, subscription *WebhookSubscription) (*WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+	AddDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id string) (*WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, query *WebhookDeliveryQuery) ([]*WebhookDelivery, int64, error)
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+	SaveDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	DeleteDeliveries(ctx context.Context, eventIDs []string) (int64, error)
+}
+
+type AccountUsecase interface {
+	Get(ctx context.Context, actor *Actor) (*Customer, error)
+	UpdateProfile(ctx context.Context, actor *Actor, req *ProfileRequest) (*Customer, error)
+	ChangePassword(ctx context.Context, actor *Actor, req *ChangePasswordRequest) (*AccountSession, error)
+	ChangeEmail(ctx context.Context, actor *Actor, req *ChangeEmailRequest) (*AccountSession, error)
+	Delete(ctx context.Context, actor *Actor, req *DeleteAccountRequest) error
+	EnrollMFA(ctx context.Context, actor *Actor, req *MFAEnrollRequest) (*MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, actor *Actor, req *MFACodeRequest) (*MFAActivation, error)
+	DisableMFA(ctx context.Context, actor *Actor, req *MFADisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, actor *Actor, req *MFACodeRequest) (*MFARecoveryCodes, error)
+}
+
+type PrivacyUsecase interface {
+	Export(ctx context.Context, actor *Actor, customerID string) (*PersonalDataExport, error)
+	Erase(ctx context.Context, actor *Actor, customerID string) (*ErasureResult, error)
+}
+
+type APIKeyUsecase interface {
+	GetAll(ctx context.Context) ([]*APIKey, error)
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	Create(ctx context.Context, actor *Actor, keyReq *APIKeyRequest) (*IssuedAPIKey, error)
+	Revoke(ctx context.Context, id string) (*APIKey, error)
+	Authenticate(ctx context.Context, key string) (*APIKey, error)
+}
+
+type APIKeyRepository interface {
+	GetAll(ctx context.Context) ([]*APIKey, error)
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	Create(ctx context.Context, key *APIKey) (*APIKey, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) (*APIKey, error)
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type CategoryUsecase interface {
+	GetAll(ctx context.Context) ([]*Category, error)
+	GetByID(ctx context.Context, id string) (*Category, error)
+	Create(ctx context.Context, categoryReq *CategoryRequest) (*Category, error)
+	Update(ctx context.Context, id string, categoryReq *CategoryRequest) (*Category, error)
+	Delete(ctx context.Context, id string) (*Category, error)
+	GetProducts(ctx context.Context, id string) ([]*Product, error)
+}
+
+type CategoryRepository interface {
+	GetAll(ctx context.Context) ([]*Category, error)
+	GetByID(ctx context.Context, id string) (*Category, error)
+	Create(ctx context.Context, category *Category) (*Category, error)
+	Update(ctx context.Context, category *Category) (*Category, error)
+	Delete(ctx context.Context, id string) (*Category, error)
+	GetDescendantIDs(ctx context.Context, id string) ([]string, error)
+	CountChildren(ctx context.Context, id string) (int64, error)
 }
 
 type AuthUsecase interface {
 	Register(ctx context.Context, req *CustomerRegiser) (*Customer, error)
-	Login(ctx context.Context, req *CustomerLogin) (*Customer, string, error)
+	Login(ctx context.Context, req *CustomerLogin) (*LoginResult, error)
+	VerifyMFA(ctx context.Context, req *MFAVerifyRequest) (*LoginResult, error)
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, req *ResendVerificationRequest) error
+	ValidateSession(ctx context.Context, customerID string, issuedAt time.Time) error
 }
 
 type AuthRepository interface {
 	Register(ctx context.Context, customer *Customer) error
 	Login(ctx context.Context, email string) (*Customer, error)
+	GetByID(ctx context.Context, id string) (*Customer, error)
+	SetPassword(ctx context.Context, customerID string, hashedPassword string, changedAt time.Time) error
+	SetEmail(ctx context.Context, customerID string, email string) error
+	MarkEmailVerified(ctx context.Context, customerID string, verifiedAt time.Time) error
+	SetPendingMFASecret(ctx context.Context, customerID string, secret string) error
+	EnableMFA(ctx context.Context, customerID string, secret string, recoveryCodes []string, step int64) error
+	DisableMFA(ctx context.Context, customerID string) error
+	SetRecoveryCodes(ctx context.Context, customerID string, recoveryCodes []string) error
+	UseTOTPStep(ctx context.Context, customerID string, step int64) error
+	UseRecoveryCode(ctx context.Context, customerID string, hash string) error
+}
+
+type AuthTokenRepository interface {
+	Create(ctx context.Context, token *AuthToken) error
+	Consume(ctx context.Context, purpose string, hash string, now time.Time) (*AuthToken, error)
+	Revoke(ctx context.Context, customerID string, purpose string, now time.Time) error
+	DeleteByCustomer(ctx context.Context, customerID string) error
+}
+
+type ReturnUsecase interface {
+	RequestReturn(ctx context.Context, actor *Actor, orderID string, req *ReturnRequest) (*Return, error)
+	GetByID(ctx context.Context, actor *Actor, id string) (*Return, error)
+	GetByOrderID(ctx context.Context, actor *Actor, orderID string) ([]*Return, error)
+	GetAll(ctx context.Context, status string) ([]*Return, error)
+	Approve(ctx context.Context, actor *Actor, id string, decision *ReturnDecision) (*Return, error)
+	Reject(ctx context.Context, actor *Actor, id string, decision *ReturnDecision) (*Return, error)
+}
+
+type ReturnRepository interface {
+	Create(ctx context.Context, ret *Return) (*Return, error)
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*Return, error)
+	GetByOrderID(ctx context.Context, orderID string) ([]*Return, error)
+	GetAll(ctx context.Context, status string) ([]*Return, error)
+	Replace(ctx context.Context, ret *Return, expectedStatus string) error
+	GetByCustomer(ctx context.Context, customerID string) ([]*Return, error)
+	AnonymizeCustomer(ctx context.Context, customerID string, email string, pseudonym string) (int64, error)
+}
+
+// PaymentGateway issues refunds. Refunds requested again with the same
+// idempotency key return the refund issued the first time.
+type PaymentGateway interface {
+	Refund(ctx context.Context, idempotencyKey string, orderID string, amount float64) (string, error)
 }
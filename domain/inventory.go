@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	StockMovementSale       = "sale"
+	StockMovementReturn     = "return"
+	StockMovementAdjustment = "adjustment"
+	StockMovementRestock    = "restock"
+	StockMovementTransfer   = "transfer"
+)
+
+// StockMovementTypes lists every kind of ledger entry.
+var StockMovementTypes = []string{StockMovementSale, StockMovementReturn, StockMovementAdjustment, StockMovementRestock, StockMovementTransfer}
+
+// DefaultReorderThreshold applies to products without a threshold of their own.
+const DefaultReorderThreshold = 5
+
+// StockMovement is one entry of the inventory ledger. Quantity is signed:
+// sales take stock away, returns and restocks add it. StockAfter is the stock
+// of the product, or of the variant, right after the movement, over all
+// warehouses. Reference points at the order, return or transfer that caused
+// it; a transfer is recorded as a pair of opposite movements.
+type StockMovement struct {
+	Id          bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	ProductID   string        `json:"product_id" bson:"product_id"`
+	VariantID   string        `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	WarehouseID string        `json:"warehouse_id,omitempty" bson:"warehouse_id,omitempty"`
+	Type        string        `json:"type" bson:"type"`
+	Quantity    int           `json:"quantity" bson:"quantity"`
+	StockAfter  int           `json:"stock_after" bson:"stock_after"`
+	Reason      string        `json:"reason,omitempty" bson:"reason,omitempty"`
+	Actor       string        `json:"actor,omitempty" bson:"actor,omitempty"`
+	Reference   string        `json:"reference,omitempty" bson:"reference,omitempty"`
+	CreatedAt   time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// StockAdjustmentRequest is a manual stock movement. Type is adjustment, the
+// default, or restock; restocks only add stock. Without a warehouse the
+// movement applies to unassigned stock.
+type StockAdjustmentRequest struct {
+	VariantID   string `json:"variant_id"`
+	WarehouseID string `json:"warehouse_id"`
+	Type        string `json:"type"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+// ReorderThresholdRequest sets the stock level at which a product is low. A
+// null threshold falls back to DefaultReorderThreshold.
+type ReorderThresholdRequest struct {
+	Threshold *int `json:"threshold"`
+}
+
+type StockMovementQuery struct {
+	ProductID   string
+	VariantID   string
+	WarehouseID string
+	Type        string
+	Page        int
+	Limit       int
+}
+
+type StockMovementResult struct {
+	Movements []*StockMovement `json:"movements"`
+	Total     int64            `json:"total"`
+	Page      int              `json:"page"`
+	Limit     int              `json:"limit"`
+}
+
+// StockLevel compares the stock of a product, or of one variant, with the
+// sum of its ledger entries.
+type StockLevel struct {
+	VariantID   string `json:"variant_id,omitempty"`
+	SKU         string `json:"sku,omitempty"`
+	Stock       int    `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"`
+	Difference  int    `json:"difference"`
+}
+
+type StockVerification struct {
+	ProductID  string        `json:"product_id"`
+	Consistent bool          `json:"consistent"`
+	Levels     []*StockLevel `json:"levels"`
+}
+
+// LowStockAlert is raised when a movement takes a product, or a variant, to
+// its reorder threshold or below.
+type LowStockAlert struct {
+	Product   *Product
+	VariantID string
+	SKU       string
+	Stock     int
+	Threshold int
+	Movement  *StockMovement
+}
+
+// LowStockListener is told whenever stock drops to the reorder threshold.
+type LowStockListener interface {
+	LowStock(ctx context.Context, alert *LowStockAlert)
+}
+
+// ReorderPoint returns the stock level at which the product is low.
+func (p *Product) ReorderPoint() int {
+	if p.ReorderThreshold != nil {
+		return *p.ReorderThreshold
+	}
+	return DefaultReorderThreshold
+}
+
+// StockOf returns the stock of a variant of the product, or of the product
+// itself when variantID is empty. Unknown variants have no stock.
+func (p *Product) StockOf(variantID string) int {
+	if variantID == "" {
+		return p.Stock
+	}
+	if variant := p.Variant(variantID); variant != nil {
+		return variant.Stock
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// MediaStore keeps uploaded files under slash-separated keys and serves them
+// from public URLs.
+type MediaStore interface {
+	Put(ctx context.Context, key string, content io.Reader, contentType string) (url string, err error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ProductImage is an uploaded product picture and its thumbnail. Images are
+// shown in the order they are stored on the product.
+type ProductImage struct {
+	Id           string    `json:"id" bson:"id"`
+	URL          string    `json:"url" bson:"url"`
+	ThumbnailURL string    `json:"thumbnail_url" bson:"thumbnail_url"`
+	Key          string    `json:"-" bson:"key"`
+	ThumbnailKey string    `json:"-" bson:"thumbnail_key"`
+	ContentType  string    `json:"content_type" bson:"content_type"`
+	Width        int       `json:"width" bson:"width"`
+	Height       int       `json:"height" bson:"height"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+type ProductImageOrderRequest struct {
+	ImageIds []string `json:"image_ids"`
+}
+
+const (
+	// MaxImageBytes is the largest image upload accepted.
+	MaxImageBytes = 10 << 20
+	// MaxProductImages is how many images one product may have.
+	MaxProductImages = 12
+)
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// TokenMFAChallenge is the purpose of the token returned by the first step of
+// a login with two-factor authentication.
+const TokenMFAChallenge = "mfa_challenge"
+
+// RecoveryCodeCount is how many recovery codes are issued at a time.
+const RecoveryCodeCount = 10
+
+// LoginResult is the outcome of a login step. Customers with two-factor
+// authentication get a ChallengeToken instead of a Token, to be exchanged
+// with a code from their authenticator app. Admins who have not enrolled yet
+// get a token that does not grant admin access until they do.
+type LoginResult struct {
+	Customer              *Customer  `json:"customer,omitempty"`
+	Token                 string     `json:"token,omitempty"`
+	MFARequired           bool       `json:"mfa_required"`
+	ChallengeToken        string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt    *time.Time `json:"challenge_expires_at,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+}
+
+// MFAVerifyRequest completes a login. Code is a code from the authenticator
+// app or an unused recovery code.
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type MFAEnrollRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+// MFAEnrollment is the secret to add to an authenticator app, directly or by
+// scanning a QR code of the provisioning URI.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFACodeRequest confirms an MFA change with a code from the authenticator
+// app; where two-factor authentication is already on, a recovery code works
+// too.
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFADisableRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Code            string `json:"code"`
+}
+
+// MFAActivation is returned once two-factor authentication is on. The
+// recovery codes are shown only this once; Token replaces the caller's.
+type MFAActivation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token"`
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Email templates.
+const (
+	EmailWelcome           = "welcome"
+	EmailOrderConfirmation = "order_confirmation"
+	EmailPasswordReset     = "password_reset"
+	EmailVerification      = "email_verification"
+)
+
+// Email is a rendered message ready to be sent.
+type Email struct {
+	To      string
+	Subject string
+	HTML    string
+}
+
+// WelcomeEmail asks the customer to verify their address when VerifyURL is
+// set.
+type WelcomeEmail struct {
+	Name      string
+	VerifyURL string
+}
+
+type OrderConfirmationEmail struct {
+	Name        string
+	OrderID     string
+	Items       []*OrderItem
+	TotalAmount float64
+	PlacedAt    time.Time
+}
+
+type PasswordResetEmail struct {
+	Name      string
+	ResetURL  string
+	ExpiresAt time.Time
+}
+
+type EmailVerificationEmail struct {
+	Name      string
+	VerifyURL string
+	ExpiresAt time.Time
+}
+
+// Notifier emails a customer using one of the email templates, with data
+// of the matching *Email type. Sending may happen after Notify returns.
+type Notifier interface {
+	Notify(ctx context.Context, to string, template string, data interface{}) error
+}
+
+// EmailSender delivers a rendered email.
+type EmailSender interface {
+	Send(ctx context.Context, email *Email) error
+}
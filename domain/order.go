@@ -7,15 +7,75 @@ import (
 )
 
 type Order struct {
-	Id          bson.ObjectID `json:"id" bson:"_id,omitempty"`
-	CustomerId  string        `json:"customer_id" `
-	ProductIds  []string      `json:"product_ids"`
-	TotalAmount float64       `json:"total_amount"`
-	CreatedAt   time.Time     `json:"created_at"`
+	Id             bson.ObjectID      `json:"id" bson:"_id,omitempty"`
+	CustomerId     string             `json:"customer_id" `
+	ProductIds     []string           `json:"product_ids"`
+	Items          []*OrderItem       `json:"items,omitempty" bson:"items,omitempty"`
+	TotalAmount    float64            `json:"total_amount"`
+	RefundedAmount float64            `json:"refunded_amount"`
+	RefundIDs      []string           `json:"-" bson:"refund_ids,omitempty"`
+	Status         string             `json:"status"`
+	Allocation     string             `json:"allocation,omitempty" bson:"allocation,omitempty"`
+	ShipTo         *GeoPoint          `json:"ship_to,omitempty" bson:"ship_to,omitempty"`
+	Allocations    []*StockAllocation `json:"allocations,omitempty" bson:"allocations,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	DeletedAt      *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	// ReturnsVersion moves on with every return requested on the order, so a
+	// return checked against returns that have changed since is noticed.
+	ReturnsVersion int `json:"-" bson:"returns_version,omitempty"`
 }
 
+const (
+	OrderStatusPending           = "pending"
+	OrderStatusPartiallyRefunded = "partially_refunded"
+	OrderStatusRefunded          = "refunded"
+)
+
+// OpenOrderStatuses are the statuses of orders that are still being fulfilled.
+var OpenOrderStatuses = []string{OrderStatusPending}
+
+// OrderItem is an order line. The SKU and unit price are snapshotted when the
+// order is placed so later price changes do not affect it.
+type OrderItem struct {
+	ProductID string  `json:"product_id" bson:"product_id"`
+	VariantID string  `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	SKU       string  `json:"sku,omitempty" bson:"sku,omitempty"`
+	Quantity  int     `json:"quantity" bson:"quantity"`
+	UnitPrice float64 `json:"unit_price" bson:"unit_price"`
+}
+
+// OrderRequest accepts either plain product_ids, one unit each, or items.
+// The unit price and SKU of items are filled in by the server. Allocation
+// picks how stock is taken from the warehouses and defaults to split;
+// nearest needs ship_to.
 type OrderRequest struct {
-	CustomerId  string   `json:"customer_id"`
-	ProductIds  []string `json:"product_ids"`
-	TotalAmount float64  `json:"total_amount"`
+	CustomerId  string       `json:"customer_id"`
+	ProductIds  []string     `json:"product_ids"`
+	Items       []*OrderItem `json:"items"`
+	TotalAmount float64      `json:"total_amount"`
+	Allocation  string       `json:"allocation"`
+	ShipTo      *GeoPoint    `json:"ship_to"`
 }
+
+// OrderHistoryQuery filters one customer's orders. From and To are inclusive
+// bounds on the creation time; nil bounds are open.
+type OrderHistoryQuery struct {
+	CustomerID string
+	From       *time.Time
+	To         *time.Time
+	Statuses   []string
+	MinAmount  *float64
+	MaxAmount  *float64
+	Page       int
+	Limit      int
+}
+
+type OrderHistoryResult struct {
+	Orders []*Order `json:"orders"`
+	Total  int64    `json:"total"`
+	Page   int      `json:"page"`
+	Limit  int      `json:"limit"`
+}
+
+// OrderStatuses lists every status an order can have.
+var OrderStatuses = []string{OrderStatusPending, OrderStatusPartiallyRefunded, OrderStatusRefunded}
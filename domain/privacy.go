@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PersonalDataFormatJSON = "json"
+	PersonalDataFormatZIP  = "zip"
+)
+
+// MaxExportedLogLines caps the log lines included in a personal data export.
+const MaxExportedLogLines = 5000
+
+// PersonalDataExport is everything the shop holds about one customer, as
+// handed out in answer to a data subject access request. Logs are the lines
+// of the application log that mention the customer's id or email address.
+type PersonalDataExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Customer   *Customer `json:"customer"`
+	Cart       *Cart     `json:"cart,omitempty"`
+	Wishlist   *Wishlist `json:"wishlist,omitempty"`
+	Orders     []*Order  `json:"orders"`
+	Returns    []*Return `json:"returns"`
+	Reviews    []*Review `json:"reviews"`
+	Logs       []string  `json:"logs"`
+}
+
+// ErasureResult reports what an erasure changed. Orders, returns and reviews
+// are kept with their amounts and ratings, under Pseudonym instead of the
+// customer id.
+type ErasureResult struct {
+	CustomerID string    `json:"customer_id"`
+	Pseudonym  string    `json:"pseudonym"`
+	Orders     int64     `json:"orders"`
+	Returns    int64     `json:"returns"`
+	Reviews    int64     `json:"reviews"`
+	Events     int       `json:"events"`
+	ErasedAt   time.Time `json:"erased_at"`
+}
+
+// LogSearcher finds the application log lines containing any of the terms,
+// oldest first, stopping after limit lines.
+type LogSearcher interface {
+	Search(ctx context.Context, terms []string, limit int) ([]string, error)
+}
@@ -1,16 +1,34 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 type Product struct {
-	Id    bson.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name  string        `json:"name"`
-	Price float64       `json:"price"`
-	Stock int           `json:"stock"`
+	Id               bson.ObjectID     `json:"id" bson:"_id,omitempty"`
+	SKU              string            `json:"sku,omitempty" bson:"sku,omitempty"`
+	Name             string            `json:"name"`
+	Price            float64           `json:"price"`
+	Stock            int               `json:"stock"`
+	CategoryIds      []string          `json:"category_ids" bson:"category_ids,omitempty"`
+	Options          []*ProductOption  `json:"options,omitempty" bson:"options,omitempty"`
+	Variants         []*ProductVariant `json:"variants,omitempty" bson:"variants,omitempty"`
+	Images           []*ProductImage   `json:"images,omitempty" bson:"images,omitempty"`
+	Breadcrumbs      [][]*CategoryRef  `json:"breadcrumbs,omitempty" bson:"-"`
+	RatingAverage    float64           `json:"rating_average" bson:"rating_average,omitempty"`
+	RatingCount      int               `json:"rating_count" bson:"rating_count,omitempty"`
+	RatingSum        int               `json:"-" bson:"rating_sum,omitempty"`
+	ReorderThreshold *int              `json:"reorder_threshold,omitempty" bson:"reorder_threshold,omitempty"`
+	WarehouseStock   map[string]int    `json:"warehouse_stock,omitempty" bson:"warehouse_stock,omitempty"`
+	DeletedAt        *time.Time        `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 }
 
 type ProductRequest struct {
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	SKU         string   `json:"sku" bson:"sku,omitempty"`
+	Name        string   `json:"name"`
+	Price       float64  `json:"price"`
+	Stock       int      `json:"stock"`
+	CategoryIds []string `json:"category_ids" bson:"category_ids,omitempty"`
 }
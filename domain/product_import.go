@@ -0,0 +1,24 @@
+package domain
+
+const (
+	ProductFormatCSV    = "csv"
+	ProductFormatNDJSON = "ndjson"
+)
+
+// ProductImportError reports why one row of an import was rejected. Line is
+// the 1-based line of the source file.
+type ProductImportError struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ProductImportResult summarises an import. Rows are only written when every
+// row is valid and the import is not a dry run.
+type ProductImportResult struct {
+	DryRun  bool                  `json:"dry_run"`
+	Rows    int                   `json:"rows"`
+	Created int64                 `json:"created"`
+	Updated int64                 `json:"updated"`
+	Errors  []*ProductImportError `json:"errors"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+// Reasons a line of a previous order was not copied to the cart as it was.
+const (
+	ReorderDiscontinued    = "discontinued"
+	ReorderOutOfStock      = "out_of_stock"
+	ReorderQuantityReduced = "quantity_reduced"
+	ReorderVariantRequired = "variant_required"
+	ReorderPriceChanged    = "price_changed"
+)
+
+// ReorderAdjustment flags one order line that was skipped, reduced or
+// repriced when it was copied to the cart.
+type ReorderAdjustment struct {
+	ProductID string   `json:"product_id"`
+	VariantID string   `json:"variant_id,omitempty"`
+	Reason    string   `json:"reason"`
+	Requested int      `json:"requested"`
+	Added     int      `json:"added"`
+	OldPrice  *float64 `json:"old_price,omitempty"`
+	NewPrice  *float64 `json:"new_price,omitempty"`
+}
+
+type ReorderResult struct {
+	Cart        *Cart                `json:"cart"`
+	Adjustments []*ReorderAdjustment `json:"adjustments"`
+}
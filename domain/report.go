@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+const (
+	TopProductsByUnits   = "units"
+	TopProductsByRevenue = "revenue"
+)
+
+// ReportQuery bounds a report to orders created between From and To,
+// inclusive.
+type ReportQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	SortBy   string
+	Limit    int
+}
+
+// RevenuePoint sums the orders created in one period. Weeks start on Monday.
+type RevenuePoint struct {
+	Period            time.Time `json:"period" bson:"_id"`
+	Orders            int64     `json:"orders" bson:"orders"`
+	Gross             float64   `json:"gross" bson:"gross"`
+	Refunded          float64   `json:"refunded" bson:"refunded"`
+	Net               float64   `json:"net" bson:"net"`
+	AverageOrderValue float64   `json:"average_order_value" bson:"average_order_value"`
+}
+
+type TopProduct struct {
+	ProductID string  `json:"product_id" bson:"_id"`
+	Name      string  `json:"name" bson:"name"`
+	Units     int64   `json:"units" bson:"units"`
+	Revenue   float64 `json:"revenue" bson:"revenue"`
+}
+
+type OrderValueSummary struct {
+	Orders            int64   `json:"orders" bson:"orders"`
+	Gross             float64 `json:"gross" bson:"gross"`
+	AverageOrderValue float64 `json:"average_order_value" bson:"average_order_value"`
+	MinOrderValue     float64 `json:"min_order_value" bson:"min_order_value"`
+	MaxOrderValue     float64 `json:"max_order_value" bson:"max_order_value"`
+}
+
+// CustomerMix splits the customers who ordered in a period into those whose
+// first order ever falls in the period and those who had ordered before.
+type CustomerMix struct {
+	NewCustomers       int64   `json:"new_customers" bson:"new_customers"`
+	ReturningCustomers int64   `json:"returning_customers" bson:"returning_customers"`
+	NewRevenue         float64 `json:"new_revenue" bson:"new_revenue"`
+	ReturningRevenue   float64 `json:"returning_revenue" bson:"returning_revenue"`
+}
+
+// LowStockItem is a product, or one variant of it, at or below the stock
+// threshold.
+type LowStockItem struct {
+	ProductID string `json:"product_id" bson:"product_id"`
+	Name      string `json:"name" bson:"name"`
+	VariantID string `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	SKU       string `json:"sku,omitempty" bson:"sku,omitempty"`
+	Stock     int    `json:"stock" bson:"stock"`
+	Threshold int    `json:"threshold" bson:"threshold"`
+}
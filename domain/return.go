@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRefunding = "refunding"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// Return is a customer's request to send back items of an order. An approved
+// return is claimed by moving it to refunding, so only one approval restocks
+// and refunds it. Restocked records that the items are back in stock, so a
+// retried approval does not restock them again.
+type Return struct {
+	Id           bson.ObjectID  `json:"id" bson:"_id,omitempty"`
+	OrderID      string         `json:"order_id" bson:"order_id"`
+	CustomerID   string         `json:"customer_id" bson:"customer_id"`
+	Items        []*ReturnItem  `json:"items" bson:"items"`
+	Reason       string         `json:"reason" bson:"reason"`
+	Status       string         `json:"status" bson:"status"`
+	RefundAmount float64        `json:"refund_amount" bson:"refund_amount"`
+	RefundID     string         `json:"refund_id,omitempty" bson:"refund_id,omitempty"`
+	Restocked    bool           `json:"restocked" bson:"restocked"`
+	History      []*ReturnEvent `json:"history" bson:"history"`
+	CreatedAt    time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+type ReturnItem struct {
+	ProductID string  `json:"product_id" bson:"product_id"`
+	VariantID string  `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity" bson:"quantity"`
+	UnitPrice float64 `json:"unit_price" bson:"unit_price"`
+	Subtotal  float64 `json:"subtotal" bson:"subtotal"`
+}
+
+// ReturnEvent is one entry of the audit trail kept on every return.
+type ReturnEvent struct {
+	Status string    `json:"status" bson:"status"`
+	Actor  string    `json:"actor" bson:"actor"`
+	Note   string    `json:"note,omitempty" bson:"note,omitempty"`
+	At     time.Time `json:"at" bson:"at"`
+}
+
+type ReturnRequest struct {
+	Items  []*ReturnItemRequest `json:"items"`
+	Reason string               `json:"reason"`
+}
+
+type ReturnItemRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ReturnDecision struct {
+	Note string `json:"note"`
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is a customer's rating of a product. Each customer reviews a product
+// at most once; posting again edits the existing review. Only published
+// reviews count towards the product's rating.
+type Review struct {
+	Id             bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	ProductID      string        `json:"product_id" bson:"product_id"`
+	CustomerID     string        `json:"customer_id" bson:"customer_id"`
+	Rating         int           `json:"rating" bson:"rating"`
+	Title          string        `json:"title,omitempty" bson:"title,omitempty"`
+	Body           string        `json:"body,omitempty" bson:"body,omitempty"`
+	Verified       bool          `json:"verified_purchase" bson:"verified"`
+	Status         string        `json:"status" bson:"status"`
+	ModerationNote string        `json:"moderation_note,omitempty" bson:"moderation_note,omitempty"`
+	ModeratedBy    string        `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"`
+	Version        int64         `json:"-" bson:"version"`
+	CreatedAt      time.Time     `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at" bson:"updated_at"`
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// ReviewModeration publishes or hides a review.
+type ReviewModeration struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// Published reports whether the review is shown and counted in the rating.
+func (r *Review) Published() bool {
+	return r.Status == ReviewStatusPublished
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "context"
+
+// PriceFacetBoundaries are the lower bounds of the price ranges reported in
+// search facets. The last range is open-ended.
+var PriceFacetBoundaries = []float64{0, 25, 50, 100, 250}
+
+type ProductSearchQuery struct {
+	Query    string
+	MinPrice *float64
+	MaxPrice *float64
+	InStock  bool
+	Page     int
+	Limit    int
+}
+
+type ProductSearchHit struct {
+	*Product
+	Score float64 `json:"score"`
+}
+
+type PriceFacet struct {
+	Min   float64  `json:"min" bson:"min"`
+	Max   *float64 `json:"max,omitempty" bson:"max,omitempty"`
+	Count int64    `json:"count" bson:"count"`
+}
+
+type AvailabilityFacet struct {
+	InStock    int64 `json:"in_stock"`
+	OutOfStock int64 `json:"out_of_stock"`
+}
+
+// SearchFacets are counted over every product matching the text query,
+// before the price and stock filters are applied, so a storefront can show
+// how many results each filter option would give.
+type SearchFacets struct {
+	Price        []*PriceFacet     `json:"price"`
+	Availability AvailabilityFacet `json:"availability"`
+}
+
+type ProductSearchResult struct {
+	Products []*ProductSearchHit `json:"products"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	Limit    int                 `json:"limit"`
+	Facets   SearchFacets        `json:"facets"`
+}
+
+type ProductSearcher interface {
+	Search(ctx context.Context, query *ProductSearchQuery) (*ProductSearchResult, error)
+}
+
+// NewPriceFacets returns an empty facet for every price range.
+func NewPriceFacets() []*PriceFacet {
+	facets := make([]*PriceFacet, len(PriceFacetBoundaries))
+	for i, min := range PriceFacetBoundaries {
+		facets[i] = &PriceFacet{Min: min}
+		if i+1 < len(PriceFacetBoundaries) {
+			max := PriceFacetBoundaries[i+1]
+			facets[i].Max = &max
+		}
+	}
+	return facets
+}
@@ -0,0 +1,56 @@
+package domain
+
+// ProductOption is an axis a product varies along, such as size or colour.
+type ProductOption struct {
+	Name   string   `json:"name" bson:"name"`
+	Values []string `json:"values" bson:"values"`
+}
+
+// ProductVariant is one sellable SKU of a product. A nil Price means the
+// variant sells at the product price. Stock is the total over all
+// warehouses.
+type ProductVariant struct {
+	Id             string            `json:"id" bson:"id"`
+	SKU            string            `json:"sku" bson:"sku"`
+	Options        map[string]string `json:"options" bson:"options"`
+	Price          *float64          `json:"price,omitempty" bson:"price,omitempty"`
+	Stock          int               `json:"stock" bson:"stock"`
+	WarehouseStock map[string]int    `json:"warehouse_stock,omitempty" bson:"warehouse_stock,omitempty"`
+}
+
+// ProductOptionsRequest replaces the options of a product and regenerates its
+// variants. Variants whose option values survive keep their id, SKU, price
+// and stock.
+type ProductOptionsRequest struct {
+	Options []*ProductOption `json:"options"`
+}
+
+type ProductVariantRequest struct {
+	SKU   string   `json:"sku"`
+	Price *float64 `json:"price"`
+	Stock *int     `json:"stock"`
+}
+
+// Variant returns the variant with the given id, or nil.
+func (p *Product) Variant(id string) *ProductVariant {
+	for _, variant := range p.Variants {
+		if variant.Id == id {
+			return variant
+		}
+	}
+	return nil
+}
+
+// HasVariants reports whether the product is sold by variant.
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// PriceOf returns the price of a variant of the product, falling back to the
+// product price when the variant has no override.
+func (p *Product) PriceOf(variant *ProductVariant) float64 {
+	if variant != nil && variant.Price != nil {
+		return *variant.Price
+	}
+	return p.Price
+}
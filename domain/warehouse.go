@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Warehouse is a place stock is kept and shipped from. Inactive warehouses
+// keep their stock but are skipped when orders are allocated.
+type Warehouse struct {
+	Id        bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	Code      string        `json:"code" bson:"code"`
+	Name      string        `json:"name" bson:"name"`
+	Location  *GeoPoint     `json:"location,omitempty" bson:"location,omitempty"`
+	Active    bool          `json:"active" bson:"active"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+}
+
+type WarehouseRequest struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Location *GeoPoint `json:"location"`
+	Active   *bool     `json:"active"`
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude" bson:"latitude"`
+	Longitude float64 `json:"longitude" bson:"longitude"`
+}
+
+const (
+	// AllocationNearest ships each line from the nearest warehouse that can
+	// fill it on its own.
+	AllocationNearest = "nearest"
+	// AllocationMostStock ships each line from the warehouse with the most
+	// stock of it.
+	AllocationMostStock = "most_stock"
+	// AllocationSplit fills lines from several warehouses when no single one
+	// has enough, nearest first when a destination is known.
+	AllocationSplit = "split"
+)
+
+// AllocationStrategies lists the allocation rules an order can ask for.
+var AllocationStrategies = []string{AllocationNearest, AllocationMostStock, AllocationSplit}
+
+// StockAllocation is the part of an order line shipped from one warehouse.
+// An empty WarehouseID is stock not assigned to any warehouse.
+type StockAllocation struct {
+	WarehouseID string `json:"warehouse_id,omitempty" bson:"warehouse_id,omitempty"`
+	ProductID   string `json:"product_id" bson:"product_id"`
+	VariantID   string `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	Quantity    int    `json:"quantity" bson:"quantity"`
+}
+
+// StockTransferRequest moves stock between warehouses. An empty From takes
+// stock that is not assigned to any warehouse yet.
+type StockTransferRequest struct {
+	VariantID string `json:"variant_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+// WarehouseStockOf returns the stock per warehouse of a variant of the
+// product, or of the product itself when variantID is empty.
+func (p *Product) WarehouseStockOf(variantID string) map[string]int {
+	if variantID == "" {
+		return p.WarehouseStock
+	}
+	if variant := p.Variant(variantID); variant != nil {
+		return variant.WarehouseStock
+	}
+	return nil
+}
+
+// UnassignedStock returns the stock of a product line that is not kept in
+// any warehouse, such as stock recorded before warehouses existed.
+func (p *Product) UnassignedStock(variantID string) int {
+	unassigned := p.StockOf(variantID)
+	for _, stock := range p.WarehouseStockOf(variantID) {
+		unassigned -= stock
+	}
+	return unassigned
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// EventWebhookTest is the type of the events sent by the test endpoint. It
+// is never published by the usecases.
+const EventWebhookTest = "webhook.test"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDeliveryStatuses lists every status a delivery can have.
+var WebhookDeliveryStatuses = []string{WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryDead}
+
+// MaxWebhookAttempts is how often a delivery is tried before it is dead.
+const MaxWebhookAttempts = 8
+
+// WebhookSubscription sends the events of the listed types to a partner.
+// The secret signs every delivery; it is only shown when it is set.
+type WebhookSubscription struct {
+	Id         bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL        string        `json:"url" bson:"url"`
+	Secret     string        `json:"secret,omitempty" bson:"secret"`
+	EventTypes []string      `json:"event_types" bson:"event_types"`
+	Active     bool          `json:"active" bson:"active"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// WebhookSubscriptionRequest creates or replaces a subscription. An empty
+// secret is generated on create and kept as it is on update.
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+// WebhookDelivery is one event on its way to one subscription, and the
+// delivery log entry for it. Failed attempts are retried with exponential
+// backoff until MaxWebhookAttempts, after which the delivery is dead.
+type WebhookDelivery struct {
+	Id             bson.ObjectID   `json:"id" bson:"_id,omitempty"`
+	SubscriptionID string          `json:"subscription_id" bson:"subscription_id"`
+	EventID        string          `json:"event_id" bson:"event_id"`
+	EventType      string          `json:"event_type" bson:"event_type"`
+	Payload        json.RawMessage `json:"payload" bson:"payload"`
+	OccurredAt     time.Time       `json:"occurred_at" bson:"occurred_at"`
+	Status         string          `json:"status" bson:"status"`
+	Attempts       int             `json:"attempts" bson:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty" bson:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" bson:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveryQuery struct {
+	SubscriptionID string
+	Status         string
+	Page           int
+	Limit          int
+}
+
+type WebhookDeliveryResult struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+}
+
+// WebhookSender makes one delivery attempt. It returns the status code the
+// receiver answered with, or an error when there was no answer. Anything but
+// 2xx counts as a failure.
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *WebhookSubscription, delivery *WebhookDelivery) (int, error)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Wishlist holds the products a customer saved without buying them yet.
+type Wishlist struct {
+	Id         bson.ObjectID   `json:"id" bson:"_id,omitempty"`
+	CustomerID string          `json:"customer_id" bson:"customer_id"`
+	Items      []*WishlistItem `json:"items" bson:"items"`
+	UpdatedAt  time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// WishlistItem is a saved product or variant. Name, price and availability
+// are filled in from the catalog when the wishlist is read, never stored.
+type WishlistItem struct {
+	ProductID         string    `json:"product_id" bson:"product_id"`
+	VariantID         string    `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	SKU               string    `json:"sku,omitempty" bson:"sku,omitempty"`
+	NotifyBackInStock bool      `json:"notify_back_in_stock" bson:"notify_back_in_stock"`
+	AddedAt           time.Time `json:"added_at" bson:"added_at"`
+	ProductName       string    `json:"product_name,omitempty" bson:"-"`
+	Price             float64   `json:"price" bson:"-"`
+	Available         bool      `json:"available" bson:"-"`
+	InStock           bool      `json:"in_stock" bson:"-"`
+}
+
+type WishlistItemRequest struct {
+	ProductID         string `json:"product_id"`
+	VariantID         string `json:"variant_id"`
+	NotifyBackInStock bool   `json:"notify_back_in_stock"`
+}
+
+// WishlistMoveRequest names the line moved between the cart and the wishlist.
+// Quantity only applies when moving to the cart and defaults to one.
+type WishlistMoveRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// WishlistTransfer is the state of both lists after a move between them.
+type WishlistTransfer struct {
+	Cart     *Cart     `json:"cart"`
+	Wishlist *Wishlist `json:"wishlist"`
+}
+
+// Restock reports stock that went from zero to positive. ProductRestocked is
+// set when the product as a whole came back; VariantIDs lists the variants
+// that did.
+type Restock struct {
+	Product          *Product
+	ProductRestocked bool
+	VariantIDs       []string
+}
+
+// RestockListener is told whenever a product comes back in stock.
+type RestockListener interface {
+	Restocked(ctx context.Context, restock *Restock)
+}
+
+// BackInStockNotifier tells a customer that a wishlisted item can be bought
+// again.
+type BackInStockNotifier interface {
+	NotifyBackInStock(ctx context.Context, customerID string, product *Product, item *WishlistItem) error
+}
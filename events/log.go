@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+)
+
+var _ domain.EventSink = (*LogSink)(nil)
+
+// LogSink writes events to the application log.
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Deliver(ctx context.Context, event *domain.Event) error {
+	logger.Info("Domain event",
+		"event_id", event.Id.Hex(),
+		"type", event.Type,
+		"aggregate_id", event.AggregateID,
+		"payload", string(event.Payload),
+	)
+	return nil
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"slices"
+	"sync"
+)
+
+var (
+	_ domain.EventSink      = (*MemoryBus)(nil)
+	_ domain.EventPublisher = (*MemoryBus)(nil)
+)
+
+// MemoryBus keeps events in memory and hands them to subscribers as they
+// arrive. It serves as a sink for the relay and, in tests, as a publisher
+// that skips the outbox.
+type MemoryBus struct {
+	mu          sync.Mutex
+	events      []*domain.Event
+	subscribers []func(event *domain.Event)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+func (b *MemoryBus) Name() string {
+	return "memory"
+}
+
+// Subscribe registers fn to be called with every event delivered from now
+// on.
+func (b *MemoryBus) Subscribe(fn func(event *domain.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, fn)
+}
+
+func (b *MemoryBus) Deliver(ctx context.Context, event *domain.Event) error {
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	subscribers := append([]func(event *domain.Event){}, b.subscribers...)
+	b.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(event)
+	}
+	return nil
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, events ...*domain.Event) error {
+	for _, event := range events {
+		if err := b.Deliver(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns the events delivered so far, optionally only those of the
+// given types.
+func (b *MemoryBus) Events(types ...string) []*domain.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []*domain.Event
+	for _, event := range b.events {
+		if len(types) == 0 || slices.Contains(types, event.Type) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
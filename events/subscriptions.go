@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"intern-project-v2/domain"
+)
+
+var _ domain.EventSink = (*SubscriptionSink)(nil)
+
+// SubscriptionSink queues events for the partner webhook subscriptions.
+// Queuing only records the deliveries; they are sent by the webhook worker.
+type SubscriptionSink struct {
+	webhooks domain.WebhookUsecase
+}
+
+func NewSubscriptionSink(webhooks domain.WebhookUsecase) *SubscriptionSink {
+	return &SubscriptionSink{
+		webhooks: webhooks,
+	}
+}
+
+func (s *SubscriptionSink) Name() string {
+	return "subscriptions"
+}
+
+func (s *SubscriptionSink) Deliver(ctx context.Context, event *domain.Event) error {
+	return s.webhooks.Enqueue(ctx, event)
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"intern-project-v2/domain"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+var _ domain.EventSink = (*WebhookSink)(nil)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookSink posts each event as JSON to a fixed URL. Any response other
+// than 2xx counts as a failed delivery and is retried by the relay.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, event *domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.Id.Hex())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SinksFromEnv returns the sinks the relay delivers to: the log, plus a
+// webhook when EVENT_WEBHOOK_URL is set.
+func SinksFromEnv() []domain.EventSink {
+	sinks := []domain.EventSink{NewLogSink()}
+	if url := os.Getenv("EVENT_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, NewWebhookSink(url))
+	}
+	return sinks
+}
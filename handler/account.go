@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type accountHandler struct {
+	accountUsecase domain.AccountUsecase
+}
+
+func NewAccountHandler(accountUsecase domain.AccountUsecase) *accountHandler {
+	return &accountHandler{
+		accountUsecase: accountUsecase,
+	}
+}
+
+// Get godoc
+// @Summary Get own profile
+// @Description Retrieve the profile of the authenticated customer
+// @Tags Account
+// @Produce json
+// @Success 200 {object} domain.Customer
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /me [get]
+func (ah *accountHandler) Get(c *gin.Context) {
+	customer, err := ah.accountUsecase.Get(c.Request.Context(), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve profile", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customer)
+}
+
+// UpdateProfile godoc
+// @Summary Update own profile
+// @Description Change the name and phone number of the authenticated customer; empty fields are kept
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param profile body domain.ProfileRequest true "Profile"
+// @Success 200 {object} domain.Customer
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /me [put]
+func (ah *accountHandler) UpdateProfile(c *gin.Context) {
+	var req domain.ProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	customer, err := ah.accountUsecase.UpdateProfile(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update profile", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customer)
+}
+
+// ChangePassword godoc
+// @Summary Change own password
+// @Description Replace the password after confirming the current one. Every other session is signed out; use the returned token from now on
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body domain.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} domain.AccountSession
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Router /me/password [put]
+func (ah *accountHandler) ChangePassword(c *gin.Context) {
+	var req domain.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	session, err := ah.accountUsecase.ChangePassword(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to change password", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// ChangeEmail godoc
+// @Summary Change own email address
+// @Description Move the account to a new address after confirming the password. The new address must be verified through the link sent to it
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body domain.ChangeEmailRequest true "New address and current password"
+// @Success 200 {object} domain.AccountSession
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me/email [put]
+func (ah *accountHandler) ChangeEmail(c *gin.Context) {
+	var req domain.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	session, err := ah.accountUsecase.ChangeEmail(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to change email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// Delete godoc
+// @Summary Delete own account
+// @Description Erase the account after confirming the password. Past orders are kept under a pseudonym; accounts with open orders cannot be deleted
+// @Tags Account
+// @Accept json
+// @Param request body domain.DeleteAccountRequest true "Current password"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me [delete]
+func (ah *accountHandler) Delete(c *gin.Context) {
+	var req domain.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := ah.accountUsecase.Delete(c.Request.Context(), currentActor(c), &req); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete account", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// EnrollMFA godoc
+// @Summary Start two-factor enrollment
+// @Description Create a TOTP secret after confirming the password. Add it to an authenticator app, by hand or through a QR code of the provisioning URI, then confirm with a code
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body domain.MFAEnrollRequest true "Current password"
+// @Success 200 {object} domain.MFAEnrollment
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me/mfa/enroll [post]
+func (ah *accountHandler) EnrollMFA(c *gin.Context) {
+	var req domain.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	enrollment, err := ah.accountUsecase.EnrollMFA(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to start two-factor enrollment", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmMFA godoc
+// @Summary Turn on two-factor authentication
+// @Description Confirm the enrollment with a code from the authenticator app. The recovery codes are shown only this once; use the returned token from now on
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body domain.MFACodeRequest true "Authenticator code"
+// @Success 200 {object} domain.MFAActivation
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me/mfa/confirm [post]
+func (ah *accountHandler) ConfirmMFA(c *gin.Context) {
+	var req domain.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	activation, err := ah.accountUsecase.ConfirmMFA(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to turn on two-factor authentication", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, activation)
+}
+
+// DisableMFA godoc
+// @Summary Turn off two-factor authentication
+// @Description Turn two-factor authentication off after confirming the password and a code. Not allowed for admins
+// @Tags Account
+// @Accept json
+// @Param request body domain.MFADisableRequest true "Current password and code"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me/mfa [delete]
+func (ah *accountHandler) DisableMFA(c *gin.Context) {
+	var req domain.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := ah.accountUsecase.DisableMFA(c.Request.Context(), currentActor(c), &req); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to turn off two-factor authentication", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Replace the recovery codes
+// @Description Issue new recovery codes after confirming a code; the old ones stop working
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body domain.MFACodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} domain.MFARecoveryCodes
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /me/mfa/recovery-codes [post]
+func (ah *accountHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req domain.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	codes, err := ah.accountUsecase.RegenerateRecoveryCodes(c.Request.Context(), currentActor(c), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to replace recovery codes", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyHandler struct {
+	apiKeyUsecase domain.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(apiKeyUsecase domain.APIKeyUsecase) *apiKeyHandler {
+	return &apiKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+// GetAll godoc
+// @Summary Get all API keys
+// @Description Retrieve every API key, newest first, including revoked and expired ones; keys themselves are never included (admin only)
+// @Tags API Keys
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 403
+// @Failure 500
+// @Router /api-keys [get]
+func (kh *apiKeyHandler) GetAll(c *gin.Context) {
+	keys, err := kh.apiKeyUsecase.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetByID godoc
+// @Summary Get API key by ID
+// @Description Retrieve an API key with its scopes, expiry and last use (admin only)
+// @Tags API Keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} domain.APIKey
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /api-keys/{id} [get]
+func (kh *apiKeyHandler) GetByID(c *gin.Context) {
+	key, err := kh.apiKeyUsecase.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve API key", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+// Create godoc
+// @Summary Issue an API key
+// @Description Issue a key for a server-to-server client, sent in the X-API-Key header. Scopes are <resource>:read or <resource>:write; the key is only returned here (admin only)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param key body domain.APIKeyRequest true "API key"
+// @Success 201 {object} domain.IssuedAPIKey
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /api-keys [post]
+func (kh *apiKeyHandler) Create(c *gin.Context) {
+	var keyReq domain.APIKeyRequest
+	if err := c.ShouldBindJSON(&keyReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	key, err := kh.apiKeyUsecase.Create(c.Request.Context(), currentActor(c), &keyReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to issue API key", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// Revoke godoc
+// @Summary Revoke an API key
+// @Description Stop an API key from working right away; the key stays listed (admin only)
+// @Tags API Keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} domain.APIKey
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /api-keys/{id}/revoke [post]
+func (kh *apiKeyHandler) Revoke(c *gin.Context) {
+	key, err := kh.apiKeyUsecase.Revoke(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to revoke API key", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"intern-project-v2/domain"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,10 +39,126 @@ func (ah *authHandler) Login(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid request body"})
 		return
 	}
-	customer, token, err := ah.authUsecase.Login(ctx, &loginReq)
+	result, err := ah.authUsecase.Login(ctx, &loginReq)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, result)
+}
+
+// VerifyMFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from login and a code from the authenticator app, or a recovery code, for a login token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body domain.MFAVerifyRequest true "Challenge token and code"
+// @Success 200 {object} domain.LoginResult
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /auth/mfa/verify [post]
+func (ah *authHandler) VerifyMFA(c *gin.Context) {
+	var req domain.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	result, err := ah.authUsecase.VerifyMFA(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to verify code", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the address has an account
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body domain.ForgotPasswordRequest true "Account email"
+// @Success 202
+// @Failure 400
+// @Failure 500
+// @Router /auth/forgot-password [post]
+func (ah *authHandler) ForgotPassword(c *gin.Context) {
+	var req domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := ah.authUsecase.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to request password reset", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address has an account, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with the token from a reset email. The token works once and expires
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body domain.ResetPasswordRequest true "Token and new password"
+// @Success 200
+// @Failure 400
+// @Failure 500
+// @Router /auth/reset-password [post]
+func (ah *authHandler) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := ah.authUsecase.ResetPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to reset password", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Target of the link in verification emails; marks the customer's address as verified
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200
+// @Failure 400
+// @Failure 500
+// @Router /auth/verify-email [get]
+func (ah *authHandler) VerifyEmail(c *gin.Context) {
+	if err := ah.authUsecase.VerifyEmail(c.Request.Context(), c.Query("token")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to verify email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Email a new verification link to an unverified account. The response is the same whether or not the address has an account
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body domain.ResendVerificationRequest true "Account email"
+// @Success 202
+// @Failure 400
+// @Failure 500
+// @Router /auth/resend-verification [post]
+func (ah *authHandler) ResendVerification(c *gin.Context) {
+	var req domain.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := ah.authUsecase.ResendVerification(c.Request.Context(), &req); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to resend verification email", "details": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"customer": customer, "token": token})
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address has an unverified account, a verification link has been sent"})
 }
@@ -38,7 +38,7 @@ func (ch *cartHandler) AddToCart(c *gin.Context) {
 	}
 	cart, err := ch.cartUsecase.AddToCart(ctx, customerID, &cartItem)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(200, cart)
@@ -54,13 +54,14 @@ func (ch *cartHandler) AddToCart(c *gin.Context) {
 // @Success 200 {object} domain.Cart
 // @Failure 500
 // @Failure 400
+// @Failure 404
 // @Router /customers/{id}/cart [get]
 func (ch *cartHandler) GetCartByCustomerId(c *gin.Context) {
 	ctx := c.Request.Context()
 	customerID := c.Param("id")
 	cart, err := ch.cartUsecase.GetCartByCustomerId(ctx, customerID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(200, cart)
@@ -88,7 +89,7 @@ func (ch *cartHandler) UpdateCartItem(c *gin.Context) {
 	}
 	cart, err := ch.cartUsecase.UpdateCartItem(ctx, customerID, &cartItem)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(200, cart)
@@ -102,6 +103,7 @@ func (ch *cartHandler) UpdateCartItem(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Customer ID"
 // @Param product_id path string true "Product ID"
+// @Param variant_id query string false "Only remove this variant of the product"
 // @Success 200 {object} domain.Cart
 // @Failure 400
 // @Failure 500
@@ -110,7 +112,8 @@ func (ch *cartHandler) RemoveCartItem(c *gin.Context) {
 	ctx := c.Request.Context()
 	customerID := c.Param("id")
 	productID := c.Param("product_id")
-	cart, err := ch.cartUsecase.RemoveCartItem(ctx, customerID, productID)
+	variantID := c.Query("variant_id")
+	cart, err := ch.cartUsecase.RemoveCartItem(ctx, customerID, productID, variantID)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -138,3 +141,31 @@ func (ch *cartHandler) ClearCart(c *gin.Context) {
 	}
 	c.JSON(200, gin.H{"message": "Cart cleared successfully"})
 }
+
+// BatchUpdate godoc
+// @Summary Update several cart items at once
+// @Description Replace the whole cart with items, or apply a list of add, update and remove operations. All changes are validated together and saved atomically.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param batch body domain.CartBatchRequest true "Items or operations"
+// @Success 200 {object} domain.Cart
+// @Failure 400
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /customers/{id}/cart [put]
+func (ch *cartHandler) BatchUpdate(c *gin.Context) {
+	var batchReq domain.CartBatchRequest
+	if err := c.ShouldBindJSON(&batchReq); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+	cart, err := ch.cartUsecase.BatchUpdate(c.Request.Context(), currentActor(c), c.Param("id"), &batchReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, cart)
+}
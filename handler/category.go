@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type categoryHandler struct {
+	categoryUsecase domain.CategoryUsecase
+}
+
+func NewCategoryHandler(categoryUsecase domain.CategoryUsecase) *categoryHandler {
+	return &categoryHandler{
+		categoryUsecase: categoryUsecase,
+	}
+}
+
+// GetAll godoc
+// @Summary Get all categories
+// @Description Retrieve all categories; the tree is described by each category's parent_id
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.Category
+// @Failure 500
+// @Router /categories [get]
+func (ch *categoryHandler) GetAll(c *gin.Context) {
+	ctx := c.Request.Context()
+	categories, err := ch.categoryUsecase.GetAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+	if categories == nil {
+		categories = []*domain.Category{}
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetByID godoc
+// @Summary Get category by ID
+// @Description Retrieve a category by its ID
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} domain.Category
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /categories/{id} [get]
+func (ch *categoryHandler) GetByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	category, err := ch.categoryUsecase.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve category", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// GetProducts godoc
+// @Summary Get products of a category
+// @Description Retrieve the products of a category and of all its subcategories
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {array} domain.Product
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /categories/{id}/products [get]
+func (ch *categoryHandler) GetProducts(c *gin.Context) {
+	ctx := c.Request.Context()
+	products, err := ch.categoryUsecase.GetProducts(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve category products", "details": err.Error()})
+		return
+	}
+	if products == nil {
+		products = []*domain.Product{}
+	}
+	c.JSON(http.StatusOK, products)
+}
+
+// Create godoc
+// @Summary Create a new category
+// @Description Create a category, optionally nested under a parent (admin only)
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param category body domain.CategoryRequest true "Category Request"
+// @Success 201 {object} domain.Category
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /categories [post]
+func (ch *categoryHandler) Create(c *gin.Context) {
+	var categoryReq domain.CategoryRequest
+	if err := c.ShouldBindJSON(&categoryReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	category, err := ch.categoryUsecase.Create(ctx, &categoryReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to create category", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// Update godoc
+// @Summary Update a category
+// @Description Rename or move a category; an empty parent_id moves it to the root (admin only)
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body domain.CategoryRequest true "Category Request"
+// @Success 200 {object} domain.Category
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /categories/{id} [put]
+func (ch *categoryHandler) Update(c *gin.Context) {
+	var categoryReq domain.CategoryRequest
+	if err := c.ShouldBindJSON(&categoryReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	category, err := ch.categoryUsecase.Update(ctx, c.Param("id"), &categoryReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update category", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category updated successfully",
+		"category": category,
+	})
+}
+
+// Delete godoc
+// @Summary Delete a category
+// @Description Delete a category without subcategories and unassign it from products (admin only)
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /categories/{id} [delete]
+func (ch *categoryHandler) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	category, err := ch.categoryUsecase.Delete(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete category", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category deleted successfully",
+		"category": category,
+	})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentActor builds the caller identity stored by middleware.JWTAuth.
+func currentActor(c *gin.Context) *domain.Actor {
+	return &domain.Actor{
+		CustomerID: c.GetString("customer_id"),
+		Email:      c.GetString("email"),
+		Role:       c.GetString("role"),
+		MFA:        c.GetBool("mfa"),
+	}
+}
+
+// includeDeleted reports whether soft-deleted records were requested. Only
+// admins may ask for them; for anyone else a 403 is written and ok is false.
+func includeDeleted(c *gin.Context) (include bool, ok bool) {
+	if c.Query("include_deleted") != "true" {
+		return false, true
+	}
+	if !currentActor(c).IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "include_deleted is restricted to admins"})
+		return false, false
+	}
+	return true, true
+}
+
+// errorStatus maps the domain error sentinels to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -28,9 +28,20 @@ func NewCustomerHandler(customerUsecase domain.CustomerUsecase) *customerHandler
 // @Success 200 {array} domain.Customer
 // @Failure 500
 // @Failure 400
+// @Param include_deleted query bool false "Include soft-deleted records (admin only)"
 // @Router /customers [get]
 func (ch *customerHandler) GetAll(c *gin.Context) {
-	customers, err := ch.customerUsecase.GetAll(c.Request.Context())
+	withDeleted, ok := includeDeleted(c)
+	if !ok {
+		return
+	}
+	var customers []*domain.Customer
+	var err error
+	if withDeleted {
+		customers, err = ch.customerUsecase.GetAllIncludingDeleted(c.Request.Context())
+	} else {
+		customers, err = ch.customerUsecase.GetAll(c.Request.Context())
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve customers"})
 		return
@@ -171,7 +182,7 @@ func (ch *customerHandler) Delete(c *gin.Context) {
 
 	customer, err := ch.customerUsecase.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err), gin.H{
 			"error":   "Failed to delete customer",
 			"details": err.Error()})
 		return
@@ -187,3 +198,28 @@ func (ch *customerHandler) Delete(c *gin.Context) {
 		"customer": customer})
 	logger.Info("Customer deleted successfully", "customer", customer)
 }
+
+// Restore godoc
+// @Summary Restore a deleted customer
+// @Description Restore a soft-deleted customer by its ID (admin only)
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} domain.Customer
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /customers/{id}/restore [post]
+func (ch *customerHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+	customer, err := ch.customerUsecase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to restore customer", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Customer restored successfully",
+		"customer": customer,
+	})
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type inventoryHandler struct {
+	inventoryUsecase domain.InventoryUsecase
+}
+
+func NewInventoryHandler(inventoryUsecase domain.InventoryUsecase) *inventoryHandler {
+	return &inventoryHandler{
+		inventoryUsecase: inventoryUsecase,
+	}
+}
+
+// Adjust godoc
+// @Summary Adjust product stock
+// @Description Record a manual adjustment or a restock in the inventory ledger and apply it to the stock (admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param adjustment body domain.StockAdjustmentRequest true "Stock movement"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/inventory/adjustments [post]
+func (ih *inventoryHandler) Adjust(c *gin.Context) {
+	var adjustmentReq domain.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&adjustmentReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ih.inventoryUsecase.Adjust(c.Request.Context(), currentActor(c), c.Param("id"), &adjustmentReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to adjust stock", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// Transfer godoc
+// @Summary Transfer stock between warehouses
+// @Description Move stock of a product or variant from one warehouse to another; an empty from takes unassigned stock (admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param transfer body domain.StockTransferRequest true "Stock transfer"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/inventory/transfers [post]
+func (ih *inventoryHandler) Transfer(c *gin.Context) {
+	var transferReq domain.StockTransferRequest
+	if err := c.ShouldBindJSON(&transferReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ih.inventoryUsecase.Transfer(c.Request.Context(), currentActor(c), c.Param("id"), &transferReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to transfer stock", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// GetMovements godoc
+// @Summary List stock movements
+// @Description Page through the inventory ledger of a product, newest first (admin only)
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant_id query string false "Only movements of this variant"
+// @Param warehouse_id query string false "Only movements of this warehouse"
+// @Param type query string false "sale, return, adjustment, restock or transfer"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(50)
+// @Success 200 {object} domain.StockMovementResult
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /products/{id}/inventory/movements [get]
+func (ih *inventoryHandler) GetMovements(c *gin.Context) {
+	query := &domain.StockMovementQuery{
+		ProductID:   c.Param("id"),
+		VariantID:   c.Query("variant_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		Type:        c.Query("type"),
+	}
+	query.Page, _ = strconv.Atoi(c.Query("page"))
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	result, err := ih.inventoryUsecase.GetMovements(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve stock movements", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Verify godoc
+// @Summary Verify product stock against the ledger
+// @Description Compare the stock of a product and its variants with the sum of their ledger entries (admin only)
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.StockVerification
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/inventory/verify [get]
+func (ih *inventoryHandler) Verify(c *gin.Context) {
+	verification, err := ih.inventoryUsecase.Verify(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to verify stock", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// Reconcile godoc
+// @Summary Reconcile the ledger with product stock
+// @Description Record an adjustment for every difference between the stock and the ledger, keeping the stock as it is (admin only)
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.StockVerification
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/inventory/reconcile [post]
+func (ih *inventoryHandler) Reconcile(c *gin.Context) {
+	verification, err := ih.inventoryUsecase.Reconcile(c.Request.Context(), currentActor(c), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to reconcile stock", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// SetReorderThreshold godoc
+// @Summary Set the reorder threshold of a product
+// @Description Stock at or below the threshold raises a low stock alert; null restores the default (admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param threshold body domain.ReorderThresholdRequest true "Reorder threshold"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/inventory/threshold [put]
+func (ih *inventoryHandler) SetReorderThreshold(c *gin.Context) {
+	var thresholdReq domain.ReorderThresholdRequest
+	if err := c.ShouldBindJSON(&thresholdReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ih.inventoryUsecase.SetReorderThreshold(c.Request.Context(), c.Param("id"), &thresholdReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to set reorder threshold", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
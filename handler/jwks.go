@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"intern-project-v2/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jwksHandler struct {
+	keys *utils.KeySet
+}
+
+func NewJWKSHandler(keys *utils.KeySet) *jwksHandler {
+	return &jwksHandler{
+		keys: keys,
+	}
+}
+
+// Get serves the public keys login tokens are signed with as a JSON Web Key
+// Set, for other services to verify tokens. It is served outside /api, at
+// /.well-known/jwks.json, and includes keys scheduled to sign later.
+func (jh *jwksHandler) Get(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jh.keys.JWKS(time.Now()))
+}
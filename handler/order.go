@@ -3,6 +3,9 @@ package handler
 import (
 	"intern-project-v2/domain"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,10 +29,21 @@ func NewOrderHandler(orderUsecase domain.OrderUsecase) *orderHandler {
 // @Success 200 {array} domain.Order
 // @Failure 500
 // @Failure 400
+// @Param include_deleted query bool false "Include soft-deleted records (admin only)"
 // @Router /orders [get]
 func (oh *orderHandler) GetAll(c *gin.Context) {
 	ctx := c.Request.Context()
-	orders, err := oh.orderUsecase.GetAll(ctx)
+	withDeleted, ok := includeDeleted(c)
+	if !ok {
+		return
+	}
+	var orders []*domain.Order
+	var err error
+	if withDeleted {
+		orders, err = oh.orderUsecase.GetAllIncludingDeleted(ctx)
+	} else {
+		orders, err = oh.orderUsecase.GetAll(ctx)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve orders"})
 		return
@@ -98,7 +112,7 @@ func (oh *orderHandler) Create(c *gin.Context) {
 	}
 	order, err := oh.orderUsecase.Create(ctx, &orderReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order", "details": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to create order", "details": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, order)
@@ -106,7 +120,7 @@ func (oh *orderHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary Update an existing order
-// @Description Update an order with the provided details
+// @Description Update the customer or total of an order. The lines of an order cannot be changed
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -130,7 +144,7 @@ func (oh *orderHandler) Update(c *gin.Context) {
 	}
 	order, err := oh.orderUsecase.Update(ctx, orderID, &orderReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order", "details": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update order", "details": err.Error()})
 		return
 	}
 	if order == nil {
@@ -142,13 +156,15 @@ func (oh *orderHandler) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete an order
-// @Description Delete an order by its ID
+// @Description Delete an order by its ID and put its stock back. Orders with refunds cannot be deleted
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
 // @Success 200
 // @Failure 400
+// @Failure 404
+// @Failure 409
 // @Failure 500
 // @Router /orders/{id} [delete]
 func (oh *orderHandler) Delete(c *gin.Context) {
@@ -160,7 +176,7 @@ func (oh *orderHandler) Delete(c *gin.Context) {
 	}
 	order, err := oh.orderUsecase.Delete(ctx, orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete order", "details": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete order", "details": err.Error()})
 		return
 	}
 	if order == nil {
@@ -169,3 +185,127 @@ func (oh *orderHandler) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully", "order": order})
 }
+
+// Restore godoc
+// @Summary Restore a deleted order
+// @Description Restore a soft-deleted order by its ID and take its stock again (admin only)
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /orders/{id}/restore [post]
+func (oh *orderHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+	order, err := oh.orderUsecase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to restore order", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order restored successfully",
+		"order":   order,
+	})
+}
+
+// Reorder godoc
+// @Summary Reorder a previous order
+// @Description Add the products of a previous order to the customer's cart at current prices, reporting lines that were skipped or reduced
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} domain.ReorderResult
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /orders/{id}/reorder [post]
+func (oh *orderHandler) Reorder(c *gin.Context) {
+	id := c.Param("id")
+	result, err := oh.orderUsecase.Reorder(c.Request.Context(), currentActor(c), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to reorder", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByCustomer godoc
+// @Summary Get a customer's order history
+// @Description Page through a customer's orders, newest first, filtered by date, status and amount. Customers may only read their own orders.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param from query string false "Earliest creation time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Latest creation time (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param status query string false "Comma-separated order statuses"
+// @Param min_amount query number false "Minimum total amount"
+// @Param max_amount query number false "Maximum total amount"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} domain.OrderHistoryResult
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /customers/{id}/orders [get]
+func (oh *orderHandler) GetByCustomer(c *gin.Context) {
+	query := &domain.OrderHistoryQuery{CustomerID: c.Param("id")}
+	var err error
+	if query.From, err = optionalTime(c, "from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from"})
+		return
+	}
+	if query.To, err = optionalTime(c, "to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to"})
+		return
+	}
+	if query.MinAmount, err = optionalFloat(c, "min_amount"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_amount"})
+		return
+	}
+	if query.MaxAmount, err = optionalFloat(c, "max_amount"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_amount"})
+		return
+	}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+	query.Page, _ = strconv.Atoi(c.Query("page"))
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	result, err := oh.orderUsecase.GetByCustomer(c.Request.Context(), currentActor(c), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve orders", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// optionalTime parses an RFC 3339 timestamp or a plain date. A plain date
+// used as an upper bound covers the whole day.
+func optionalTime(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	if value, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &value, nil
+	}
+	value, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		value = value.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &value, nil
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type privacyHandler struct {
+	privacyUsecase domain.PrivacyUsecase
+}
+
+func NewPrivacyHandler(privacyUsecase domain.PrivacyUsecase) *privacyHandler {
+	return &privacyHandler{
+		privacyUsecase: privacyUsecase,
+	}
+}
+
+// Export godoc
+// @Summary Export a customer's personal data
+// @Description Download everything held about a customer: profile, cart, wishlist, orders, returns, reviews and the log lines mentioning them. Customers may export their own data, admins anyone's
+// @Tags Privacy
+// @Produce json
+// @Produce application/zip
+// @Param id path string true "Customer ID"
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} domain.PersonalDataExport
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /customers/{id}/export [get]
+func (ph *privacyHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.PersonalDataFormatJSON)
+	if format != domain.PersonalDataFormatJSON && format != domain.PersonalDataFormatZIP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+	id := c.Param("id")
+	export, err := ph.privacyUsecase.Export(c.Request.Context(), currentActor(c), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to export personal data", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=customer-%s.%s", id, format))
+	if format == domain.PersonalDataFormatJSON {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	if err := writeExportArchive(c.Writer, export); err != nil {
+		// The body is already being streamed, so the client sees a broken archive.
+		logger.Error("Personal data export aborted", "customer_id", id, "error", err)
+	}
+}
+
+// Erase godoc
+// @Summary Erase a customer's personal data
+// @Description Pseudonymize a customer across all records (admin only). Orders keep their amounts under a pseudonym; carts, wishlists, emailed tokens and the events naming the customer are deleted and the profile is scrubbed. Customers with open orders cannot be erased
+// @Tags Privacy
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} domain.ErasureResult
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /customers/{id}/erase [post]
+func (ph *privacyHandler) Erase(c *gin.Context) {
+	result, err := ph.privacyUsecase.Erase(c.Request.Context(), currentActor(c), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to erase personal data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// writeExportArchive writes the export as a ZIP with one JSON file per kind
+// of record and the log lines as plain text.
+func writeExportArchive(w io.Writer, export *domain.PersonalDataExport) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Customer},
+		{"cart.json", export.Cart},
+		{"wishlist.json", export.Wishlist},
+		{"orders.json", export.Orders},
+		{"returns.json", export.Returns},
+		{"reviews.json", export.Reviews},
+	}
+	for _, file := range files {
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	entry, err := archive.Create("logs.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(entry, strings.Join(export.Logs, "\n")); err != nil {
+		return err
+	}
+	return archive.Close()
+}
@@ -3,11 +3,31 @@ package handler
 import (
 	"fmt"
 	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportBytes bounds the size of an import upload.
+const maxImportBytes = 20 << 20
+
+var exportContentTypes = map[string]string{
+	domain.ProductFormatCSV:    "text/csv; charset=utf-8",
+	domain.ProductFormatNDJSON: "application/x-ndjson",
+}
+
+// importFormat guesses the import format from a content type, defaulting to CSV.
+func importFormat(contentType string) string {
+	if strings.Contains(contentType, "json") {
+		return domain.ProductFormatNDJSON
+	}
+	return domain.ProductFormatCSV
+}
+
 type productHandler struct {
 	productUsecase domain.ProductUsecase
 }
@@ -27,10 +47,21 @@ func NewProductHandler(productUsecase domain.ProductUsecase) *productHandler {
 // @Success 200 {array} domain.Product
 // @Failure 500
 // @Failure 400
+// @Param include_deleted query bool false "Include soft-deleted records (admin only)"
 // @Router /products [get]
 func (ph *productHandler) GetAll(c *gin.Context) {
 	ctx := c.Request.Context()
-	products, err := ph.productUsecase.GetAll(ctx)
+	withDeleted, ok := includeDeleted(c)
+	if !ok {
+		return
+	}
+	var products []*domain.Product
+	var err error
+	if withDeleted {
+		products, err = ph.productUsecase.GetAllIncludingDeleted(ctx)
+	} else {
+		products, err = ph.productUsecase.GetAll(ctx)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve products"})
 		return
@@ -80,13 +111,14 @@ func (ph *productHandler) GetByID(c *gin.Context) {
 
 // Create godoc
 // @Summary Create a new product
-// @Description Create a new product with the provided details
+// @Description Create a new product with the provided details (admin only)
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param product body domain.ProductRequest true "Product Request"
 // @Success 201 {object} domain.Product
 // @Failure 400
+// @Failure 403
 // @Failure 500
 // @Router /products [post]
 func (ph *productHandler) Create(c *gin.Context) {
@@ -99,7 +131,7 @@ func (ph *productHandler) Create(c *gin.Context) {
 	ctx := c.Request.Context()
 	product, err := ph.productUsecase.Create(ctx, &productReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product", "details": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to create product", "details": err.Error()})
 		return
 	}
 
@@ -108,7 +140,7 @@ func (ph *productHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary Update an existing product
-// @Description Update a product with the provided details
+// @Description Update a product with the provided details (admin only)
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -116,6 +148,7 @@ func (ph *productHandler) Create(c *gin.Context) {
 // @Param product body domain.ProductRequest true "Product Request"
 // @Success 200 {object} domain.Product
 // @Failure 400
+// @Failure 403
 // @Failure 500
 // @Router /products/{id} [put]
 func (ph *productHandler) Update(c *gin.Context) {
@@ -125,7 +158,8 @@ func (ph *productHandler) Update(c *gin.Context) {
 		return
 	}
 
-	var productReq domain.ProductRequest
+	// A missing stock leaves the stock alone rather than zeroing it.
+	productReq := domain.ProductRequest{Stock: -1}
 	if err := c.ShouldBindJSON(&productReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
@@ -137,9 +171,9 @@ func (ph *productHandler) Update(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	product, err := ph.productUsecase.Update(ctx, id, &productReq)
+	product, err := ph.productUsecase.Update(ctx, currentActor(c), id, &productReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product", "details": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update product", "details": err.Error()})
 		return
 	}
 
@@ -151,13 +185,14 @@ func (ph *productHandler) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a product
-// @Description Delete a product by its ID
+// @Description Delete a product by its ID (admin only)
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID"
 // @Success 200
 // @Failure 400
+// @Failure 403
 // @Failure 500
 // @Router /products/{id} [delete]
 func (ph *productHandler) Delete(c *gin.Context) {
@@ -184,3 +219,301 @@ func (ph *productHandler) Delete(c *gin.Context) {
 		"product": product,
 	})
 }
+
+// Restore godoc
+// @Summary Restore a deleted product
+// @Description Restore a soft-deleted product by its ID (admin only)
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.Product
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/restore [post]
+func (ph *productHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+	product, err := ph.productUsecase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to restore product", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product restored successfully",
+		"product": product,
+	})
+}
+
+// SetOptions godoc
+// @Summary Set product options
+// @Description Replace the options of a product and regenerate its variants, one per combination of option values (admin only)
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param options body domain.ProductOptionsRequest true "Product options"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/options [put]
+func (ph *productHandler) SetOptions(c *gin.Context) {
+	var optionsReq domain.ProductOptionsRequest
+	if err := c.ShouldBindJSON(&optionsReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ph.productUsecase.SetOptions(c.Request.Context(), c.Param("id"), &optionsReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to set product options", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateVariant godoc
+// @Summary Update a product variant
+// @Description Change the SKU, price override or stock of one variant (admin only)
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant_id path string true "Variant ID"
+// @Param variant body domain.ProductVariantRequest true "Variant fields to change"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/variants/{variant_id} [patch]
+func (ph *productHandler) UpdateVariant(c *gin.Context) {
+	var variantReq domain.ProductVariantRequest
+	if err := c.ShouldBindJSON(&variantReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ph.productUsecase.UpdateVariant(c.Request.Context(), currentActor(c), c.Param("id"), c.Param("variant_id"), &variantReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update variant", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// AddImage godoc
+// @Summary Upload a product image
+// @Description Upload a JPEG, PNG or GIF image; a thumbnail is generated and the image is appended to the product (admin only)
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param image formData file true "Image file"
+// @Success 201 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/images [post]
+func (ph *productHandler) AddImage(c *gin.Context) {
+	// Leave room for the multipart envelope around the file itself.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxImageBytes+1<<20)
+	file, _, err := c.Request.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An image file is required in the image field", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	product, err := ph.productUsecase.AddImage(c.Request.Context(), c.Param("id"), file)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to upload image", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, product)
+}
+
+// DeleteImage godoc
+// @Summary Delete a product image
+// @Description Remove an image and its thumbnail from a product (admin only)
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} domain.Product
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/images/{image_id} [delete]
+func (ph *productHandler) DeleteImage(c *gin.Context) {
+	product, err := ph.productUsecase.DeleteImage(c.Request.Context(), c.Param("id"), c.Param("image_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete image", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// ReorderImages godoc
+// @Summary Reorder product images
+// @Description Set the display order of a product's images; every image must be listed once (admin only)
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param order body domain.ProductImageOrderRequest true "Image ids in display order"
+// @Success 200 {object} domain.Product
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/images/order [put]
+func (ph *productHandler) ReorderImages(c *gin.Context) {
+	var orderReq domain.ProductImageOrderRequest
+	if err := c.ShouldBindJSON(&orderReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	product, err := ph.productUsecase.ReorderImages(c.Request.Context(), c.Param("id"), &orderReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to reorder images", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// Import godoc
+// @Summary Import products
+// @Description Upsert products by SKU from a CSV file (columns sku,name,price,stock,category_ids) or JSON Lines. Nothing is written unless every row is valid (admin only)
+// @Tags Products
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "csv or ndjson; detected from the content type when omitted"
+// @Param dry_run query bool false "Validate without writing"
+// @Param file formData file false "File to import when sending multipart form data"
+// @Success 200 {object} domain.ProductImportResult
+// @Failure 400
+// @Failure 403
+// @Failure 422 {object} domain.ProductImportResult
+// @Failure 500
+// @Router /products/import [post]
+func (ph *productHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	content := io.Reader(c.Request.Body)
+	contentType := c.ContentType()
+	if contentType == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the file field", "details": err.Error()})
+			return
+		}
+		defer file.Close()
+		content = file
+		contentType = header.Header.Get("Content-Type")
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = importFormat(contentType)
+	}
+	result, err := ph.productUsecase.Import(c.Request.Context(), currentActor(c), content, format, c.Query("dry_run") == "true")
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to import products", "details": err.Error()})
+		return
+	}
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Export godoc
+// @Summary Export products
+// @Description Stream every product as CSV or JSON Lines in the columns accepted by the import (admin only)
+// @Tags Products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "csv (default) or ndjson"
+// @Success 200
+// @Failure 400
+// @Failure 403
+// @Router /products/export [get]
+func (ph *productHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", domain.ProductFormatCSV)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or ndjson"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+	c.Status(http.StatusOK)
+	if err := ph.productUsecase.Export(c.Request.Context(), c.Writer, format); err != nil {
+		// The body is already being streamed, so the client sees a truncated file.
+		logger.Error("Product export aborted", "format", format, "error", err)
+	}
+}
+
+// Search godoc
+// @Summary Search products
+// @Description Full-text product search with price and stock filters and facet counts
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param q query string false "Search text"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query bool false "Only products in stock"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} domain.ProductSearchResult
+// @Failure 400
+// @Failure 500
+// @Router /products/search [get]
+func (ph *productHandler) Search(c *gin.Context) {
+	query := &domain.ProductSearchQuery{
+		Query:   c.Query("q"),
+		InStock: c.Query("in_stock") == "true",
+	}
+	var err error
+	if query.MinPrice, err = optionalFloat(c, "min_price"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_price"})
+		return
+	}
+	if query.MaxPrice, err = optionalFloat(c, "max_price"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_price"})
+		return
+	}
+	query.Page, _ = strconv.Atoi(c.Query("page"))
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	ctx := c.Request.Context()
+	result, err := ph.productUsecase.Search(ctx, query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to search products", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func optionalFloat(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
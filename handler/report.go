@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reportHandler struct {
+	reportUsecase domain.ReportUsecase
+}
+
+func NewReportHandler(reportUsecase domain.ReportUsecase) *reportHandler {
+	return &reportHandler{
+		reportUsecase: reportUsecase,
+	}
+}
+
+// Revenue godoc
+// @Summary Revenue report
+// @Description Gross, refunded and net revenue and average order value per day, week or month (admin only)
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End of the range (RFC 3339 or YYYY-MM-DD, inclusive), defaults to now"
+// @Param interval query string false "day (default), week or month"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} domain.RevenuePoint
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /admin/reports/revenue [get]
+func (rh *reportHandler) Revenue(c *gin.Context) {
+	query, ok := reportQuery(c)
+	if !ok {
+		return
+	}
+	query.Interval = c.Query("interval")
+	points, err := rh.reportUsecase.Revenue(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to build revenue report", "details": err.Error()})
+		return
+	}
+	writeReport(c, "revenue", points, []string{"period", "orders", "gross", "refunded", "net", "average_order_value"}, func() [][]string {
+		rows := make([][]string, 0, len(points))
+		for _, point := range points {
+			rows = append(rows, []string{
+				point.Period.Format(time.DateOnly),
+				strconv.FormatInt(point.Orders, 10),
+				formatAmount(point.Gross),
+				formatAmount(point.Refunded),
+				formatAmount(point.Net),
+				formatAmount(point.AverageOrderValue),
+			})
+		}
+		return rows
+	})
+}
+
+// TopProducts godoc
+// @Summary Top products report
+// @Description Best selling products by units or revenue (admin only)
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "End of the range (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param by query string false "units (default) or revenue"
+// @Param limit query int false "Number of products" default(10)
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} domain.TopProduct
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /admin/reports/top-products [get]
+func (rh *reportHandler) TopProducts(c *gin.Context) {
+	query, ok := reportQuery(c)
+	if !ok {
+		return
+	}
+	query.SortBy = c.Query("by")
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+	products, err := rh.reportUsecase.TopProducts(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to build top products report", "details": err.Error()})
+		return
+	}
+	writeReport(c, "top-products", products, []string{"product_id", "name", "units", "revenue"}, func() [][]string {
+		rows := make([][]string, 0, len(products))
+		for _, product := range products {
+			rows = append(rows, []string{
+				product.ProductID,
+				product.Name,
+				strconv.FormatInt(product.Units, 10),
+				formatAmount(product.Revenue),
+			})
+		}
+		return rows
+	})
+}
+
+// OrderValue godoc
+// @Summary Order value report
+// @Description Number of orders and average, smallest and largest order value (admin only)
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "End of the range (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} domain.OrderValueSummary
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /admin/reports/order-value [get]
+func (rh *reportHandler) OrderValue(c *gin.Context) {
+	query, ok := reportQuery(c)
+	if !ok {
+		return
+	}
+	summary, err := rh.reportUsecase.OrderValue(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to build order value report", "details": err.Error()})
+		return
+	}
+	writeReport(c, "order-value", summary, []string{"orders", "gross", "average_order_value", "min_order_value", "max_order_value"}, func() [][]string {
+		return [][]string{{
+			strconv.FormatInt(summary.Orders, 10),
+			formatAmount(summary.Gross),
+			formatAmount(summary.AverageOrderValue),
+			formatAmount(summary.MinOrderValue),
+			formatAmount(summary.MaxOrderValue),
+		}}
+	})
+}
+
+// CustomerMix godoc
+// @Summary New and returning customers report
+// @Description Customers who ordered in the range, split by whether it was their first order (admin only)
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "End of the range (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} domain.CustomerMix
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /admin/reports/customers [get]
+func (rh *reportHandler) CustomerMix(c *gin.Context) {
+	query, ok := reportQuery(c)
+	if !ok {
+		return
+	}
+	mix, err := rh.reportUsecase.CustomerMix(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to build customer report", "details": err.Error()})
+		return
+	}
+	writeReport(c, "customers", mix, []string{"new_customers", "returning_customers", "new_revenue", "returning_revenue"}, func() [][]string {
+		return [][]string{{
+			strconv.FormatInt(mix.NewCustomers, 10),
+			strconv.FormatInt(mix.ReturningCustomers, 10),
+			formatAmount(mix.NewRevenue),
+			formatAmount(mix.ReturningRevenue),
+		}}
+	})
+}
+
+// LowStock godoc
+// @Summary Low stock report
+// @Description Products and variants at or below a stock threshold, lowest first (admin only)
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param threshold query int false "Stock threshold, defaults to the reorder threshold of each product"
+// @Param limit query int false "Number of items" default(50)
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} domain.LowStockItem
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /admin/reports/low-stock [get]
+func (rh *reportHandler) LowStock(c *gin.Context) {
+	threshold := -1
+	if raw := c.Query("threshold"); raw != "" {
+		var err error
+		if threshold, err = strconv.Atoi(raw); err != nil || threshold < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold"})
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	items, err := rh.reportUsecase.LowStock(c.Request.Context(), threshold, limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to build low stock report", "details": err.Error()})
+		return
+	}
+	writeReport(c, "low-stock", items, []string{"product_id", "name", "variant_id", "sku", "stock", "threshold"}, func() [][]string {
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{item.ProductID, item.Name, item.VariantID, item.SKU, strconv.Itoa(item.Stock), strconv.Itoa(item.Threshold)})
+		}
+		return rows
+	})
+}
+
+// reportQuery reads the date range shared by the order reports. On a bad
+// value a 400 is written and ok is false.
+func reportQuery(c *gin.Context) (query *domain.ReportQuery, ok bool) {
+	query = &domain.ReportQuery{}
+	from, err := optionalTime(c, "from", false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from"})
+		return nil, false
+	}
+	to, err := optionalTime(c, "to", true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to"})
+		return nil, false
+	}
+	if from != nil {
+		query.From = *from
+	}
+	if to != nil {
+		query.To = *to
+	}
+	return query, true
+}
+
+// writeReport sends a report as JSON, or as a CSV download when format=csv.
+// rows is only called for CSV.
+func writeReport(c *gin.Context, name string, report interface{}, header []string, rows func() [][]string) {
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, report)
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", name))
+		c.Status(http.StatusOK)
+		writer := csv.NewWriter(c.Writer)
+		if err := writer.Write(header); err != nil {
+			logger.Error("Failed to write report", "report", name, "error", err)
+			return
+		}
+		if err := writer.WriteAll(rows()); err != nil {
+			logger.Error("Failed to write report", "report", name, "error", err)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+	}
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type returnHandler struct {
+	returnUsecase domain.ReturnUsecase
+}
+
+func NewReturnHandler(returnUsecase domain.ReturnUsecase) *returnHandler {
+	return &returnHandler{
+		returnUsecase: returnUsecase,
+	}
+}
+
+// RequestReturn godoc
+// @Summary Request a return
+// @Description Request a return for some of the products on an order
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param return body domain.ReturnRequest true "Return Request"
+// @Success 201 {object} domain.Return
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /orders/{id}/returns [post]
+func (rh *returnHandler) RequestReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+	var returnReq domain.ReturnRequest
+	if err := c.ShouldBindJSON(&returnReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	ret, err := rh.returnUsecase.RequestReturn(ctx, currentActor(c), orderID, &returnReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to request return", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, ret)
+}
+
+// GetByOrderID godoc
+// @Summary Get returns of an order
+// @Description Retrieve every return requested for an order
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {array} domain.Return
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /orders/{id}/returns [get]
+func (rh *returnHandler) GetByOrderID(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+	returns, err := rh.returnUsecase.GetByOrderID(ctx, currentActor(c), orderID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve returns", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
+
+// GetByID godoc
+// @Summary Get return by ID
+// @Description Retrieve a return with its audit trail
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Success 200 {object} domain.Return
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /returns/{id} [get]
+func (rh *returnHandler) GetByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	ret, err := rh.returnUsecase.GetByID(ctx, currentActor(c), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve return", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+// GetAll godoc
+// @Summary Get all returns
+// @Description Retrieve all returns, optionally filtered by status (admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param status query string false "Return status"
+// @Success 200 {array} domain.Return
+// @Failure 403
+// @Failure 500
+// @Router /returns [get]
+func (rh *returnHandler) GetAll(c *gin.Context) {
+	ctx := c.Request.Context()
+	returns, err := rh.returnUsecase.GetAll(ctx, c.Query("status"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve returns", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
+
+// Approve godoc
+// @Summary Approve a return
+// @Description Approve a return, restock its products and refund the customer (admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param decision body domain.ReturnDecision false "Decision note"
+// @Success 200 {object} domain.Return
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /returns/{id}/approve [post]
+func (rh *returnHandler) Approve(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	var decision domain.ReturnDecision
+	// The decision note is optional, so an empty body is accepted.
+	_ = c.ShouldBindJSON(&decision)
+	ret, err := rh.returnUsecase.Approve(ctx, currentActor(c), id, &decision)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to approve return", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+// Reject godoc
+// @Summary Reject a return
+// @Description Reject a requested return (admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param decision body domain.ReturnDecision false "Decision note"
+// @Success 200 {object} domain.Return
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /returns/{id}/reject [post]
+func (rh *returnHandler) Reject(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	var decision domain.ReturnDecision
+	_ = c.ShouldBindJSON(&decision)
+	ret, err := rh.returnUsecase.Reject(ctx, currentActor(c), id, &decision)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to reject return", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
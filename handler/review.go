@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reviewHandler struct {
+	reviewUsecase domain.ReviewUsecase
+}
+
+func NewReviewHandler(reviewUsecase domain.ReviewUsecase) *reviewHandler {
+	return &reviewHandler{
+		reviewUsecase: reviewUsecase,
+	}
+}
+
+// GetByProduct godoc
+// @Summary Get product reviews
+// @Description Retrieve the published reviews of a product, newest first
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} domain.Review
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/reviews [get]
+func (rh *reviewHandler) GetByProduct(c *gin.Context) {
+	reviews, err := rh.reviewUsecase.GetByProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve reviews", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// Submit godoc
+// @Summary Review a product
+// @Description Post a 1-5 rating and text for a product. Posting again edits the caller's existing review.
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param review body domain.ReviewRequest true "Review Request"
+// @Success 201 {object} domain.Review
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /products/{id}/reviews [post]
+func (rh *reviewHandler) Submit(c *gin.Context) {
+	var reviewReq domain.ReviewRequest
+	if err := c.ShouldBindJSON(&reviewReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	review, err := rh.reviewUsecase.Submit(c.Request.Context(), currentActor(c), c.Param("id"), &reviewReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to submit review", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, review)
+}
+
+// Update godoc
+// @Summary Update a review
+// @Description Edit the rating and text of the caller's own review
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review ID"
+// @Param review body domain.ReviewRequest true "Review Request"
+// @Success 200 {object} domain.Review
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /reviews/{id} [put]
+func (rh *reviewHandler) Update(c *gin.Context) {
+	var reviewReq domain.ReviewRequest
+	if err := c.ShouldBindJSON(&reviewReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	review, err := rh.reviewUsecase.Update(c.Request.Context(), currentActor(c), c.Param("id"), &reviewReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update review", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// Delete godoc
+// @Summary Delete a review
+// @Description Delete a review. Customers may delete their own reviews, admins any review.
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review ID"
+// @Success 200 {object} domain.Review
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /reviews/{id} [delete]
+func (rh *reviewHandler) Delete(c *gin.Context) {
+	review, err := rh.reviewUsecase.Delete(c.Request.Context(), currentActor(c), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete review", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully", "review": review})
+}
+
+// GetAll godoc
+// @Summary Get all reviews
+// @Description Retrieve all reviews, optionally filtered by status, for moderation (admin only)
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param status query string false "Review status (published or hidden)"
+// @Success 200 {array} domain.Review
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /reviews [get]
+func (rh *reviewHandler) GetAll(c *gin.Context) {
+	reviews, err := rh.reviewUsecase.GetAll(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve reviews", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// Moderate godoc
+// @Summary Moderate a review
+// @Description Publish or hide a review (admin only). Hidden reviews do not count towards the product rating.
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review ID"
+// @Param moderation body domain.ReviewModeration true "Moderation decision"
+// @Success 200 {object} domain.Review
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /reviews/{id}/moderate [post]
+func (rh *reviewHandler) Moderate(c *gin.Context) {
+	var moderation domain.ReviewModeration
+	if err := c.ShouldBindJSON(&moderation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	review, err := rh.reviewUsecase.Moderate(c.Request.Context(), currentActor(c), c.Param("id"), &moderation)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to moderate review", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
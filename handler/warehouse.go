@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type warehouseHandler struct {
+	warehouseUsecase domain.WarehouseUsecase
+}
+
+func NewWarehouseHandler(warehouseUsecase domain.WarehouseUsecase) *warehouseHandler {
+	return &warehouseHandler{
+		warehouseUsecase: warehouseUsecase,
+	}
+}
+
+// GetAll godoc
+// @Summary Get all warehouses
+// @Description Retrieve all warehouses, ordered by code (admin only)
+// @Tags Warehouses
+// @Produce json
+// @Success 200 {array} domain.Warehouse
+// @Failure 403
+// @Failure 500
+// @Router /warehouses [get]
+func (wh *warehouseHandler) GetAll(c *gin.Context) {
+	warehouses, err := wh.warehouseUsecase.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve warehouses"})
+		return
+	}
+	if warehouses == nil {
+		warehouses = []*domain.Warehouse{}
+	}
+	c.JSON(http.StatusOK, warehouses)
+}
+
+// GetByID godoc
+// @Summary Get warehouse by ID
+// @Description Retrieve a warehouse by its ID (admin only)
+// @Tags Warehouses
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} domain.Warehouse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /warehouses/{id} [get]
+func (wh *warehouseHandler) GetByID(c *gin.Context) {
+	warehouse, err := wh.warehouseUsecase.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve warehouse", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, warehouse)
+}
+
+// Create godoc
+// @Summary Create a warehouse
+// @Description Add a warehouse stock can be kept in and shipped from (admin only)
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Param warehouse body domain.WarehouseRequest true "Warehouse Request"
+// @Success 201 {object} domain.Warehouse
+// @Failure 400
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Router /warehouses [post]
+func (wh *warehouseHandler) Create(c *gin.Context) {
+	var warehouseReq domain.WarehouseRequest
+	if err := c.ShouldBindJSON(&warehouseReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	warehouse, err := wh.warehouseUsecase.Create(c.Request.Context(), &warehouseReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to create warehouse", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, warehouse)
+}
+
+// Update godoc
+// @Summary Update a warehouse
+// @Description Replace the code, name and location of a warehouse; inactive warehouses are skipped when orders are allocated (admin only)
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Param warehouse body domain.WarehouseRequest true "Warehouse Request"
+// @Success 200 {object} domain.Warehouse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /warehouses/{id} [put]
+func (wh *warehouseHandler) Update(c *gin.Context) {
+	var warehouseReq domain.WarehouseRequest
+	if err := c.ShouldBindJSON(&warehouseReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	warehouse, err := wh.warehouseUsecase.Update(c.Request.Context(), c.Param("id"), &warehouseReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update warehouse", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, warehouse)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type webhookHandler struct {
+	webhookUsecase domain.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase domain.WebhookUsecase) *webhookHandler {
+	return &webhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// GetAll godoc
+// @Summary Get all webhook subscriptions
+// @Description Retrieve every partner webhook subscription; secrets are not included (admin only)
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} domain.WebhookSubscription
+// @Failure 403
+// @Failure 500
+// @Router /webhooks [get]
+func (wh *webhookHandler) GetAll(c *gin.Context) {
+	subscriptions, err := wh.webhookUsecase.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscriptions"})
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []*domain.WebhookSubscription{}
+	}
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetByID godoc
+// @Summary Get webhook subscription by ID
+// @Description Retrieve a webhook subscription; the secret is not included (admin only)
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} domain.WebhookSubscription
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id} [get]
+func (wh *webhookHandler) GetByID(c *gin.Context) {
+	subscription, err := wh.webhookUsecase.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve webhook subscription", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// Create godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a partner URL to event types; the signing secret is generated when not given and only returned here (admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body domain.WebhookSubscriptionRequest true "Subscription"
+// @Success 201 {object} domain.WebhookSubscription
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Router /webhooks [post]
+func (wh *webhookHandler) Create(c *gin.Context) {
+	var subscriptionReq domain.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&subscriptionReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	subscription, err := wh.webhookUsecase.Create(c.Request.Context(), &subscriptionReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to create webhook subscription", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// Update godoc
+// @Summary Update a webhook subscription
+// @Description Replace the URL and event types of a subscription; an empty secret keeps the current one (admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param subscription body domain.WebhookSubscriptionRequest true "Subscription"
+// @Success 200 {object} domain.WebhookSubscription
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id} [put]
+func (wh *webhookHandler) Update(c *gin.Context) {
+	var subscriptionReq domain.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&subscriptionReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	subscription, err := wh.webhookUsecase.Update(c.Request.Context(), c.Param("id"), &subscriptionReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to update webhook subscription", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// Delete godoc
+// @Summary Delete a webhook subscription
+// @Description Stop sending events to a subscription; its delivery log is kept (admin only)
+// @Tags Webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id} [delete]
+func (wh *webhookHandler) Delete(c *gin.Context) {
+	if err := wh.webhookUsecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to delete webhook subscription", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Page through the delivery log of a subscription, newest first (admin only)
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param status query string false "pending, delivered or dead"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(50)
+// @Success 200 {object} domain.WebhookDeliveryResult
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id}/deliveries [get]
+func (wh *webhookHandler) GetDeliveries(c *gin.Context) {
+	query := &domain.WebhookDeliveryQuery{
+		SubscriptionID: c.Param("id"),
+		Status:         c.Query("status"),
+	}
+	query.Page, _ = strconv.Atoi(c.Query("page"))
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	result, err := wh.webhookUsecase.GetDeliveries(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retrieve webhook deliveries", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// SendTest godoc
+// @Summary Send a test event
+// @Description Send a signed webhook.test event to a subscription right away and return the logged delivery (admin only)
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} domain.WebhookDelivery
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id}/test [post]
+func (wh *webhookHandler) SendTest(c *gin.Context) {
+	delivery, err := wh.webhookUsecase.SendTest(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to send test event", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Retry godoc
+// @Summary Retry a dead delivery
+// @Description Put a dead delivery back in the queue with fresh attempts (admin only)
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} domain.WebhookDelivery
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /webhooks/{id}/deliveries/{deliveryId}/retry [post]
+func (wh *webhookHandler) Retry(c *gin.Context) {
+	delivery, err := wh.webhookUsecase.Retry(c.Request.Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Failed to retry delivery", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
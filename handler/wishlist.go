@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"intern-project-v2/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type wishlistHandler struct {
+	wishlistUsecase domain.WishlistUsecase
+}
+
+func NewWishlistHandler(wishlistUsecase domain.WishlistUsecase) *wishlistHandler {
+	return &wishlistHandler{
+		wishlistUsecase: wishlistUsecase,
+	}
+}
+
+// Get godoc
+// @Summary Get wishlist
+// @Description Retrieve the customer's wishlist with current prices and availability
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} domain.Wishlist
+// @Failure 403
+// @Failure 500
+// @Router /customers/{id}/wishlist [get]
+func (wh *wishlistHandler) Get(c *gin.Context) {
+	wishlist, err := wh.wishlistUsecase.Get(c.Request.Context(), currentActor(c), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// AddItem godoc
+// @Summary Add item to wishlist
+// @Description Save a product, or one of its variants, to the customer's wishlist. Saving a saved item again updates its back-in-stock flag.
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param item body domain.WishlistItemRequest true "Wishlist Item Request"
+// @Success 200 {object} domain.Wishlist
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /customers/{id}/wishlist/items [post]
+func (wh *wishlistHandler) AddItem(c *gin.Context) {
+	var itemReq domain.WishlistItemRequest
+	if err := c.ShouldBindJSON(&itemReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	wishlist, err := wh.wishlistUsecase.AddItem(c.Request.Context(), currentActor(c), c.Param("id"), &itemReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// RemoveItem godoc
+// @Summary Remove item from wishlist
+// @Description Remove a saved product from the customer's wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param product_id path string true "Product ID"
+// @Param variant_id query string false "Variant ID of the saved line"
+// @Success 200 {object} domain.Wishlist
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /customers/{id}/wishlist/items/{product_id} [delete]
+func (wh *wishlistHandler) RemoveItem(c *gin.Context) {
+	wishlist, err := wh.wishlistUsecase.RemoveItem(c.Request.Context(), currentActor(c), c.Param("id"), c.Param("product_id"), c.Query("variant_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// MoveToCart godoc
+// @Summary Move wishlist item to cart
+// @Description Add a wishlisted product to the cart and remove it from the wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param move body domain.WishlistMoveRequest true "Item to move"
+// @Success 200 {object} domain.WishlistTransfer
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /customers/{id}/wishlist/move-to-cart [post]
+func (wh *wishlistHandler) MoveToCart(c *gin.Context) {
+	var moveReq domain.WishlistMoveRequest
+	if err := c.ShouldBindJSON(&moveReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	transfer, err := wh.wishlistUsecase.MoveToCart(c.Request.Context(), currentActor(c), c.Param("id"), &moveReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+// SaveForLater godoc
+// @Summary Save cart item for later
+// @Description Move a cart line to the wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param move body domain.WishlistMoveRequest true "Item to move"
+// @Success 200 {object} domain.WishlistTransfer
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /customers/{id}/cart/save-for-later [post]
+func (wh *wishlistHandler) SaveForLater(c *gin.Context) {
+	var moveReq domain.WishlistMoveRequest
+	if err := c.ShouldBindJSON(&moveReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	transfer, err := wh.wishlistUsecase.SaveForLater(c.Request.Context(), currentActor(c), c.Param("id"), &moveReq)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
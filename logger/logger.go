@@ -10,6 +10,10 @@ import (
 
 var logger *slog.Logger
 
+// logPath is the file the logs are written to, empty when they only go to
+// stdout.
+var logPath string
+
 func init() {
 	if err := initLogger(); err != nil {
 		// ✅ Không panic, fallback to stdout
@@ -84,8 +88,9 @@ func setupLocalLogger() error {
 	}
 
 	// Create file writer
+	path := filepath.Join("logs", "app.log")
 	logFile, err := os.OpenFile(
-		filepath.Join("logs", "app.log"),
+		path,
 		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
 		0666,
 	)
@@ -94,6 +99,7 @@ func setupLocalLogger() error {
 		setupFallbackLogger()
 		return nil
 	}
+	logPath = path
 
 	// Multi-writer (console + file) cho local
 	multiWriter := io.MultiWriter(os.Stdout, logFile)
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// FileSearcher reads back the log file. Where the logs only go to stdout,
+// as in serverless deployments, there is nothing to search and it finds no
+// lines.
+type FileSearcher struct{}
+
+func NewFileSearcher() *FileSearcher {
+	return &FileSearcher{}
+}
+
+// Search returns the lines containing any of the non-empty terms, oldest
+// first, stopping after limit lines.
+func (fs *FileSearcher) Search(ctx context.Context, terms []string, limit int) ([]string, error) {
+	lines := []string{}
+	if logPath == "" {
+		return lines, nil
+	}
+	file, err := os.Open(logPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return lines, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() && len(lines) < limit {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := scanner.Text()
+		for _, term := range terms {
+			if term != "" && strings.Contains(line, term) {
+				lines = append(lines, line)
+				break
+			}
+		}
+	}
+	return lines, scanner.Err()
+}
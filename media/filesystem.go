@@ -0,0 +1,99 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var _ domain.MediaStore = (*FilesystemStore)(nil)
+
+const defaultRoot = "uploads"
+
+// FilesystemStore writes media to a local directory. The router serves that
+// directory under baseURL, so it only suits single-instance deployments; a
+// shared object store should implement domain.MediaStore for anything larger.
+type FilesystemStore struct {
+	root    string
+	baseURL string
+}
+
+func NewFilesystemStore(root, baseURL string) (*FilesystemStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FilesystemStore{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// RootFromEnv reads MEDIA_ROOT, defaulting to ./uploads.
+func RootFromEnv() string {
+	if root := os.Getenv("MEDIA_ROOT"); root != "" {
+		return root
+	}
+	return defaultRoot
+}
+
+// Root is the directory the store writes to.
+func (s *FilesystemStore) Root() string {
+	return s.root
+}
+
+// Put writes the content to a temporary file first and renames it into place
+// so readers never see a partial file.
+func (s *FilesystemStore) Put(ctx context.Context, key string, content io.Reader, contentType string) (string, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	logger.Info("Stored media", "key", key, "content_type", contentType)
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *FilesystemStore) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would escape it.
+func (s *FilesystemStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", fmt.Errorf("%w: invalid media key %q", domain.ErrInvalidInput, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
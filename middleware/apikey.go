@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"errors"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keyClients counts the requests of each API key. There are few keys, so
+// unlike the clients of RateLimit they are never cleaned up.
+var (
+	keyClients = make(map[string]*Client)
+	keyMutex   = sync.Mutex{}
+)
+
+// APIKeyAuth authenticates requests that send an API key and lets the
+// others through to JWTAuth. A key acts as an admin, limited to its scopes
+// and its rate limit.
+func APIKeyAuth(keys domain.APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plain := c.GetHeader(domain.APIKeyHeader)
+		if plain == "" {
+			c.Next()
+			return
+		}
+		key, err := keys.Authenticate(c.Request.Context(), plain)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, domain.ErrUnauthorized) {
+				status = http.StatusUnauthorized
+			}
+			c.JSON(status, gin.H{
+				"error":   "Invalid API key",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		scope := apiKeyScope(c)
+		if scope == "" || !key.Allows(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient scope",
+				"message": "This API key cannot be used for " + c.Request.Method + " " + c.FullPath(),
+				"scope":   scope,
+			})
+			c.Abort()
+			return
+		}
+		if retryAfter, ok := allowKeyRequest(key); !ok {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Too many requests",
+				"retry_after": retryAfter,
+			})
+			logger.Warn("API key rate limit exceeded", "prefix", key.Prefix, "limit", key.RateLimit)
+			c.Abort()
+			return
+		}
+
+		c.Set("api_key_id", key.Id.Hex())
+		c.Set("role", domain.RoleAdmin)
+		c.Next()
+	}
+}
+
+// apiKeyScope is the scope a request needs: the last resource named in the
+// route, followed by read for GET requests and write for the others. Routes
+// that name no resource, such as /api/me, need a scope no key can have.
+func apiKeyScope(c *gin.Context) string {
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !slices.Contains(domain.APIKeyResources, segments[i]) {
+			continue
+		}
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return segments[i] + ":read"
+		}
+		return segments[i] + ":write"
+	}
+	return ""
+}
+
+// allowKeyRequest counts a request of a key and reports whether it is within
+// the key's rate limit, and otherwise in how many seconds it will be.
+func allowKeyRequest(key *domain.APIKey) (int, bool) {
+	now := time.Now()
+	id := key.Id.Hex()
+
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
+
+	client, exists := keyClients[id]
+	if !exists || now.After(client.ResetTime) {
+		keyClients[id] = &Client{Count: 1, ResetTime: now.Add(time.Minute), LastSeen: now}
+		return 0, true
+	}
+	client.LastSeen = now
+	if client.Count >= key.RateLimit {
+		return int(time.Until(client.ResetTime).Seconds()) + 1, false
+	}
+	client.Count++
+	return 0, true
+}
@@ -1,15 +1,28 @@
 package middleware
 
 import (
+	"errors"
+	"intern-project-v2/domain"
 	"intern-project-v2/utils"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func JWTAuth() gin.HandlerFunc {
+// JWTAuth requires a valid token. When sessions is set, tokens of deleted
+// accounts and of signed-out sessions are rejected too. Admins who did not
+// log in with a second factor are treated as customers, so they can enroll
+// but not use admin access.
+func JWTAuth(sessions domain.SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetString("customer_id") != "" || c.GetString("api_key_id") != "" {
+			// Already checked by OptionalJWTAuth or APIKeyAuth on an
+			// enclosing group.
+			c.Next()
+			return
+		}
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -28,7 +41,7 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		tokenString := tokenParts[1]
-		email, err := utils.ValidateJWT(tokenString)
+		claims, err := utils.ParseJWT(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid token",
@@ -37,8 +50,67 @@ func JWTAuth() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if sessions != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			if err := sessions.ValidateSession(c.Request.Context(), claims.Subject, issuedAt); err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, domain.ErrUnauthorized) {
+					status = http.StatusUnauthorized
+				}
+				c.JSON(status, gin.H{
+					"error":   "Invalid token",
+					"message": err.Error(),
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		role := claims.Role
+		if role == domain.RoleAdmin && !claims.MFA {
+			role = domain.RoleCustomer
+			c.Set("mfa_required", true)
+		}
+		c.Set("email", claims.Email)
+		c.Set("customer_id", claims.Subject)
+		c.Set("role", role)
+		c.Set("mfa", claims.MFA)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuth identifies the caller when a token is sent but lets
+// anonymous requests through, for public routes with admin-only options.
+func OptionalJWTAuth(sessions domain.SessionValidator) gin.HandlerFunc {
+	auth := JWTAuth(sessions)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" || c.GetString("api_key_id") != "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
 
-		c.Set("email", email)
+// RequireRole must run after JWTAuth.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role == domain.RoleAdmin && c.GetBool("mfa_required") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Two-factor authentication required",
+				"message": "Admins must log in with a second factor; enroll at /api/me/mfa/enroll",
+			})
+			c.Abort()
+			return
+		}
+		if c.GetString("role") != role {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
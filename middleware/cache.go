@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"intern-project-v2/config"
+	"intern-project-v2/domain"
 	"time"
 
 	"github.com/gin-contrib/cache"
@@ -9,5 +10,22 @@ import (
 )
 
 func CacheMiddleware(duration time.Duration, handler gin.HandlerFunc) gin.HandlerFunc {
-	return cache.CachePage(config.CacheStore, duration, handler)
+	cached := cache.CachePage(config.CacheStore, duration, handler)
+	return func(c *gin.Context) {
+		// Authenticated responses can depend on the caller (e.g. admin-only
+		// filters), so they must never be served from, or stored in, the
+		// shared page cache.
+		if hasCredentials(c) {
+			handler(c)
+			return
+		}
+		cached(c)
+	}
+}
+
+// hasCredentials reports whether a request carries a token or an API key, or
+// was authenticated by an earlier middleware.
+func hasCredentials(c *gin.Context) bool {
+	return c.GetHeader("Authorization") != "" || c.GetHeader(domain.APIKeyHeader) != "" ||
+		c.GetString("customer_id") != "" || c.GetString("api_key_id") != ""
 }
@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+)
+
+var _ domain.Notifier = (*EmailNotifier)(nil)
+
+const (
+	defaultQueueSize = 256
+	sendAttempts     = 3
+	sendRetryDelay   = 2 * time.Second
+)
+
+// ErrQueueFull is returned by Notify when emails are queued faster than
+// they can be sent.
+var ErrQueueFull = errors.New("email queue is full")
+
+// EmailNotifier renders emails when Notify is called and sends them from a
+// queue in the background, so requests do not wait for the mail server.
+// Queued emails are lost when the process exits.
+type EmailNotifier struct {
+	sender domain.EmailSender
+	queue  chan *domain.Email
+}
+
+func NewEmailNotifier(sender domain.EmailSender) *EmailNotifier {
+	return &EmailNotifier{
+		sender: sender,
+		queue:  make(chan *domain.Email, defaultQueueSize),
+	}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, to string, template string, data interface{}) error {
+	email, err := Render(to, template, data)
+	if err != nil {
+		return err
+	}
+	select {
+	case n.queue <- email:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start sends queued emails with the given number of workers until ctx is
+// cancelled.
+func (n *EmailNotifier) Start(ctx context.Context, workers int) {
+	for range max(workers, 1) {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case email := <-n.queue:
+					n.send(ctx, email)
+				}
+			}
+		}()
+	}
+}
+
+// send tries an email a few times before giving up on it.
+func (n *EmailNotifier) send(ctx context.Context, email *domain.Email) {
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		if err = n.sender.Send(ctx, email); err == nil {
+			return
+		}
+		if attempt < sendAttempts {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(attempt) * sendRetryDelay):
+			}
+		}
+	}
+	logger.Error("Failed to send email", "to", email.To, "subject", email.Subject, "attempts", sendAttempts, "error", err)
+}
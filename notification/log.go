@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+)
+
+var (
+	_ domain.BackInStockNotifier = (*LogNotifier)(nil)
+	_ domain.LowStockListener    = (*LogNotifier)(nil)
+)
+
+// LogNotifier writes notifications to the application log. It stands in for
+// a real delivery channel until one is integrated.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) NotifyBackInStock(ctx context.Context, customerID string, product *domain.Product, item *domain.WishlistItem) error {
+	logger.Info("Wishlisted item is back in stock",
+		"customer_id", customerID,
+		"product_id", item.ProductID,
+		"variant_id", item.VariantID,
+		"product_name", product.Name,
+	)
+	return nil
+}
+
+func (n *LogNotifier) LowStock(ctx context.Context, alert *domain.LowStockAlert) {
+	logger.Warn("Stock is at or below the reorder threshold",
+		"product_id", alert.Product.Id.Hex(),
+		"variant_id", alert.VariantID,
+		"sku", alert.SKU,
+		"stock", alert.Stock,
+		"threshold", alert.Threshold,
+		"movement", alert.Movement.Type,
+	)
+}
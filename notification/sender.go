@@ -0,0 +1,129 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	_ domain.EmailSender = (*SMTPSender)(nil)
+	_ domain.EmailSender = (*FileSender)(nil)
+	_ domain.EmailSender = (*ConsoleSender)(nil)
+)
+
+// SMTPSender sends emails through an SMTP server. Authentication is only
+// used when a username is set; net/smtp upgrades to TLS when the server
+// offers STARTTLS.
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPSender(host string, port string, username string, password string, from string) *SMTPSender {
+	sender := &SMTPSender{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+	}
+	if username != "" {
+		sender.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return sender
+}
+
+func (s *SMTPSender) Send(ctx context.Context, email *domain.Email) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{email.To}, message(s.from, email))
+}
+
+// FileSender writes every email as an .eml file to a directory, so mails
+// sent during development can be opened in a mail client.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSender{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, email *domain.Email) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), bson.NewObjectID().Hex())
+	return os.WriteFile(filepath.Join(s.dir, name), message(s.from, email), 0o644)
+}
+
+// ConsoleSender writes emails to the application log instead of sending
+// them.
+type ConsoleSender struct{}
+
+func NewConsoleSender() *ConsoleSender {
+	return &ConsoleSender{}
+}
+
+func (s *ConsoleSender) Send(ctx context.Context, email *domain.Email) error {
+	logger.Info("Email", "to", email.To, "subject", email.Subject, "html", email.HTML)
+	return nil
+}
+
+// SenderFromEnv sends through SMTP_HOST when it is set, authenticating with
+// SMTP_USERNAME and SMTP_PASSWORD, otherwise writes to MAIL_DIR when that is
+// set, and logs emails as a last resort. MAIL_FROM is the sender address.
+func SenderFromEnv() domain.EmailSender {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPSender(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+	}
+	if dir := os.Getenv("MAIL_DIR"); dir != "" {
+		sender, err := NewFileSender(dir, from)
+		if err == nil {
+			return sender
+		}
+		logger.Warn("Falling back to logging emails", "mail_dir", dir, "error", err)
+	}
+	return NewConsoleSender()
+}
+
+// message formats an email as an RFC 5322 message with a quoted-printable
+// HTML body. Line breaks are removed from header values so a crafted
+// address cannot add headers.
+func message(from string, email *domain.Email) []byte {
+	header := strings.NewReplacer("\r", "", "\n", "")
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(&msg, "To: %s\r\n", header.Replace(email.To))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", header.Replace(email.Subject)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	msg.WriteString("\r\n")
+	body := quotedprintable.NewWriter(&msg)
+	body.Write([]byte(strings.ReplaceAll(email.HTML, "\n", "\r\n")))
+	body.Close()
+	return msg.Bytes()
+}
@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"intern-project-v2/domain"
+	"strings"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templates holds one template set per email, each made of the layout and
+// the email's "subject" and "content" blocks.
+var templates = parseTemplates(
+	domain.EmailWelcome,
+	domain.EmailOrderConfirmation,
+	domain.EmailPasswordReset,
+	domain.EmailVerification,
+)
+
+func parseTemplates(names ...string) map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		parsed[name] = template.Must(template.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return parsed
+}
+
+// Render builds the email for a template and its data.
+func Render(to string, name string, data interface{}) (*domain.Email, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "layout.html", data); err != nil {
+		return nil, fmt.Errorf("render %s body: %w", name, err)
+	}
+	return &domain.Email{
+		To: to,
+		// The subject is escaped for HTML like the rest of the template, but
+		// goes into a mail header as plain text.
+		Subject: strings.TrimSpace(html.UnescapeString(subject.String())),
+		HTML:    body.String(),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var _ domain.PaymentGateway = (*FakeGateway)(nil)
+
+// FakeGateway accepts every refund and keeps them in memory. It stands in for
+// a real payment provider until one is integrated.
+type FakeGateway struct {
+	mu      sync.Mutex
+	refunds map[string]float64
+	keys    map[string]string
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		refunds: make(map[string]float64),
+		keys:    make(map[string]string),
+	}
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, idempotencyKey string, orderID string, amount float64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("refund amount must be positive: %.2f", amount)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if refundID, ok := g.keys[idempotencyKey]; ok {
+		logger.Info("Refund already issued by fake gateway", "order_id", orderID, "refund_id", refundID)
+		return refundID, nil
+	}
+	refundID := "rf_" + bson.NewObjectID().Hex()
+	g.refunds[refundID] = amount
+	g.keys[idempotencyKey] = refundID
+	logger.Info("Refund issued by fake gateway", "order_id", orderID, "refund_id", refundID, "amount", amount)
+	return refundID, nil
+}
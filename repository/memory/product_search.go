@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var _ domain.ProductSearcher = (*productSearcherImpl)(nil)
+
+type productLister interface {
+	GetAll(ctx context.Context) ([]*domain.Product, error)
+}
+
+// productSearcherImpl scores products in memory. It is used in tests and as a
+// fallback when the MongoDB text index cannot be created, so it loads the
+// whole catalog on every search and only suits small catalogs.
+type productSearcherImpl struct {
+	products productLister
+}
+
+func NewProductSearcher(products productLister) domain.ProductSearcher {
+	return &productSearcherImpl{
+		products: products,
+	}
+}
+
+func (ps *productSearcherImpl) Search(ctx context.Context, query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	products, err := ps.products.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := tokenize(query.Query)
+	result := &domain.ProductSearchResult{
+		Products: []*domain.ProductSearchHit{},
+		Page:     query.Page,
+		Limit:    query.Limit,
+		Facets:   domain.SearchFacets{Price: domain.NewPriceFacets()},
+	}
+
+	var hits []*domain.ProductSearchHit
+	for _, product := range products {
+		if product.DeletedAt != nil {
+			continue
+		}
+		score := relevance(terms, tokenize(product.Name))
+		if len(terms) > 0 && score == 0 {
+			continue
+		}
+
+		countFacets(&result.Facets, product)
+		if !matchesFilters(query, product) {
+			continue
+		}
+		hits = append(hits, &domain.ProductSearchHit{Product: product, Score: score})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Name < hits[j].Name
+	})
+
+	result.Total = int64(len(hits))
+	start := (query.Page - 1) * query.Limit
+	if start < len(hits) {
+		end := start + query.Limit
+		if end > len(hits) {
+			end = len(hits)
+		}
+		result.Products = hits[start:end]
+	}
+	return result, nil
+}
+
+// relevance gives a full point for every query term matching a word of the
+// product name and half a point for a prefix match.
+func relevance(terms, words []string) float64 {
+	score := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, word := range words {
+			if word == term {
+				best = 1
+				break
+			}
+			if strings.HasPrefix(word, term) {
+				best = 0.5
+			}
+		}
+		score += best
+	}
+	return score
+}
+
+func matchesFilters(query *domain.ProductSearchQuery, product *domain.Product) bool {
+	if query.MinPrice != nil && product.Price < *query.MinPrice {
+		return false
+	}
+	if query.MaxPrice != nil && product.Price > *query.MaxPrice {
+		return false
+	}
+	if query.InStock && product.Stock <= 0 {
+		return false
+	}
+	return true
+}
+
+func countFacets(facets *domain.SearchFacets, product *domain.Product) {
+	for _, facet := range facets.Price {
+		if product.Price >= facet.Min && (facet.Max == nil || product.Price < *facet.Max) {
+			facet.Count++
+			break
+		}
+	}
+	if product.Stock > 0 {
+		facets.Availability.InStock++
+	} else {
+		facets.Availability.OutOfStock++
+	}
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
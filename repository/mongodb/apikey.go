@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.APIKeyRepository = (*apiKeyRepositoryImpl)(nil)
+
+type apiKeyRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewAPIKeyRepository(db *mongo.Database) domain.APIKeyRepository {
+	return &apiKeyRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureAPIKeyIndexes looks keys up by their prefix, which is unique.
+func EnsureAPIKeyIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("api_keys")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "prefix", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("api_keys_prefix"),
+	})
+	if err != nil {
+		logger.Error("Failed to create API key indexes", "error", err)
+	}
+	return err
+}
+
+func (kr *apiKeyRepositoryImpl) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	collection := kr.conn.Collection("api_keys")
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Failed to find API keys", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*domain.APIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (kr *apiKeyRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	return kr.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (kr *apiKeyRepositoryImpl) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	return kr.findOne(ctx, bson.M{"prefix": prefix})
+}
+
+func (kr *apiKeyRepositoryImpl) findOne(ctx context.Context, filter bson.M) (*domain.APIKey, error) {
+	collection := kr.conn.Collection("api_keys")
+	var key domain.APIKey
+	err := collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (kr *apiKeyRepositoryImpl) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	collection := kr.conn.Collection("api_keys")
+	result, err := collection.InsertOne(ctx, key)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: an API key with prefix %s already exists", domain.ErrConflict, key.Prefix)
+		}
+		logger.Error("Failed to create API key", "name", key.Name, "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	key.Id = insertedID
+	return key, nil
+}
+
+// Revoke marks a key as revoked. Revoking a key twice keeps the first
+// revocation time.
+func (kr *apiKeyRepositoryImpl) Revoke(ctx context.Context, id string, revokedAt time.Time) (*domain.APIKey, error) {
+	collection := kr.conn.Collection("api_keys")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	_, err = collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt}},
+	)
+	if err != nil {
+		logger.Error("Failed to revoke API key", "id", id, "error", err)
+		return nil, err
+	}
+	return kr.findOne(ctx, bson.M{"_id": objectID})
+}
+
+// Touch records when a key was last used.
+func (kr *apiKeyRepositoryImpl) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	collection := kr.conn.Collection("api_keys")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$max": bson.M{"last_used_at": usedAt}})
+	if err != nil {
+		logger.Error("Failed to record API key use", "id", id, "error", err)
+	}
+	return err
+}
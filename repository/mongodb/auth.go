@@ -2,8 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
@@ -20,18 +25,135 @@ func NewAuthRepository(db *mongo.Database) domain.AuthRepository {
 }
 func (ar *authRepositoryImpl) Register(ctx context.Context, customer *domain.Customer) error {
 	collection := ar.db.Collection("customers")
-	_, err := collection.InsertOne(ctx, customer)
+	result, err := collection.InsertOne(ctx, customer)
 	if err != nil {
 		return err
 	}
+	if insertedID, ok := result.InsertedID.(bson.ObjectID); ok {
+		customer.Id = insertedID
+	}
 	return nil
 }
 func (ar *authRepositoryImpl) Login(ctx context.Context, email string) (*domain.Customer, error) {
 	collection := ar.db.Collection("customers")
 	var customer domain.Customer
-	err := collection.FindOne(ctx, map[string]interface{}{"email": email}).Decode(&customer)
+	err := collection.FindOne(ctx, notDeleted(bson.M{"email": email})).Decode(&customer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (ar *authRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Customer, error) {
+	collection := ar.db.Collection("customers")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	var customer domain.Customer
+	err = collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&customer)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &customer, nil
 }
+
+// SetPassword replaces the password hash and signs out every session that
+// started before the change.
+func (ar *authRepositoryImpl) SetPassword(ctx context.Context, customerID string, hashedPassword string, changedAt time.Time) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{"$set": bson.M{"password": hashedPassword, "sessions_valid_after": changedAt}})
+}
+
+// SetEmail changes the address, which then has to be verified again.
+func (ar *authRepositoryImpl) SetEmail(ctx context.Context, customerID string, email string) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{
+		"$set":   bson.M{"email": email, "email_verified": false},
+		"$unset": bson.M{"email_verified_at": ""},
+	})
+}
+
+func (ar *authRepositoryImpl) MarkEmailVerified(ctx context.Context, customerID string, verifiedAt time.Time) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{"$set": bson.M{"email_verified": true, "email_verified_at": verifiedAt}})
+}
+
+// SetPendingMFASecret keeps a new TOTP secret until the customer proves
+// their authenticator app has it.
+func (ar *authRepositoryImpl) SetPendingMFASecret(ctx context.Context, customerID string, secret string) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{"$set": bson.M{"mfa_pending_secret": secret}})
+}
+
+func (ar *authRepositoryImpl) EnableMFA(ctx context.Context, customerID string, secret string, recoveryCodes []string, step int64) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{
+		"$set": bson.M{
+			"mfa_enabled":        true,
+			"mfa_secret":         secret,
+			"mfa_recovery_codes": recoveryCodes,
+			"mfa_last_step":      step,
+		},
+		"$unset": bson.M{"mfa_pending_secret": ""},
+	})
+}
+
+func (ar *authRepositoryImpl) DisableMFA(ctx context.Context, customerID string) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{
+		"$set":   bson.M{"mfa_enabled": false},
+		"$unset": bson.M{"mfa_secret": "", "mfa_pending_secret": "", "mfa_recovery_codes": "", "mfa_last_step": ""},
+	})
+}
+
+func (ar *authRepositoryImpl) SetRecoveryCodes(ctx context.Context, customerID string, recoveryCodes []string) error {
+	return ar.updateCustomer(ctx, customerID, bson.M{"$set": bson.M{"mfa_recovery_codes": recoveryCodes}})
+}
+
+// UseTOTPStep records the period of a code just used. It fails with
+// ErrConflict when a code of that or a later period was used already, so two
+// requests racing with the same code cannot both succeed.
+func (ar *authRepositoryImpl) UseTOTPStep(ctx context.Context, customerID string, step int64) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"mfa_last_step": bson.M{"$lt": step}},
+		bson.M{"mfa_last_step": bson.M{"$exists": false}},
+	}}
+	err := ar.updateCustomerWhere(ctx, customerID, filter, bson.M{"$set": bson.M{"mfa_last_step": step}})
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%w: the code was already used", domain.ErrConflict)
+	}
+	return err
+}
+
+// UseRecoveryCode removes a recovery code, given by its hash, and fails with
+// ErrNotFound when the customer has no such code left.
+func (ar *authRepositoryImpl) UseRecoveryCode(ctx context.Context, customerID string, hash string) error {
+	filter := bson.M{"mfa_recovery_codes": hash}
+	return ar.updateCustomerWhere(ctx, customerID, filter, bson.M{"$pull": bson.M{"mfa_recovery_codes": hash}})
+}
+
+func (ar *authRepositoryImpl) updateCustomer(ctx context.Context, customerID string, update bson.M) error {
+	return ar.updateCustomerWhere(ctx, customerID, bson.M{}, update)
+}
+
+// updateCustomerWhere updates the customer if they also match filter.
+func (ar *authRepositoryImpl) updateCustomerWhere(ctx context.Context, customerID string, filter bson.M, update bson.M) error {
+	collection := ar.db.Collection("customers")
+	objectID, err := bson.ObjectIDFromHex(customerID)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", customerID, "error", err)
+		return domain.ErrInvalidInput
+	}
+	filter["_id"] = objectID
+	result, err := collection.UpdateOne(ctx, notDeleted(filter), update)
+	if err != nil {
+		logger.Error("Failed to update customer credentials", "id", customerID, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.AuthTokenRepository = (*authTokenRepositoryImpl)(nil)
+
+type authTokenRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewAuthTokenRepository(db *mongo.Database) domain.AuthTokenRepository {
+	return &authTokenRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureAuthTokenIndexes looks tokens up by hash and lets MongoDB remove
+// them a day after they expire.
+func EnsureAuthTokenIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("auth_tokens")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("auth_tokens_hash"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour).Seconds())).SetName("auth_tokens_expiry"),
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to create auth token indexes", "error", err)
+	}
+	return err
+}
+
+func (tr *authTokenRepositoryImpl) Create(ctx context.Context, token *domain.AuthToken) error {
+	collection := tr.conn.Collection("auth_tokens")
+	result, err := collection.InsertOne(ctx, token)
+	if err != nil {
+		logger.Error("Failed to create auth token", "customer_id", token.CustomerID, "purpose", token.Purpose, "error", err)
+		return err
+	}
+	if insertedID, ok := result.InsertedID.(bson.ObjectID); ok {
+		token.Id = insertedID
+	}
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. Marking
+// and checking happen in one update, so a token works only once even when
+// it is submitted twice at the same time.
+func (tr *authTokenRepositoryImpl) Consume(ctx context.Context, purpose string, hash string, now time.Time) (*domain.AuthToken, error) {
+	collection := tr.conn.Collection("auth_tokens")
+	filter := bson.M{
+		"hash":       hash,
+		"purpose":    purpose,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var token domain.AuthToken
+	err := collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}, opts).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke uses up every open token of a customer for a purpose, so only the
+// newest emailed link works.
+func (tr *authTokenRepositoryImpl) Revoke(ctx context.Context, customerID string, purpose string, now time.Time) error {
+	collection := tr.conn.Collection("auth_tokens")
+	filter := bson.M{"customer_id": customerID, "purpose": purpose, "used_at": nil}
+	_, err := collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"used_at": now}})
+	if err != nil {
+		logger.Error("Failed to revoke auth tokens", "customer_id", customerID, "purpose", purpose, "error", err)
+	}
+	return err
+}
+
+// DeleteByCustomer removes every token of a customer, used or not.
+func (tr *authTokenRepositoryImpl) DeleteByCustomer(ctx context.Context, customerID string) error {
+	collection := tr.conn.Collection("auth_tokens")
+	_, err := collection.DeleteMany(ctx, bson.M{"customer_id": customerID})
+	if err != nil {
+		logger.Error("Failed to delete auth tokens", "customer_id", customerID, "error", err)
+	}
+	return err
+}
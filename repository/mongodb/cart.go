@@ -2,13 +2,18 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 	"intern-project-v2/domain"
 	"intern-project-v2/logger"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+var _ domain.CartRepository = (*cartRepositoryImpl)(nil)
+
 type cartRepositoryImpl struct {
 	conn *mongo.Database
 }
@@ -35,6 +40,7 @@ func (cr *cartRepositoryImpl) AddToCart(ctx context.Context, customerID string,
 				Items:      []*domain.CartItem{item},
 				TotalItems: item.Quantity,
 				TotalPrice: item.ProductPrice * float64(item.Quantity),
+				UpdatedAt:  time.Now(),
 			}
 			result, err := collection.InsertOne(ctx, newCart)
 			if err != nil {
@@ -58,7 +64,7 @@ func (cr *cartRepositoryImpl) AddToCart(ctx context.Context, customerID string,
 	}
 	found := false
 	for i, existingItem := range existingCart.Items {
-		if existingItem.ProductID == item.ProductID {
+		if sameCartLine(existingItem, item.ProductID, item.VariantID) {
 			existingCart.Items[i].Quantity += item.Quantity
 			existingCart.TotalItems += item.Quantity
 			existingCart.TotalPrice += item.ProductPrice * float64(item.Quantity)
@@ -70,8 +76,7 @@ func (cr *cartRepositoryImpl) AddToCart(ctx context.Context, customerID string,
 		existingCart.Items = append(existingCart.Items, item)
 	}
 	cr.recalCartTotals(&existingCart)
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": existingCart.Id}, bson.M{"$set": existingCart})
-	if err != nil {
+	if err := cr.replaceVersion(ctx, collection, &existingCart); err != nil {
 		logger.Error("Failed to update existing cart", "error", err)
 		return nil, err
 	}
@@ -86,7 +91,7 @@ func (cr *cartRepositoryImpl) GetCartByCustomerId(ctx context.Context, customerI
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			logger.Error("Customer's cart is empty", "customer_id", customerID)
-			return nil, err
+			return nil, domain.ErrNotFound
 		}
 		logger.Error("Failed to find cart for customer", "customer_id", customerID, "error", err)
 		return nil, err
@@ -109,7 +114,7 @@ func (cr *cartRepositoryImpl) UpdateCartItem(ctx context.Context, customerID str
 
 	found := false
 	for i, existingItem := range existingCart.Items {
-		if existingItem.ProductID == item.ProductID {
+		if sameCartLine(existingItem, item.ProductID, item.VariantID) {
 			existingCart.Items[i].Quantity = item.Quantity
 			existingCart.Items[i].Subtotal = item.ProductPrice * float64(item.Quantity)
 			found = true
@@ -122,15 +127,16 @@ func (cr *cartRepositoryImpl) UpdateCartItem(ctx context.Context, customerID str
 	}
 
 	cr.recalCartTotals(&existingCart)
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": existingCart.Id}, bson.M{"$set": existingCart})
-	if err != nil {
+	if err := cr.replaceVersion(ctx, collection, &existingCart); err != nil {
 		logger.Error("Failed to update cart", "error", err)
 		return nil, err
 	}
 	return &existingCart, nil
 }
 
-func (cr *cartRepositoryImpl) RemoveCartItem(ctx context.Context, customerID string, productID string) (*domain.Cart, error) {
+// RemoveCartItem removes one variant of a product from the cart, or every
+// line of the product when variantID is empty.
+func (cr *cartRepositoryImpl) RemoveCartItem(ctx context.Context, customerID string, productID string, variantID string) (*domain.Cart, error) {
 	collection := cr.conn.Collection("carts")
 	var existingCart domain.Cart
 	err := collection.FindOne(ctx, bson.M{"customer_id": customerID}).Decode(&existingCart)
@@ -145,7 +151,7 @@ func (cr *cartRepositoryImpl) RemoveCartItem(ctx context.Context, customerID str
 
 	var updatedItems []*domain.CartItem
 	for _, item := range existingCart.Items {
-		if item.ProductID != productID {
+		if item.ProductID != productID || (variantID != "" && item.VariantID != variantID) {
 			updatedItems = append(updatedItems, item)
 		}
 	}
@@ -157,9 +163,7 @@ func (cr *cartRepositoryImpl) RemoveCartItem(ctx context.Context, customerID str
 
 	existingCart.Items = updatedItems
 	cr.recalCartTotals(&existingCart)
-
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": existingCart.Id}, bson.M{"$set": existingCart})
-	if err != nil {
+	if err := cr.replaceVersion(ctx, collection, &existingCart); err != nil {
 		logger.Error("Failed to update cart after removing item", "error", err)
 		return nil, err
 	}
@@ -179,6 +183,157 @@ func (cr *cartRepositoryImpl) ClearCart(ctx context.Context, customerID string)
 	return nil
 }
 
+// RemoveProductFromCarts pulls a product out of every cart holding it and
+// recomputes the totals in the same update.
+func (cr *cartRepositoryImpl) RemoveProductFromCarts(ctx context.Context, productID string) (int64, error) {
+	collection := cr.conn.Collection("carts")
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"items": bson.M{"$filter": bson.M{
+			"input": "$items",
+			"cond":  bson.M{"$ne": bson.A{"$$this.product_id", productID}},
+		}}}}},
+		{{Key: "$set", Value: bson.M{
+			"total_items": bson.M{"$sum": "$items.quantity"},
+			"total_price": bson.M{"$sum": bson.M{"$map": bson.M{
+				"input": "$items",
+				"in":    bson.M{"$multiply": bson.A{"$$this.product_price", "$$this.quantity"}},
+			}}},
+			"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+		}}},
+	}
+	result, err := collection.UpdateMany(ctx, bson.M{"items.product_id": productID}, update)
+	if err != nil {
+		logger.Error("Failed to remove product from carts", "product_id", productID, "error", err)
+		return 0, err
+	}
+	logger.Info("Removed product from carts", "product_id", productID, "carts", result.ModifiedCount)
+	return result.ModifiedCount, nil
+}
+
+// Save writes a whole cart at once. An existing cart is only overwritten if
+// its version has not moved since it was read, otherwise ErrConflict is
+// returned and the caller can retry with fresh data.
+func (cr *cartRepositoryImpl) Save(ctx context.Context, cart *domain.Cart) (*domain.Cart, error) {
+	collection := cr.conn.Collection("carts")
+	cr.recalCartTotals(cart)
+	cart.UpdatedAt = time.Now()
+	cart.AbandonedAt = nil
+	if cart.Id.IsZero() {
+		cart.Version = 1
+		result, err := collection.InsertOne(ctx, cart)
+		if err != nil {
+			logger.Error("Failed to create cart", "customer_id", cart.CustomerID, "error", err)
+			return nil, err
+		}
+		if insertedID, ok := result.InsertedID.(bson.ObjectID); ok {
+			cart.Id = insertedID
+		}
+		return cart, nil
+	}
+
+	filter := versionFilter(cart)
+	cart.Version++
+	result, err := collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"items":       cart.Items,
+			"total_items": cart.TotalItems,
+			"total_price": cart.TotalPrice,
+			"version":     cart.Version,
+			"updated_at":  cart.UpdatedAt,
+		},
+		"$unset": bson.M{"abandoned_at": ""},
+	})
+	if err != nil {
+		logger.Error("Failed to save cart", "customer_id", cart.CustomerID, "error", err)
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("%w: cart was changed by another request", domain.ErrConflict)
+	}
+	return cart, nil
+}
+
+// FindAbandoned returns carts with items that have not changed since
+// idleSince and were not reported as abandoned yet, longest idle first.
+// Carts saved before UpdatedAt existed are never reported.
+func (cr *cartRepositoryImpl) FindAbandoned(ctx context.Context, idleSince time.Time, limit int) ([]*domain.Cart, error) {
+	collection := cr.conn.Collection("carts")
+	filter := bson.M{
+		"items.0":      bson.M{"$exists": true},
+		"updated_at":   bson.M{"$lt": idleSince},
+		"abandoned_at": bson.M{"$exists": false},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find abandoned carts", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var carts []*domain.Cart
+	if err := cursor.All(ctx, &carts); err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
+// MarkAbandoned flags a cart as abandoned unless it changed since it was
+// read, and reports whether it did.
+func (cr *cartRepositoryImpl) MarkAbandoned(ctx context.Context, cart *domain.Cart) (bool, error) {
+	collection := cr.conn.Collection("carts")
+	now := time.Now()
+	filter := bson.M{"_id": cart.Id, "version": cart.Version, "abandoned_at": bson.M{"$exists": false}}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"abandoned_at": now}})
+	if err != nil {
+		logger.Error("Failed to mark cart as abandoned", "cart_id", cart.Id.Hex(), "error", err)
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, nil
+	}
+	cart.AbandonedAt = &now
+	return true, nil
+}
+
+// replaceVersion writes a cart the customer changed over the version it was
+// read with and bumps the version. If another request wrote the cart in
+// between, nothing is written and ErrConflict is returned, as in Save.
+func (cr *cartRepositoryImpl) replaceVersion(ctx context.Context, collection *mongo.Collection, cart *domain.Cart) error {
+	filter := versionFilter(cart)
+	cart.Version++
+	result, err := collection.UpdateOne(ctx, filter, touch(cart))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: cart was changed by another request", domain.ErrConflict)
+	}
+	return nil
+}
+
+// versionFilter matches a cart while it still has the version it was read
+// with. Carts written before versioning have no version field at all.
+func versionFilter(cart *domain.Cart) bson.M {
+	version := bson.M{"version": cart.Version}
+	if cart.Version == 0 {
+		version = bson.M{"$or": bson.A{bson.M{"version": 0}, bson.M{"version": bson.M{"$exists": false}}}}
+	}
+	return bson.M{"$and": bson.A{bson.M{"_id": cart.Id}, version}}
+}
+
+// touch builds the update that saves a cart the customer changed, which
+// also makes an abandoned cart active again.
+func touch(cart *domain.Cart) bson.M {
+	cart.UpdatedAt = time.Now()
+	cart.AbandonedAt = nil
+	return bson.M{"$set": cart, "$unset": bson.M{"abandoned_at": ""}}
+}
+
+func sameCartLine(item *domain.CartItem, productID, variantID string) bool {
+	return item.ProductID == productID && item.VariantID == variantID
+}
+
 func (cr *cartRepositoryImpl) recalCartTotals(cart *domain.Cart) {
 	totalItems := 0
 	totalPrice := 0.0
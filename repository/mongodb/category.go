@@ -0,0 +1,188 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.CategoryRepository = (*categoryRepositoryImpl)(nil)
+
+type categoryRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewCategoryRepository(db *mongo.Database) domain.CategoryRepository {
+	return &categoryRepositoryImpl{
+		conn: db,
+	}
+}
+
+func (cr *categoryRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Category, error) {
+	collection := cr.conn.Collection("categories")
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []*domain.Category
+	for cursor.Next(ctx) {
+		var category domain.Category
+		if err := cursor.Decode(&category); err != nil {
+			return nil, err
+		}
+		categories = append(categories, &category)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (cr *categoryRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	collection := cr.conn.Collection("categories")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var category domain.Category
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&category)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Category not found", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (cr *categoryRepositoryImpl) Create(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	collection := cr.conn.Collection("categories")
+	result, err := collection.InsertOne(ctx, category)
+	if err != nil {
+		logger.Error("Failed to create category", "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	category.Id = insertedID
+	return category, nil
+}
+
+func (cr *categoryRepositoryImpl) Update(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	collection := cr.conn.Collection("categories")
+	update := bson.M{"$set": bson.M{"name": category.Name, "slug": category.Slug}}
+	if category.ParentID != nil {
+		update["$set"].(bson.M)["parent_id"] = category.ParentID
+	} else {
+		update["$unset"] = bson.M{"parent_id": ""}
+	}
+
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, bson.M{"_id": category.Id}, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Category not found", "id", category.Id.Hex())
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var updatedCategory domain.Category
+	if err := result.Decode(&updatedCategory); err != nil {
+		return nil, err
+	}
+	return &updatedCategory, nil
+}
+
+func (cr *categoryRepositoryImpl) Delete(ctx context.Context, id string) (*domain.Category, error) {
+	collection := cr.conn.Collection("categories")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var deletedCategory domain.Category
+	err = collection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&deletedCategory)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Category not found for deletion", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &deletedCategory, nil
+}
+
+// GetDescendantIDs walks the parent_id links downwards from a category with
+// $graphLookup and returns the ids of all of its descendants.
+func (cr *categoryRepositoryImpl) GetDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	collection := cr.conn.Collection("categories")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": objectID}}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":             "categories",
+			"startWith":        "$_id",
+			"connectFromField": "_id",
+			"connectToField":   "parent_id",
+			"as":               "descendants",
+		}}},
+		{{Key: "$project", Value: bson.M{"descendants._id": 1}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Failed to look up category descendants", "id", id, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Descendants []struct {
+			Id bson.ObjectID `bson:"_id"`
+		} `bson:"descendants"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	ids := make([]string, 0, len(results[0].Descendants))
+	for _, descendant := range results[0].Descendants {
+		ids = append(ids, descendant.Id.Hex())
+	}
+	return ids, nil
+}
+
+func (cr *categoryRepositoryImpl) CountChildren(ctx context.Context, id string) (int64, error) {
+	collection := cr.conn.Collection("categories")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return 0, domain.ErrInvalidInput
+	}
+	return collection.CountDocuments(ctx, bson.M{"parent_id": objectID})
+}
@@ -6,9 +6,11 @@ import (
 	"intern-project-v2/domain"
 	"intern-project-v2/logger"
 	"log"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 var _ domain.CustomerRepository = (*customerRepositoryImpl)(nil)
@@ -24,14 +26,22 @@ func NewCustomerRepository(db *mongo.Database) domain.CustomerRepository {
 }
 
 func (cr *customerRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Customer, error) {
+	return cr.find(ctx, notDeleted(bson.M{}))
+}
+
+func (cr *customerRepositoryImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Customer, error) {
+	return cr.find(ctx, bson.M{})
+}
+
+func (cr *customerRepositoryImpl) find(ctx context.Context, filter bson.M) ([]*domain.Customer, error) {
 	collection := cr.conn.Collection("customers")
-	cursor, err := collection.Find(context.TODO(), bson.M{})
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.TODO())
+	defer cursor.Close(ctx)
 	var customers []*domain.Customer
-	for cursor.Next(context.TODO()) {
+	for cursor.Next(ctx) {
 		var customer domain.Customer
 		if err := cursor.Decode(&customer); err != nil {
 			return nil, err
@@ -39,6 +49,10 @@ func (cr *customerRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Custome
 		customers = append(customers, &customer)
 	}
 
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
 	return customers, nil
 }
 
@@ -50,11 +64,11 @@ func (cr *customerRepositoryImpl) GetByID(ctx context.Context, id string) (*doma
 		log.Printf("Error converting ID to ObjectID: %v", ok)
 		return nil, ok
 	}
-	err := collection.FindOne(ctx, bson.M{"_id": ObjectID}).Decode(&customer)
+	err := collection.FindOne(ctx, notDeleted(bson.M{"_id": ObjectID})).Decode(&customer)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			logger.Error("Customer not found", "id", id)
-			return nil, err
+			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
@@ -105,7 +119,15 @@ func (cr *customerRepositoryImpl) Update(ctx context.Context, id string, custome
 
 	update := bson.M{"$set": updateFields}
 
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": ObjectID}, update)
+	if customerReq.Email != "" {
+		// A new address has to be verified again.
+		unverify := bson.M{"$set": bson.M{"email_verified": false}, "$unset": bson.M{"email_verified_at": ""}}
+		if _, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": ObjectID, "email": bson.M{"$ne": customerReq.Email}}), unverify); err != nil {
+			logger.Error("Failed to reset email verification", "id", id, "error", err)
+			return nil, err
+		}
+	}
+	result, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": ObjectID}), update)
 	if err != nil {
 		logger.Error("Failed to update customer", "error", err)
 		return nil, err
@@ -129,7 +151,8 @@ func (cr *customerRepositoryImpl) Delete(ctx context.Context, id string) (*domai
 
 	var deletedCustomer domain.Customer
 
-	err := collection.FindOneAndDelete(ctx, bson.M{"_id": ObjectID}).Decode(&deletedCustomer)
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(ctx, notDeleted(bson.M{"_id": ObjectID}), softDeleteUpdate(), otps).Decode(&deletedCustomer)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			logger.Error("Customer not found for deletion", "id", id)
@@ -140,3 +163,93 @@ func (cr *customerRepositoryImpl) Delete(ctx context.Context, id string) (*domai
 	}
 	return &deletedCustomer, nil
 }
+
+func (cr *customerRepositoryImpl) Restore(ctx context.Context, id string) (*domain.Customer, error) {
+	collection := cr.conn.Collection("customers")
+	ObjectID, ok := bson.ObjectIDFromHex(id)
+	if ok != nil {
+		log.Printf("Error converting ID to ObjectID: %v", ok)
+		return nil, ok
+	}
+
+	var restoredCustomer domain.Customer
+
+	filter := bson.M{"_id": ObjectID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(ctx, filter, update, otps).Decode(&restoredCustomer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Deleted customer not found for restore", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to restore customer", "error", err)
+		return nil, err
+	}
+	return &restoredCustomer, nil
+}
+
+func (cr *customerRepositoryImpl) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return purgeDeleted(ctx, cr.conn.Collection("customers"), deletedBefore)
+}
+
+// Anonymize erases the personal data of a customer and deletes the account.
+// The document stays until it is purged so references to it still resolve.
+func (cr *customerRepositoryImpl) Anonymize(ctx context.Context, id string, at time.Time) error {
+	collection := cr.conn.Collection("customers")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"name":                 domain.AnonymizedCustomerName,
+			"email":                "",
+			"phone":                "",
+			"password":             "",
+			"email_verified":       false,
+			"mfa_enabled":          false,
+			"anonymized_at":        at,
+			"sessions_valid_after": at,
+		},
+		"$unset": bson.M{
+			"email_verified_at":  "",
+			"mfa_secret":         "",
+			"mfa_pending_secret": "",
+			"mfa_recovery_codes": "",
+			"mfa_last_step":      "",
+		},
+		// Keeps an earlier deletion time, so the purge schedule is unchanged.
+		"$min": bson.M{"deleted_at": at},
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		logger.Error("Failed to anonymize customer", "id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetByIDIncludingDeleted finds a customer whether or not the account was
+// deleted.
+func (cr *customerRepositoryImpl) GetByIDIncludingDeleted(ctx context.Context, id string) (*domain.Customer, error) {
+	collection := cr.conn.Collection("customers")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	var customer domain.Customer
+	if err := collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&customer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to retrieve customer", "id", id, "error", err)
+		return nil, err
+	}
+	return &customer, nil
+}
@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.InventoryRepository = (*inventoryRepositoryImpl)(nil)
+
+type inventoryRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewInventoryRepository(db *mongo.Database) domain.InventoryRepository {
+	return &inventoryRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureInventoryIndexes indexes the ledger of each product, newest first.
+func EnsureInventoryIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("stock_movements")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}},
+		Options: options.Index().SetName("stock_movements_product_created"),
+	})
+	if err != nil {
+		logger.Error("Failed to create stock movement index", "error", err)
+	}
+	return err
+}
+
+// Record appends a movement to the ledger. Entries are never updated.
+func (ir *inventoryRepositoryImpl) Record(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, error) {
+	collection := ir.conn.Collection("stock_movements")
+	result, err := collection.InsertOne(ctx, movement)
+	if err != nil {
+		logger.Error("Failed to record stock movement", "product_id", movement.ProductID, "type", movement.Type, "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	movement.Id = insertedID
+	return movement, nil
+}
+
+func (ir *inventoryRepositoryImpl) GetMovements(ctx context.Context, query *domain.StockMovementQuery) ([]*domain.StockMovement, int64, error) {
+	collection := ir.conn.Collection("stock_movements")
+	filter := bson.M{"product_id": query.ProductID}
+	if query.VariantID != "" {
+		filter["variant_id"] = query.VariantID
+	}
+	if query.WarehouseID != "" {
+		filter["warehouse_id"] = query.WarehouseID
+	}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count stock movements", "product_id", query.ProductID, "error", err)
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.Limit)).
+		SetLimit(int64(query.Limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find stock movements", "product_id", query.ProductID, "error", err)
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	movements := []*domain.StockMovement{}
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+// Totals sums the ledger of a product per variant. Movements of the product
+// itself are keyed by the empty string.
+func (ir *inventoryRepositoryImpl) Totals(ctx context.Context, productID string) (map[string]int, error) {
+	collection := ir.conn.Collection("stock_movements")
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product_id": productID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$ifNull": bson.A{"$variant_id", ""}},
+			"quantity": bson.M{"$sum": "$quantity"},
+		}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Failed to sum stock movements", "product_id", productID, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		VariantID string `bson:"_id"`
+		Quantity  int    `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	totals := make(map[string]int, len(rows))
+	for _, row := range rows {
+		totals[row.VariantID] = row.Quantity
+	}
+	return totals, nil
+}
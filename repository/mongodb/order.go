@@ -26,8 +26,16 @@ func NewOrderRepository(db *mongo.Database) domain.OrderRepository {
 }
 
 func (or *orderRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Order, error) {
+	return or.find(ctx, notDeleted(bson.M{}))
+}
+
+func (or *orderRepositoryImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Order, error) {
+	return or.find(ctx, bson.M{})
+}
+
+func (or *orderRepositoryImpl) find(ctx context.Context, filter bson.M) ([]*domain.Order, error) {
 	collection := or.conn.Collection("orders")
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -58,11 +66,11 @@ func (or *orderRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.
 		return nil, err
 	}
 
-	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&order)
+	err = collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&order)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			logger.Error("Order not found", "id", id)
-			return nil, err
+			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
@@ -75,7 +83,11 @@ func (or *orderRepositoryImpl) Create(ctx context.Context, order *domain.OrderRe
 	newOrder := &domain.Order{
 		CustomerId:  order.CustomerId,
 		ProductIds:  order.ProductIds,
+		Items:       order.Items,
 		TotalAmount: order.TotalAmount,
+		Status:      domain.OrderStatusPending,
+		Allocation:  order.Allocation,
+		ShipTo:      order.ShipTo,
 		CreatedAt:   time.Now(),
 	}
 
@@ -103,18 +115,24 @@ func (or *orderRepositoryImpl) Update(ctx context.Context, id string, orderReq *
 
 	updateFields := bson.M{}
 	if orderReq.CustomerId != "" {
-		updateFields["customer_id"] = orderReq.CustomerId
+		updateFields["customerid"] = orderReq.CustomerId
 	}
 	if len(orderReq.ProductIds) > 0 {
 		updateFields["productids"] = orderReq.ProductIds
 	}
+	if len(orderReq.Items) > 0 {
+		updateFields["items"] = orderReq.Items
+	} else if len(orderReq.ProductIds) > 0 {
+		// Plain product ids replace any earlier order lines.
+		updateFields["items"] = nil
+	}
 	if orderReq.TotalAmount > 0 {
 		updateFields["totalamount"] = orderReq.TotalAmount
 	}
 
 	update := bson.M{"$set": updateFields}
 	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	result := collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, otps)
+	result := collection.FindOneAndUpdate(ctx, notDeleted(bson.M{"_id": objectID}), update, otps)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
 			logger.Error("Order not found", "id", id)
@@ -140,14 +158,15 @@ func (or *orderRepositoryImpl) Delete(ctx context.Context, id string) (*domain.O
 		return nil, err
 	}
 
-	result := collection.FindOneAndDelete(ctx, bson.M{"_id": objectID})
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, notDeleted(bson.M{"_id": objectID}), softDeleteUpdate(), otps)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
 			logger.Error("Order not found for deletion", "id", id)
-			return nil, err
+			return nil, domain.ErrNotFound
 		}
 		logger.Error("Failed to delete order", "id", id, "error", result.Err())
-		return nil, err
+		return nil, result.Err()
 	}
 
 	var deletedOrder domain.Order
@@ -158,3 +177,264 @@ func (or *orderRepositoryImpl) Delete(ctx context.Context, id string) (*domain.O
 
 	return &deletedOrder, nil
 }
+
+func (or *orderRepositoryImpl) ApplyRefund(ctx context.Context, id string, refundID string, amount float64) (*domain.Order, error) {
+	collection := or.conn.Collection("orders")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, err
+	}
+
+	// The status is derived from the incremented amount in the same update so
+	// concurrent refunds cannot leave the order in a stale state. The refund
+	// ID is kept on the order so the same refund is never counted twice.
+	filter := bson.M{"_id": objectID, "refund_ids": bson.M{"$ne": refundID}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"refundedamount": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$refundedamount", 0}}, amount}},
+			"refund_ids":     bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$refund_ids", bson.A{}}}, bson.A{refundID}}},
+		}}},
+		{{Key: "$set", Value: bson.M{"status": bson.M{"$cond": bson.A{
+			bson.M{"$gte": bson.A{"$refundedamount", "$totalamount"}},
+			domain.OrderStatusRefunded,
+			domain.OrderStatusPartiallyRefunded,
+		}}}}},
+	}
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, filter, update, otps)
+	if result.Err() == mongo.ErrNoDocuments {
+		result = collection.FindOne(ctx, bson.M{"_id": objectID})
+		if result.Err() == nil {
+			logger.Info("Refund already applied to order", "id", id, "refund_id", refundID)
+		}
+	}
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Order not found", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to apply refund to order", "id", id, "error", result.Err())
+		return nil, result.Err()
+	}
+
+	var updatedOrder domain.Order
+	if err := result.Decode(&updatedOrder); err != nil {
+		logger.Error("Failed to decode updated order", "id", id, "error", err)
+		return nil, err
+	}
+
+	return &updatedOrder, nil
+}
+
+func (or *orderRepositoryImpl) Restore(ctx context.Context, id string) (*domain.Order, error) {
+	collection := or.conn.Collection("orders")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, err
+	}
+
+	filter := bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, filter, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Deleted order not found for restore", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to restore order", "id", id, "error", result.Err())
+		return nil, result.Err()
+	}
+
+	var restoredOrder domain.Order
+	if err := result.Decode(&restoredOrder); err != nil {
+		logger.Error("Failed to decode restored order", "id", id, "error", err)
+		return nil, err
+	}
+
+	return &restoredOrder, nil
+}
+
+func (or *orderRepositoryImpl) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return purgeDeleted(ctx, or.conn.Collection("orders"), deletedBefore)
+}
+
+func (or *orderRepositoryImpl) CountOpenByCustomer(ctx context.Context, customerID string) (int64, error) {
+	collection := or.conn.Collection("orders")
+	// Orders created before statuses existed have no status and count as open.
+	statuses := bson.A{nil}
+	for _, status := range domain.OpenOrderStatuses {
+		statuses = append(statuses, status)
+	}
+	filter := notDeleted(bson.M{"customerid": customerID, "status": bson.M{"$in": statuses}})
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count open orders", "customer_id", customerID, "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// HasPurchased reports whether any order of the customer contains the
+// product, whether it was placed with items or plain product ids.
+func (or *orderRepositoryImpl) HasPurchased(ctx context.Context, customerID string, productID string) (bool, error) {
+	collection := or.conn.Collection("orders")
+	filter := notDeleted(bson.M{
+		"customerid": customerID,
+		"$or": bson.A{
+			bson.M{"productids": productID},
+			bson.M{"items.product_id": productID},
+		},
+	})
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		logger.Error("Failed to look up purchases", "customer_id", customerID, "product_id", productID, "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// EnsureOrderIndexes creates the index that serves a customer's order history
+// newest first.
+func EnsureOrderIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("orders")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "customerid", Value: 1}, {Key: "createdat", Value: -1}},
+		Options: options.Index().SetName("orders_customer_created"),
+	})
+	if err != nil {
+		logger.Error("Failed to create order history index", "error", err)
+	}
+	return err
+}
+
+// GetByCustomer returns one page of a customer's orders, newest first, and
+// the number of orders matching the filters.
+func (or *orderRepositoryImpl) GetByCustomer(ctx context.Context, query *domain.OrderHistoryQuery) ([]*domain.Order, int64, error) {
+	collection := or.conn.Collection("orders")
+	filter := notDeleted(bson.M{"customerid": query.CustomerID})
+	if query.From != nil || query.To != nil {
+		created := bson.M{}
+		if query.From != nil {
+			created["$gte"] = *query.From
+		}
+		if query.To != nil {
+			created["$lte"] = *query.To
+		}
+		filter["createdat"] = created
+	}
+	if len(query.Statuses) > 0 {
+		statuses := bson.A{}
+		for _, status := range query.Statuses {
+			statuses = append(statuses, status)
+			// Orders created before statuses existed count as pending.
+			if status == domain.OrderStatusPending {
+				statuses = append(statuses, nil)
+			}
+		}
+		filter["status"] = bson.M{"$in": statuses}
+	}
+	if query.MinAmount != nil || query.MaxAmount != nil {
+		amount := bson.M{}
+		if query.MinAmount != nil {
+			amount["$gte"] = *query.MinAmount
+		}
+		if query.MaxAmount != nil {
+			amount["$lte"] = *query.MaxAmount
+		}
+		filter["totalamount"] = amount
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count customer orders", "customer_id", query.CustomerID, "error", err)
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdat", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.Limit)).
+		SetLimit(int64(query.Limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find customer orders", "customer_id", query.CustomerID, "error", err)
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []*domain.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// SetAllocations records which warehouses the items of an order ship from.
+func (or *orderRepositoryImpl) SetAllocations(ctx context.Context, id string, allocations []*domain.StockAllocation) error {
+	collection := or.conn.Collection("orders")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"allocations": allocations}})
+	if err != nil {
+		logger.Error("Failed to save order allocations", "id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ClaimReturn moves the returns version of an order on from the one a new
+// return was checked against. ErrConflict means another return was
+// requested in between.
+func (or *orderRepositoryImpl) ClaimReturn(ctx context.Context, id string, returnsVersion int) error {
+	collection := or.conn.Collection("orders")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	// Orders without returns have no version field at all.
+	version := bson.M{"returns_version": returnsVersion}
+	if returnsVersion == 0 {
+		version = bson.M{"$or": bson.A{bson.M{"returns_version": 0}, bson.M{"returns_version": bson.M{"$exists": false}}}}
+	}
+	filter := bson.M{"$and": bson.A{bson.M{"_id": objectID}, version}}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"returns_version": returnsVersion + 1}})
+	if err != nil {
+		logger.Error("Failed to claim order return", "id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: another return was requested for order %s", domain.ErrConflict, id)
+	}
+	return nil
+}
+
+// AnonymizeCustomer moves every order of a customer, deleted ones included,
+// to a pseudonym and drops the delivery location. Amounts and lines are
+// kept for the books.
+func (or *orderRepositoryImpl) AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error) {
+	collection := or.conn.Collection("orders")
+	update := bson.M{"$set": bson.M{"customerid": pseudonym}, "$unset": bson.M{"ship_to": ""}}
+	result, err := collection.UpdateMany(ctx, bson.M{"customerid": customerID}, update)
+	if err != nil {
+		logger.Error("Failed to anonymize orders", "customer_id", customerID, "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// GetAllByCustomer returns every order of a customer, deleted ones included.
+func (or *orderRepositoryImpl) GetAllByCustomer(ctx context.Context, customerID string) ([]*domain.Order, error) {
+	orders, err := or.find(ctx, bson.M{"customerid": customerID})
+	if err != nil {
+		logger.Error("Failed to retrieve customer orders", "customer_id", customerID, "error", err)
+	}
+	return orders, err
+}
@@ -0,0 +1,160 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	_ domain.OutboxRepository = (*outboxRepositoryImpl)(nil)
+	_ domain.EventPublisher   = (*outboxRepositoryImpl)(nil)
+)
+
+type outboxRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewOutboxRepository(db *mongo.Database) domain.OutboxRepository {
+	return &outboxRepositoryImpl{
+		conn: db,
+	}
+}
+
+// NewEventPublisher returns the publisher the usecases emit events with.
+// Publishing writes the events to the outbox, inside the transaction of the
+// context when there is one, and the relay delivers them from there.
+func NewEventPublisher(db *mongo.Database) domain.EventPublisher {
+	return &outboxRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureOutboxIndexes indexes the events still waiting for delivery, oldest
+// first.
+func EnsureOutboxIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("outbox")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "occurred_at", Value: 1}},
+		Options: options.Index().
+			SetName("outbox_pending").
+			SetPartialFilterExpression(bson.M{"published_at": bson.M{"$exists": false}}),
+	})
+	if err != nil {
+		logger.Error("Failed to create outbox index", "error", err)
+	}
+	return err
+}
+
+func (or *outboxRepositoryImpl) Publish(ctx context.Context, events ...*domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	collection := or.conn.Collection("outbox")
+	documents := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		documents = append(documents, event)
+	}
+	if _, err := collection.InsertMany(ctx, documents); err != nil {
+		logger.Error("Failed to write events to the outbox", "type", events[0].Type, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Pending returns undelivered events that are due, oldest first. Events that
+// used up their attempts stay in the outbox but are not returned.
+func (or *outboxRepositoryImpl) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.Event, error) {
+	collection := or.conn.Collection("outbox")
+	filter := bson.M{
+		"published_at":    bson.M{"$exists": false},
+		"next_attempt_at": bson.M{"$lte": now},
+		"attempts":        bson.M{"$lt": domain.MaxEventAttempts},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to read the outbox", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*domain.Event
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (or *outboxRepositoryImpl) MarkPublished(ctx context.Context, id string) error {
+	return or.mark(ctx, id, bson.M{"$set": bson.M{"published_at": time.Now()}, "$unset": bson.M{"last_error": ""}})
+}
+
+func (or *outboxRepositoryImpl) MarkFailed(ctx context.Context, id string, lastError string, nextAttempt time.Time) error {
+	return or.mark(ctx, id, bson.M{
+		"$set": bson.M{"last_error": lastError, "next_attempt_at": nextAttempt},
+		"$inc": bson.M{"attempts": 1},
+	})
+}
+
+func (or *outboxRepositoryImpl) mark(ctx context.Context, id string, update bson.M) error {
+	collection := or.conn.Collection("outbox")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		logger.Error("Failed to update outbox event", "id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Forget removes the events of the given types that match, delivered or
+// not, and returns their ids. Payloads are stored as encoded JSON the server
+// cannot query, so the events are matched one by one as the cursor reads
+// them.
+func (or *outboxRepositoryImpl) Forget(ctx context.Context, eventTypes []string, match func(*domain.Event) bool) ([]string, error) {
+	collection := or.conn.Collection("outbox")
+	filter := bson.M{"type": bson.M{"$in": eventTypes}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1, "type": 1, "aggregate_id": 1, "payload": 1}))
+	if err != nil {
+		logger.Error("Failed to read the outbox", "types", eventTypes, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	var objectIDs bson.A
+	for cursor.Next(ctx) {
+		var event domain.Event
+		if err := cursor.Decode(&event); err != nil {
+			return nil, err
+		}
+		if match(&event) {
+			ids = append(ids, event.Id.Hex())
+			objectIDs = append(objectIDs, event.Id)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}}); err != nil {
+		logger.Error("Failed to delete outbox events", "types", eventTypes, "error", err)
+		return nil, err
+	}
+	return ids, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"intern-project-v2/domain"
 	"intern-project-v2/logger"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -24,8 +25,16 @@ func NewProductRepository(db *mongo.Database) domain.ProductRepository {
 }
 
 func (pr *productRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Product, error) {
+	return pr.find(ctx, notDeleted(bson.M{}))
+}
+
+func (pr *productRepositoryImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Product, error) {
+	return pr.find(ctx, bson.M{})
+}
+
+func (pr *productRepositoryImpl) find(ctx context.Context, filter bson.M) ([]*domain.Product, error) {
 	collection := pr.conn.Collection("products")
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -56,11 +65,11 @@ func (pr *productRepositoryImpl) GetByID(ctx context.Context, id string) (*domai
 		return nil, err
 	}
 
-	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
+	err = collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			logger.Error("Product not found", "id", id)
-			return nil, err
+			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
@@ -73,6 +82,9 @@ func (pr *productRepositoryImpl) Create(ctx context.Context, product *domain.Pro
 	result, err := collection.InsertOne(ctx, product)
 	if err != nil {
 		logger.Error("Failed to create product", "error", err)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: SKU %s is already used", domain.ErrConflict, product.SKU)
+		}
 		return nil, err
 	}
 	productID, ok := result.InsertedID.(bson.ObjectID)
@@ -82,10 +94,12 @@ func (pr *productRepositoryImpl) Create(ctx context.Context, product *domain.Pro
 	}
 
 	createdProduct := &domain.Product{
-		Id:    productID,
-		Name:  product.Name,
-		Price: product.Price,
-		Stock: product.Stock,
+		Id:          productID,
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		CategoryIds: product.CategoryIds,
 	}
 	return createdProduct, nil
 }
@@ -99,6 +113,9 @@ func (pr *productRepositoryImpl) Update(ctx context.Context, id string, productR
 	}
 
 	updateFields := bson.M{}
+	if productReq.SKU != "" {
+		updateFields["sku"] = productReq.SKU
+	}
 	if productReq.Name != "" {
 		updateFields["name"] = productReq.Name
 	}
@@ -108,16 +125,22 @@ func (pr *productRepositoryImpl) Update(ctx context.Context, id string, productR
 	if productReq.Stock >= 0 {
 		updateFields["stock"] = productReq.Stock
 	}
+	if productReq.CategoryIds != nil {
+		updateFields["category_ids"] = productReq.CategoryIds
+	}
 	update := bson.M{"$set": updateFields}
 
 	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	result := collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, otps)
+	result := collection.FindOneAndUpdate(ctx, notDeleted(bson.M{"_id": objectID}), update, otps)
 	if result.Err() != nil {
 
 		if result.Err() == mongo.ErrNoDocuments {
 			logger.Error("Product not found", "id", id)
 			return nil, err
 		}
+		if mongo.IsDuplicateKeyError(result.Err()) {
+			return nil, fmt.Errorf("%w: SKU %s is already used", domain.ErrConflict, productReq.SKU)
+		}
 		return nil, result.Err()
 	}
 
@@ -138,7 +161,8 @@ func (pr *productRepositoryImpl) Delete(ctx context.Context, id string) (*domain
 		return nil, err
 	}
 
-	result := collection.FindOneAndDelete(ctx, bson.M{"_id": objectID})
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, notDeleted(bson.M{"_id": objectID}), softDeleteUpdate(), otps)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
 			logger.Error("Product not found for deletion", "id", id)
@@ -154,3 +178,486 @@ func (pr *productRepositoryImpl) Delete(ctx context.Context, id string) (*domain
 
 	return &deletedProduct, nil
 }
+
+func (pr *productRepositoryImpl) AdjustStock(ctx context.Context, id string, delta int) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, err
+	}
+
+	// Stock taken without a warehouse can only come from the stock no
+	// warehouse holds.
+	filter := notDeleted(bson.M{"_id": objectID})
+	if delta < 0 {
+		filter["$expr"] = unassignedAtLeast("", -delta)
+	}
+	update := bson.M{"$inc": bson.M{"stock": delta}}
+
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, filter, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Product not found or insufficient stock", "id", id, "delta", delta)
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var updatedProduct domain.Product
+	if err := result.Decode(&updatedProduct); err != nil {
+		logger.Error("Failed to decode updated product", "id", id, "error", err)
+		return nil, err
+	}
+
+	return &updatedProduct, nil
+}
+
+func (pr *productRepositoryImpl) Restore(ctx context.Context, id string) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, err
+	}
+
+	filter := bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, filter, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Deleted product not found for restore", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var restoredProduct domain.Product
+	if err := result.Decode(&restoredProduct); err != nil {
+		return nil, err
+	}
+
+	return &restoredProduct, nil
+}
+
+func (pr *productRepositoryImpl) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return purgeDeleted(ctx, pr.conn.Collection("products"), deletedBefore)
+}
+
+// GetByIDs loads all requested products with a single $in query. Unknown,
+// deleted or malformed ids are simply absent from the result.
+func (pr *productRepositoryImpl) GetByIDs(ctx context.Context, ids []string) ([]*domain.Product, error) {
+	objectIDs := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			logger.Warn("Skipping invalid product ID", "id", id)
+			continue
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	if len(objectIDs) == 0 {
+		return []*domain.Product{}, nil
+	}
+	return pr.find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": objectIDs}}))
+}
+
+func (pr *productRepositoryImpl) GetByCategoryIDs(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	return pr.find(ctx, notDeleted(bson.M{"category_ids": bson.M{"$in": categoryIDs}}))
+}
+
+func (pr *productRepositoryImpl) RemoveCategory(ctx context.Context, categoryID string) (int64, error) {
+	collection := pr.conn.Collection("products")
+	result, err := collection.UpdateMany(ctx,
+		bson.M{"category_ids": categoryID},
+		bson.M{"$pull": bson.M{"category_ids": categoryID}},
+	)
+	if err != nil {
+		logger.Error("Failed to remove category from products", "category_id", categoryID, "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// SetVariants replaces the options and variants of a product. When there are
+// variants the product stock becomes the sum of their stock. The update only
+// applies while the product and its variants still hold the stock they had in
+// current, so a concurrent stock movement is never overwritten.
+func (pr *productRepositoryImpl) SetVariants(ctx context.Context, id string, current *domain.Product, productOptions []*domain.ProductOption, variants []*domain.ProductVariant) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	filter := bson.M{"_id": objectID, "stock": current.Stock}
+	if len(current.Variants) > 0 {
+		unchanged := make(bson.A, 0, len(current.Variants))
+		for _, variant := range current.Variants {
+			unchanged = append(unchanged, bson.M{"variants": bson.M{"$elemMatch": bson.M{"id": variant.Id, "stock": variant.Stock}}})
+		}
+		filter["$and"] = unchanged
+	}
+
+	fields := bson.M{"options": productOptions, "variants": variants}
+	update := bson.M{"$set": fields}
+	if len(variants) > 0 {
+		stock := 0
+		for _, variant := range variants {
+			stock += variant.Stock
+		}
+		fields["stock"] = stock
+		// Stock is held per variant from now on.
+		update["$unset"] = bson.M{"warehouse_stock": ""}
+	}
+	product, err := pr.findOneAndUpdate(ctx, collection, notDeleted(filter), update)
+	if err == domain.ErrNotFound {
+		count, countErr := collection.CountDocuments(ctx, notDeleted(bson.M{"_id": objectID}))
+		if countErr == nil && count > 0 {
+			logger.Error("Stock changed while setting product options", "id", id)
+			return nil, fmt.Errorf("%w: stock of product %s changed, try again", domain.ErrConflict, id)
+		}
+	}
+	return product, err
+}
+
+// UpdateVariant overwrites one variant in place and recomputes the product
+// stock in the same update.
+func (pr *productRepositoryImpl) UpdateVariant(ctx context.Context, id string, variant *domain.ProductVariant) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"variants": bson.M{"$map": bson.M{
+			"input": "$variants",
+			"in": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$$this.id", variant.Id}},
+				variant,
+				"$$this",
+			}},
+		}}}}},
+		{{Key: "$set", Value: bson.M{"stock": bson.M{"$sum": "$variants.stock"}}}},
+	}
+	filter := notDeleted(bson.M{"_id": objectID, "variants.id": variant.Id})
+	return pr.findOneAndUpdate(ctx, collection, filter, update)
+}
+
+// AdjustVariantStock changes the stock of one variant, and of the product, by
+// delta. Decrements only take stock of the variant that no warehouse holds.
+func (pr *productRepositoryImpl) AdjustVariantStock(ctx context.Context, id string, variantID string, delta int) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	filter := notDeleted(bson.M{"_id": objectID, "variants.id": variantID})
+	if delta < 0 {
+		filter["$expr"] = unassignedAtLeast(variantID, -delta)
+	}
+	update := bson.M{"$inc": bson.M{"variants.$.stock": delta, "stock": delta}}
+	product, err := pr.findOneAndUpdate(ctx, collection, filter, update)
+	if err == domain.ErrNotFound {
+		logger.Error("Variant not found or insufficient stock", "id", id, "variant_id", variantID, "delta", delta)
+	}
+	return product, err
+}
+
+func (pr *productRepositoryImpl) findOneAndUpdate(ctx context.Context, collection *mongo.Collection, filter bson.M, update interface{}) (*domain.Product, error) {
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, filter, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var updatedProduct domain.Product
+	if err := result.Decode(&updatedProduct); err != nil {
+		logger.Error("Failed to decode updated product", "error", err)
+		return nil, err
+	}
+	return &updatedProduct, nil
+}
+
+func (pr *productRepositoryImpl) AddImage(ctx context.Context, id string, image *domain.ProductImage) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	update := bson.M{"$push": bson.M{"images": image}}
+	return pr.findOneAndUpdate(ctx, collection, notDeleted(bson.M{"_id": objectID}), update)
+}
+
+func (pr *productRepositoryImpl) RemoveImage(ctx context.Context, id string, imageID string) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	filter := notDeleted(bson.M{"_id": objectID, "images.id": imageID})
+	update := bson.M{"$pull": bson.M{"images": bson.M{"id": imageID}}}
+	return pr.findOneAndUpdate(ctx, collection, filter, update)
+}
+
+// SetImages stores the images of a product in a new order. The update only
+// applies while the product still holds exactly the same images, so a
+// concurrent upload or removal is not lost.
+func (pr *productRepositoryImpl) SetImages(ctx context.Context, id string, images []*domain.ProductImage) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	imageIDs := make(bson.A, 0, len(images))
+	for _, image := range images {
+		imageIDs = append(imageIDs, image.Id)
+	}
+	filter := notDeleted(bson.M{
+		"_id":       objectID,
+		"images":    bson.M{"$size": len(images)},
+		"images.id": bson.M{"$all": imageIDs},
+	})
+	update := bson.M{"$set": bson.M{"images": images}}
+	product, err := pr.findOneAndUpdate(ctx, collection, filter, update)
+	if err == domain.ErrNotFound {
+		return nil, fmt.Errorf("%w: product images changed while reordering", domain.ErrConflict)
+	}
+	return product, err
+}
+
+// EnsureProductIndexes creates the unique index that import upserts match
+// SKUs against. Products without a SKU are left out of it.
+func EnsureProductIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("products")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "sku", Value: 1}},
+		Options: options.Index().
+			SetName("products_sku").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sku": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		logger.Error("Failed to create product SKU index", "error", err)
+	}
+	return err
+}
+
+// GetBySKUs returns the products with the given SKUs, including soft-deleted
+// ones, which importing their SKU again restores.
+func (pr *productRepositoryImpl) GetBySKUs(ctx context.Context, skus []string) ([]*domain.Product, error) {
+	if len(skus) == 0 {
+		return []*domain.Product{}, nil
+	}
+	return pr.find(ctx, bson.M{"sku": bson.M{"$in": skus}})
+}
+
+// UpsertBySKU writes all products in one unordered bulk write, matching
+// existing products by SKU. A soft-deleted product whose SKU is imported again
+// is restored. Stock is not written; new products start without stock and
+// the stock is set through the inventory ledger.
+func (pr *productRepositoryImpl) UpsertBySKU(ctx context.Context, products []*domain.ProductRequest) (int64, int64, error) {
+	if len(products) == 0 {
+		return 0, 0, nil
+	}
+	collection := pr.conn.Collection("products")
+	models := make([]mongo.WriteModel, 0, len(products))
+	for _, product := range products {
+		fields := bson.M{
+			"name":  product.Name,
+			"price": product.Price,
+		}
+		if product.CategoryIds != nil {
+			fields["category_ids"] = product.CategoryIds
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"sku": product.SKU}).
+			SetUpdate(bson.M{"$set": fields, "$setOnInsert": bson.M{"stock": 0}, "$unset": bson.M{"deleted_at": ""}}).
+			SetUpsert(true))
+	}
+
+	result, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		logger.Error("Failed to import products", "rows", len(products), "error", err)
+		return 0, 0, err
+	}
+	logger.Info("Imported products", "created", result.UpsertedCount, "updated", result.MatchedCount)
+	return result.UpsertedCount, result.MatchedCount, nil
+}
+
+// Each calls fn for every product that is not deleted, in SKU order, without
+// loading the whole catalog into memory.
+func (pr *productRepositoryImpl) Each(ctx context.Context, fn func(*domain.Product) error) error {
+	collection := pr.conn.Collection("products")
+	opts := options.Find().SetSort(bson.D{{Key: "sku", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, notDeleted(bson.M{}), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var product domain.Product
+		if err := cursor.Decode(&product); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// AdjustRating moves a product's rating by one review being added, changed or
+// removed. The sum, count and average are updated in one pipeline so readers
+// never see them disagree.
+func (pr *productRepositoryImpl) AdjustRating(ctx context.Context, id string, sumDelta int, countDelta int) error {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"rating_sum":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_sum", 0}}, sumDelta}},
+			"rating_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_count", 0}}, countDelta}},
+		}}},
+		{{Key: "$set", Value: bson.M{"rating_average": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$rating_count", 0}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$rating_sum", "$rating_count"}}, 2}},
+			0,
+		}}}}},
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		logger.Error("Failed to adjust product rating", "id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// SetReorderThreshold sets the low stock threshold of a product; nil removes
+// it so the default applies.
+func (pr *productRepositoryImpl) SetReorderThreshold(ctx context.Context, id string, threshold *int) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	update := bson.M{"$unset": bson.M{"reorder_threshold": ""}}
+	if threshold != nil {
+		update = bson.M{"$set": bson.M{"reorder_threshold": *threshold}}
+	}
+	return pr.findOneAndUpdate(ctx, collection, notDeleted(bson.M{"_id": objectID}), update)
+}
+
+// AdjustWarehouseStock changes the stock a warehouse holds of a product, or
+// of one of its variants, together with the total stock. Like AdjustStock it
+// fails with ErrNotFound rather than take the warehouse below zero.
+func (pr *productRepositoryImpl) AdjustWarehouseStock(ctx context.Context, id string, variantID string, warehouseID string, delta int) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	level := "warehouse_stock." + warehouseID
+	var filter, update bson.M
+	if variantID != "" {
+		match := bson.M{"id": variantID}
+		if delta < 0 {
+			match[level] = bson.M{"$gte": -delta}
+		}
+		filter = bson.M{"_id": objectID, "variants": bson.M{"$elemMatch": match}}
+		update = bson.M{"$inc": bson.M{"variants.$." + level: delta, "variants.$.stock": delta, "stock": delta}}
+	} else {
+		filter = bson.M{"_id": objectID}
+		if delta < 0 {
+			filter[level] = bson.M{"$gte": -delta}
+		}
+		update = bson.M{"$inc": bson.M{level: delta, "stock": delta}}
+	}
+	product, err := pr.findOneAndUpdate(ctx, collection, notDeleted(filter), update)
+	if err == domain.ErrNotFound {
+		logger.Error("Product not found or insufficient warehouse stock", "id", id, "variant_id", variantID, "warehouse_id", warehouseID, "delta", delta)
+	}
+	return product, err
+}
+
+// TransferStock moves quantity units of a product, or of one of its
+// variants, from one warehouse to another. The total stock is unchanged. An
+// empty from takes stock that no warehouse holds yet, which is whatever the
+// total exceeds the sum of the warehouse levels by.
+func (pr *productRepositoryImpl) TransferStock(ctx context.Context, id string, variantID string, from string, to string, quantity int) (*domain.Product, error) {
+	collection := pr.conn.Collection("products")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	prefix := ""
+	filter := bson.M{"_id": objectID}
+	match := bson.M{}
+	if variantID != "" {
+		prefix = "variants.$."
+		match["id"] = variantID
+	}
+	inc := bson.M{prefix + "warehouse_stock." + to: quantity}
+	if from != "" {
+		match["warehouse_stock."+from] = bson.M{"$gte": quantity}
+		inc[prefix+"warehouse_stock."+from] = -quantity
+	} else {
+		filter["$expr"] = unassignedAtLeast(variantID, quantity)
+	}
+	if variantID != "" {
+		filter["variants"] = bson.M{"$elemMatch": match}
+	} else {
+		for key, value := range match {
+			filter[key] = value
+		}
+	}
+
+	product, err := pr.findOneAndUpdate(ctx, collection, notDeleted(filter), bson.M{"$inc": inc})
+	if err == domain.ErrNotFound {
+		logger.Error("Product not found or insufficient stock to transfer", "id", id, "variant_id", variantID, "from", from, "quantity", quantity)
+	}
+	return product, err
+}
+
+// unassignedAtLeast builds an $expr that holds when the stock no warehouse
+// holds of a product, or of one of its variants, is at least quantity.
+func unassignedAtLeast(variantID string, quantity int) bson.M {
+	unassigned := func(prefix string) bson.M {
+		levels := bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{prefix + "warehouse_stock", bson.M{}}}}
+		assigned := bson.M{"$sum": bson.M{"$map": bson.M{"input": levels, "in": "$$level.v", "as": "level"}}}
+		return bson.M{"$gte": bson.A{bson.M{"$subtract": bson.A{prefix + "stock", assigned}}, quantity}}
+	}
+	if variantID == "" {
+		return unassigned("$")
+	}
+	return bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": "$variants",
+		"as":    "variant",
+		"in":    bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$variant.id", variantID}}, unassigned("$$variant.")}},
+	}}}}
+}
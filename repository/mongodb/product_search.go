@@ -0,0 +1,179 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"math"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.ProductSearcher = (*productSearcherImpl)(nil)
+
+type productSearcherImpl struct {
+	conn *mongo.Database
+}
+
+// NewProductSearcher returns a searcher backed by a MongoDB text index,
+// creating the index if needed.
+func NewProductSearcher(ctx context.Context, db *mongo.Database) (domain.ProductSearcher, error) {
+	collection := db.Collection("products")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: "text"}},
+		Options: options.Index().SetName("products_text"),
+	})
+	if err != nil {
+		logger.Error("Failed to create product text index", "error", err)
+		return nil, err
+	}
+	return &productSearcherImpl{
+		conn: db,
+	}, nil
+}
+
+type searchHitDocument struct {
+	Product domain.Product `bson:",inline"`
+	Score   float64        `bson:"score"`
+}
+
+type searchFacetDocument struct {
+	Results []*searchHitDocument `bson:"results"`
+	Total   []struct {
+		Count int64 `bson:"count"`
+	} `bson:"total"`
+	Price []struct {
+		Min   interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	} `bson:"price"`
+	Availability []struct {
+		InStock bool  `bson:"_id"`
+		Count   int64 `bson:"count"`
+	} `bson:"availability"`
+}
+
+func (ps *productSearcherImpl) Search(ctx context.Context, query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	collection := ps.conn.Collection("products")
+
+	match := notDeleted(bson.M{})
+	var score interface{} = 0
+	if query.Query != "" {
+		match["$text"] = bson.M{"$search": query.Query}
+		score = bson.M{"$meta": "textScore"}
+	}
+
+	filters := bson.M{}
+	price := bson.M{}
+	if query.MinPrice != nil {
+		price["$gte"] = *query.MinPrice
+	}
+	if query.MaxPrice != nil {
+		price["$lte"] = *query.MaxPrice
+	}
+	if len(price) > 0 {
+		filters["price"] = price
+	}
+	if query.InStock {
+		filters["stock"] = bson.M{"$gt": 0}
+	}
+
+	boundaries := bson.A{}
+	for _, boundary := range domain.PriceFacetBoundaries {
+		boundaries = append(boundaries, boundary)
+	}
+	boundaries = append(boundaries, math.MaxFloat64)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": score}}},
+		{{Key: "$facet", Value: bson.M{
+			"results": bson.A{
+				bson.M{"$match": filters},
+				bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "name", Value: 1}}},
+				bson.M{"$skip": int64((query.Page - 1) * query.Limit)},
+				bson.M{"$limit": int64(query.Limit)},
+			},
+			"total": bson.A{
+				bson.M{"$match": filters},
+				bson.M{"$count": "count"},
+			},
+			"price": bson.A{
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price",
+					"boundaries": boundaries,
+					"default":    "other",
+					"output":     bson.M{"count": bson.M{"$sum": 1}},
+				}},
+			},
+			"availability": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$gt": bson.A{"$stock", 0}},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Failed to search products", "query", query.Query, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*searchFacetDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProductSearchResult{
+		Products: []*domain.ProductSearchHit{},
+		Page:     query.Page,
+		Limit:    query.Limit,
+		Facets:   domain.SearchFacets{Price: domain.NewPriceFacets()},
+	}
+	if len(documents) == 0 {
+		return result, nil
+	}
+	document := documents[0]
+
+	for _, hit := range document.Results {
+		product := hit.Product
+		result.Products = append(result.Products, &domain.ProductSearchHit{Product: &product, Score: hit.Score})
+	}
+	if len(document.Total) > 0 {
+		result.Total = document.Total[0].Count
+	}
+	for _, bucket := range document.Price {
+		for _, facet := range result.Facets.Price {
+			if min, ok := toFloat(bucket.Min); ok && min == facet.Min {
+				facet.Count = bucket.Count
+			}
+		}
+	}
+	for _, group := range document.Availability {
+		if group.InStock {
+			result.Facets.Availability.InStock = group.Count
+		} else {
+			result.Facets.Availability.OutOfStock = group.Count
+		}
+	}
+	return result, nil
+}
+
+// toFloat converts a numeric $bucket boundary, which keeps the BSON type of
+// the boundary it was given, to a float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,224 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var _ domain.ReportRepository = (*reportRepositoryImpl)(nil)
+
+type reportRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewReportRepository(db *mongo.Database) domain.ReportRepository {
+	return &reportRepositoryImpl{
+		conn: db,
+	}
+}
+
+// ordersCreatedIn matches the orders a report covers. Order fields are stored
+// under their lowercased Go names.
+func ordersCreatedIn(query *domain.ReportQuery) bson.D {
+	return bson.D{{Key: "$match", Value: notDeleted(bson.M{
+		"createdat": bson.M{"$gte": query.From, "$lte": query.To},
+	})}}
+}
+
+func (rr *reportRepositoryImpl) Revenue(ctx context.Context, query *domain.ReportQuery) ([]*domain.RevenuePoint, error) {
+	trunc := bson.M{"date": "$createdat", "unit": query.Interval}
+	if query.Interval == domain.ReportIntervalWeek {
+		trunc["startOfWeek"] = "monday"
+	}
+	pipeline := mongo.Pipeline{
+		ordersCreatedIn(query),
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$dateTrunc": trunc},
+			"orders":   bson.M{"$sum": 1},
+			"gross":    bson.M{"$sum": "$totalamount"},
+			"refunded": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$refundedamount", 0}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"net":                 bson.M{"$subtract": bson.A{"$gross", "$refunded"}},
+			"average_order_value": bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$gross", "$orders"}}, 2}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	points := []*domain.RevenuePoint{}
+	if err := rr.aggregate(ctx, "orders", pipeline, &points); err != nil {
+		logger.Error("Failed to build revenue report", "interval", query.Interval, "error", err)
+		return nil, err
+	}
+	return points, nil
+}
+
+// TopProducts ranks products by units sold or revenue. Orders placed before
+// order items existed only list product ids, one unit each, so their total
+// is split evenly across those units.
+func (rr *reportRepositoryImpl) TopProducts(ctx context.Context, query *domain.ReportQuery) ([]*domain.TopProduct, error) {
+	legacyIDs := bson.M{"$ifNull": bson.A{"$productids", bson.A{}}}
+	sortBy := "units"
+	if query.SortBy == domain.TopProductsByRevenue {
+		sortBy = "revenue"
+	}
+	pipeline := mongo.Pipeline{
+		ordersCreatedIn(query),
+		{{Key: "$project", Value: bson.M{"lines": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$items", bson.A{}}}}, 0}},
+			bson.M{"$map": bson.M{"input": "$items", "in": bson.M{
+				"product_id": "$$this.product_id",
+				"quantity":   "$$this.quantity",
+				"revenue":    bson.M{"$multiply": bson.A{"$$this.quantity", "$$this.unit_price"}},
+			}}},
+			bson.M{"$map": bson.M{"input": legacyIDs, "in": bson.M{
+				"product_id": "$$this",
+				"quantity":   1,
+				"revenue":    bson.M{"$divide": bson.A{"$totalamount", bson.M{"$max": bson.A{bson.M{"$size": legacyIDs}, 1}}}},
+			}}},
+		}}}}},
+		{{Key: "$unwind", Value: "$lines"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$lines.product_id",
+			"units":   bson.M{"$sum": "$lines.quantity"},
+			"revenue": bson.M{"$sum": "$lines.revenue"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: sortBy, Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: query.Limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "products",
+			"let":  bson.M{"pid": bson.M{"$convert": bson.M{"input": "$_id", "to": "objectId", "onError": nil, "onNull": nil}}},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$pid"}}}}},
+				{{Key: "$project", Value: bson.M{"name": 1}}},
+			},
+			"as": "product",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"name":    bson.M{"$ifNull": bson.A{bson.M{"$first": "$product.name"}, ""}},
+			"revenue": bson.M{"$round": bson.A{"$revenue", 2}},
+		}}},
+		{{Key: "$project", Value: bson.M{"product": 0}}},
+	}
+	products := []*domain.TopProduct{}
+	if err := rr.aggregate(ctx, "orders", pipeline, &products); err != nil {
+		logger.Error("Failed to build top products report", "error", err)
+		return nil, err
+	}
+	return products, nil
+}
+
+func (rr *reportRepositoryImpl) OrderValue(ctx context.Context, query *domain.ReportQuery) (*domain.OrderValueSummary, error) {
+	pipeline := mongo.Pipeline{
+		ordersCreatedIn(query),
+		{{Key: "$group", Value: bson.M{
+			"_id":             nil,
+			"orders":          bson.M{"$sum": 1},
+			"gross":           bson.M{"$sum": "$totalamount"},
+			"min_order_value": bson.M{"$min": "$totalamount"},
+			"max_order_value": bson.M{"$max": "$totalamount"},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"average_order_value": bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$gross", "$orders"}}, 2}},
+		}}},
+	}
+	var summaries []*domain.OrderValueSummary
+	if err := rr.aggregate(ctx, "orders", pipeline, &summaries); err != nil {
+		logger.Error("Failed to build order value report", "error", err)
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return &domain.OrderValueSummary{}, nil
+	}
+	return summaries[0], nil
+}
+
+// CustomerMix looks at every order up to the end of the range to find when
+// each customer first ordered, then counts the customers active in the range.
+func (rr *reportRepositoryImpl) CustomerMix(ctx context.Context, query *domain.ReportQuery) (*domain.CustomerMix, error) {
+	inRange := bson.M{"$gte": bson.A{"$createdat", query.From}}
+	isNew := bson.M{"$gte": bson.A{"$first_order", query.From}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{"createdat": bson.M{"$lte": query.To}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$customerid",
+			"first_order": bson.M{"$min": "$createdat"},
+			"orders":      bson.M{"$sum": bson.M{"$cond": bson.A{inRange, 1, 0}}},
+			"revenue":     bson.M{"$sum": bson.M{"$cond": bson.A{inRange, "$totalamount", 0}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"orders": bson.M{"$gt": 0}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":                 nil,
+			"new_customers":       bson.M{"$sum": bson.M{"$cond": bson.A{isNew, 1, 0}}},
+			"returning_customers": bson.M{"$sum": bson.M{"$cond": bson.A{isNew, 0, 1}}},
+			"new_revenue":         bson.M{"$sum": bson.M{"$cond": bson.A{isNew, "$revenue", 0}}},
+			"returning_revenue":   bson.M{"$sum": bson.M{"$cond": bson.A{isNew, 0, "$revenue"}}},
+		}}},
+	}
+	var mixes []*domain.CustomerMix
+	if err := rr.aggregate(ctx, "orders", pipeline, &mixes); err != nil {
+		logger.Error("Failed to build customer mix report", "error", err)
+		return nil, err
+	}
+	if len(mixes) == 0 {
+		return &domain.CustomerMix{}, nil
+	}
+	return mixes[0], nil
+}
+
+// LowStock lists products at or below the threshold, lowest stock first. A
+// negative threshold uses the reorder threshold of each product. Products
+// sold by variant are reported per variant.
+func (rr *reportRepositoryImpl) LowStock(ctx context.Context, threshold int, limit int) ([]*domain.LowStockItem, error) {
+	var productThreshold interface{} = bson.M{"$literal": threshold}
+	if threshold < 0 {
+		productThreshold = bson.M{"$ifNull": bson.A{"$reorder_threshold", domain.DefaultReorderThreshold}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{})}},
+		{{Key: "$project", Value: bson.M{
+			"name":      1,
+			"threshold": productThreshold,
+			"lines": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$variants", bson.A{}}}}, 0}},
+				bson.M{"$map": bson.M{"input": "$variants", "in": bson.M{
+					"variant_id": "$$this.id",
+					"sku":        "$$this.sku",
+					"stock":      "$$this.stock",
+				}}},
+				bson.A{bson.M{"sku": "$sku", "stock": "$stock"}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$lines"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$lte": bson.A{"$lines.stock", "$threshold"}}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"product_id": bson.M{"$toString": "$_id"},
+			"name":       1,
+			"variant_id": "$lines.variant_id",
+			"sku":        "$lines.sku",
+			"stock":      "$lines.stock",
+			"threshold":  1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "stock", Value: 1}, {Key: "name", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	items := []*domain.LowStockItem{}
+	if err := rr.aggregate(ctx, "products", pipeline, &items); err != nil {
+		logger.Error("Failed to build low stock report", "threshold", threshold, "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (rr *reportRepositoryImpl) aggregate(ctx context.Context, collection string, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := rr.conn.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.ReturnRepository = (*returnRepositoryImpl)(nil)
+
+type returnRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewReturnRepository(db *mongo.Database) domain.ReturnRepository {
+	return &returnRepositoryImpl{
+		conn: db,
+	}
+}
+
+func (rr *returnRepositoryImpl) Create(ctx context.Context, ret *domain.Return) (*domain.Return, error) {
+	collection := rr.conn.Collection("returns")
+	result, err := collection.InsertOne(ctx, ret)
+	if err != nil {
+		logger.Error("Failed to create return", "order_id", ret.OrderID, "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	ret.Id = insertedID
+	return ret, nil
+}
+
+func (rr *returnRepositoryImpl) Delete(ctx context.Context, id string) error {
+	collection := rr.conn.Collection("returns")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		logger.Error("Failed to delete return", "id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (rr *returnRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Return, error) {
+	collection := rr.conn.Collection("returns")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var ret domain.Return
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&ret)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Return not found", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (rr *returnRepositoryImpl) GetByOrderID(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	return rr.find(ctx, bson.M{"order_id": orderID})
+}
+
+func (rr *returnRepositoryImpl) GetAll(ctx context.Context, status string) ([]*domain.Return, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	return rr.find(ctx, filter)
+}
+
+func (rr *returnRepositoryImpl) Replace(ctx context.Context, ret *domain.Return, expectedStatus string) error {
+	collection := rr.conn.Collection("returns")
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": ret.Id, "status": expectedStatus}, ret)
+	if err != nil {
+		logger.Error("Failed to update return", "id", ret.Id.Hex(), "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		logger.Warn("Return was modified concurrently", "id", ret.Id.Hex(), "expected_status", expectedStatus)
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (rr *returnRepositoryImpl) GetByCustomer(ctx context.Context, customerID string) ([]*domain.Return, error) {
+	return rr.find(ctx, bson.M{"customer_id": customerID})
+}
+
+// AnonymizeCustomer moves a customer's returns to a pseudonym, also in the
+// history entries the customer recorded under their email address. Amounts
+// and refunds are kept.
+func (rr *returnRepositoryImpl) AnonymizeCustomer(ctx context.Context, customerID string, email string, pseudonym string) (int64, error) {
+	collection := rr.conn.Collection("returns")
+	update := bson.M{"$set": bson.M{"customer_id": pseudonym, "history.$[entry].actor": pseudonym}}
+	opts := options.UpdateMany().SetArrayFilters([]interface{}{bson.M{"entry.actor": email}})
+	result, err := collection.UpdateMany(ctx, bson.M{"customer_id": customerID}, update, opts)
+	if err != nil {
+		logger.Error("Failed to anonymize returns", "customer_id", customerID, "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (rr *returnRepositoryImpl) find(ctx context.Context, filter bson.M) ([]*domain.Return, error) {
+	collection := rr.conn.Collection("returns")
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var returns []*domain.Return
+	for cursor.Next(ctx) {
+		var ret domain.Return
+		if err := cursor.Decode(&ret); err != nil {
+			return nil, err
+		}
+		returns = append(returns, &ret)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
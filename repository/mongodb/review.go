@@ -0,0 +1,178 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.ReviewRepository = (*reviewRepositoryImpl)(nil)
+
+type reviewRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewReviewRepository(db *mongo.Database) domain.ReviewRepository {
+	return &reviewRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureReviewIndexes allows one review per customer and product and indexes
+// the product listing.
+func EnsureReviewIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("reviews")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "customer_id", Value: 1}},
+			Options: options.Index().SetName("reviews_product_customer").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("reviews_product_status"),
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to create review indexes", "error", err)
+	}
+	return err
+}
+
+func (rr *reviewRepositoryImpl) Create(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	collection := rr.conn.Collection("reviews")
+	review.Version = 1
+	result, err := collection.InsertOne(ctx, review)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: the customer has already reviewed this product", domain.ErrConflict)
+	}
+	if err != nil {
+		logger.Error("Failed to create review", "product_id", review.ProductID, "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	review.Id = insertedID
+	return review, nil
+}
+
+func (rr *reviewRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Review, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	return rr.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (rr *reviewRepositoryImpl) GetByCustomerAndProduct(ctx context.Context, customerID string, productID string) (*domain.Review, error) {
+	return rr.findOne(ctx, bson.M{"customer_id": customerID, "product_id": productID})
+}
+
+func (rr *reviewRepositoryImpl) GetByProduct(ctx context.Context, productID string, status string) ([]*domain.Review, error) {
+	filter := bson.M{"product_id": productID}
+	if status != "" {
+		filter["status"] = status
+	}
+	return rr.find(ctx, filter)
+}
+
+func (rr *reviewRepositoryImpl) GetAll(ctx context.Context, status string) ([]*domain.Review, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	return rr.find(ctx, filter)
+}
+
+// Replace overwrites a review only if nobody changed it since it was read,
+// otherwise ErrConflict is returned. This keeps the product rating, which is
+// adjusted by the difference between the two versions, correct.
+func (rr *reviewRepositoryImpl) Replace(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	collection := rr.conn.Collection("reviews")
+	filter := bson.M{"_id": review.Id, "version": review.Version}
+	review.Version++
+	result, err := collection.ReplaceOne(ctx, filter, review)
+	if err != nil {
+		logger.Error("Failed to update review", "id", review.Id.Hex(), "error", err)
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		logger.Warn("Review was modified concurrently", "id", review.Id.Hex())
+		return nil, fmt.Errorf("%w: review was changed by another request", domain.ErrConflict)
+	}
+	return review, nil
+}
+
+func (rr *reviewRepositoryImpl) Delete(ctx context.Context, id string) (*domain.Review, error) {
+	collection := rr.conn.Collection("reviews")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+	var review domain.Review
+	err = collection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to delete review", "id", id, "error", err)
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (rr *reviewRepositoryImpl) GetByCustomer(ctx context.Context, customerID string) ([]*domain.Review, error) {
+	return rr.find(ctx, bson.M{"customer_id": customerID})
+}
+
+// AnonymizeCustomer moves a customer's reviews to a pseudonym. The reviews
+// and their ratings stay; the version is bumped so edits in flight conflict.
+func (rr *reviewRepositoryImpl) AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error) {
+	collection := rr.conn.Collection("reviews")
+	update := bson.M{"$set": bson.M{"customer_id": pseudonym}, "$inc": bson.M{"version": 1}}
+	result, err := collection.UpdateMany(ctx, bson.M{"customer_id": customerID}, update)
+	if err != nil {
+		logger.Error("Failed to anonymize reviews", "customer_id", customerID, "error", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (rr *reviewRepositoryImpl) findOne(ctx context.Context, filter bson.M) (*domain.Review, error) {
+	collection := rr.conn.Collection("reviews")
+	var review domain.Review
+	err := collection.FindOne(ctx, filter).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to find review", "error", err)
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (rr *reviewRepositoryImpl) find(ctx context.Context, filter bson.M) ([]*domain.Review, error) {
+	collection := rr.conn.Collection("reviews")
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []*domain.Review{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
@@ -0,0 +1,32 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// notDeleted narrows a filter to documents that have not been soft-deleted.
+// Matching on nil also covers documents written before deleted_at existed.
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// softDeleteUpdate marks a document as deleted at the current time.
+func softDeleteUpdate() bson.M {
+	return bson.M{"$set": bson.M{"deleted_at": time.Now()}}
+}
+
+// purgeDeleted permanently removes documents soft-deleted before the cutoff.
+func purgeDeleted(ctx context.Context, collection *mongo.Collection, deletedBefore time.Time) (int64, error) {
+	result, err := collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": deletedBefore}})
+	if err != nil {
+		logger.Error("Failed to purge soft-deleted documents", "collection", collection.Name(), "error", err)
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
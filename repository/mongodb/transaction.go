@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var _ domain.Transactor = (*transactorImpl)(nil)
+
+type transactorImpl struct {
+	client    *mongo.Client
+	supported bool
+}
+
+// NewTransactor returns a Transactor backed by MongoDB sessions. Only
+// replica sets and sharded clusters support transactions; against a
+// standalone server, as used in development, fn runs without one and its
+// writes are not atomic.
+func NewTransactor(ctx context.Context, client *mongo.Client) domain.Transactor {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	supported := err == nil && (hello.SetName != "" || hello.Msg == "isdbgrid")
+	if !supported {
+		logger.Warn("MongoDB does not support transactions, events are written separately from state changes", "error", err)
+	}
+	return &transactorImpl{
+		client:    client,
+		supported: supported,
+	}
+}
+
+func (t *transactorImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !t.supported || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	session, err := t.client.StartSession()
+	if err != nil {
+		logger.Error("Failed to start a session", "error", err)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
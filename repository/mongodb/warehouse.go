@@ -0,0 +1,121 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.WarehouseRepository = (*warehouseRepositoryImpl)(nil)
+
+type warehouseRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewWarehouseRepository(db *mongo.Database) domain.WarehouseRepository {
+	return &warehouseRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureWarehouseIndexes keeps warehouse codes unique.
+func EnsureWarehouseIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("warehouses")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("warehouses_code_unique"),
+	})
+	if err != nil {
+		logger.Error("Failed to create warehouse index", "error", err)
+	}
+	return err
+}
+
+func (wr *warehouseRepositoryImpl) GetAll(ctx context.Context) ([]*domain.Warehouse, error) {
+	collection := wr.conn.Collection("warehouses")
+	opts := options.Find().SetSort(bson.D{{Key: "code", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var warehouses []*domain.Warehouse
+	if err := cursor.All(ctx, &warehouses); err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (wr *warehouseRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Warehouse, error) {
+	collection := wr.conn.Collection("warehouses")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var warehouse domain.Warehouse
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&warehouse)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Warehouse not found", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (wr *warehouseRepositoryImpl) Create(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	collection := wr.conn.Collection("warehouses")
+	result, err := collection.InsertOne(ctx, warehouse)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: warehouse code %s is already in use", domain.ErrConflict, warehouse.Code)
+	}
+	if err != nil {
+		logger.Error("Failed to create warehouse", "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	warehouse.Id = insertedID
+	return warehouse, nil
+}
+
+func (wr *warehouseRepositoryImpl) Update(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	collection := wr.conn.Collection("warehouses")
+	update := bson.M{"$set": bson.M{"code": warehouse.Code, "name": warehouse.Name, "active": warehouse.Active}}
+	if warehouse.Location != nil {
+		update["$set"].(bson.M)["location"] = warehouse.Location
+	} else {
+		update["$unset"] = bson.M{"location": ""}
+	}
+
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, bson.M{"_id": warehouse.Id}, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Warehouse not found", "id", warehouse.Id.Hex())
+			return nil, domain.ErrNotFound
+		}
+		if mongo.IsDuplicateKeyError(result.Err()) {
+			return nil, fmt.Errorf("%w: warehouse code %s is already in use", domain.ErrConflict, warehouse.Code)
+		}
+		return nil, result.Err()
+	}
+
+	var updatedWarehouse domain.Warehouse
+	if err := result.Decode(&updatedWarehouse); err != nil {
+		return nil, err
+	}
+	return &updatedWarehouse, nil
+}
@@ -0,0 +1,277 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.WebhookRepository = (*webhookRepositoryImpl)(nil)
+
+type webhookRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewWebhookRepository(db *mongo.Database) domain.WebhookRepository {
+	return &webhookRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureWebhookIndexes keeps one delivery per subscription and event, so
+// events relayed twice are only sent once, and indexes the delivery queue and
+// the delivery log.
+func EnsureWebhookIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("webhook_deliveries")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("webhook_deliveries_subscription_event"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetName("webhook_deliveries_due"),
+		},
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("webhook_deliveries_log"),
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to create webhook delivery indexes", "error", err)
+	}
+	return err
+}
+
+func (wr *webhookRepositoryImpl) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return wr.findSubscriptions(ctx, bson.M{})
+}
+
+// GetSubscribed returns the active subscriptions to an event type.
+func (wr *webhookRepositoryImpl) GetSubscribed(ctx context.Context, eventType string) ([]*domain.WebhookSubscription, error) {
+	return wr.findSubscriptions(ctx, bson.M{"active": true, "event_types": eventType})
+}
+
+func (wr *webhookRepositoryImpl) findSubscriptions(ctx context.Context, filter bson.M) ([]*domain.WebhookSubscription, error) {
+	collection := wr.conn.Collection("webhook_subscriptions")
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find webhook subscriptions", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []*domain.WebhookSubscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (wr *webhookRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	collection := wr.conn.Collection("webhook_subscriptions")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var subscription domain.WebhookSubscription
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Error("Webhook subscription not found", "id", id)
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (wr *webhookRepositoryImpl) Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	collection := wr.conn.Collection("webhook_subscriptions")
+	result, err := collection.InsertOne(ctx, subscription)
+	if err != nil {
+		logger.Error("Failed to create webhook subscription", "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	subscription.Id = insertedID
+	return subscription, nil
+}
+
+func (wr *webhookRepositoryImpl) Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	collection := wr.conn.Collection("webhook_subscriptions")
+	update := bson.M{"$set": bson.M{
+		"url":         subscription.URL,
+		"secret":      subscription.Secret,
+		"event_types": subscription.EventTypes,
+		"active":      subscription.Active,
+	}}
+
+	otps := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	result := collection.FindOneAndUpdate(ctx, bson.M{"_id": subscription.Id}, update, otps)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			logger.Error("Webhook subscription not found", "id", subscription.Id.Hex())
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var updatedSubscription domain.WebhookSubscription
+	if err := result.Decode(&updatedSubscription); err != nil {
+		return nil, err
+	}
+	return &updatedSubscription, nil
+}
+
+// Delete removes a subscription. Its delivery log is kept.
+func (wr *webhookRepositoryImpl) Delete(ctx context.Context, id string) error {
+	collection := wr.conn.Collection("webhook_subscriptions")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return domain.ErrInvalidInput
+	}
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		logger.Error("Failed to delete webhook subscription", "id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddDelivery queues a delivery. A delivery of the same event to the same
+// subscription that already exists is returned instead of a new one.
+func (wr *webhookRepositoryImpl) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	collection := wr.conn.Collection("webhook_deliveries")
+	result, err := collection.InsertOne(ctx, delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		var existing domain.WebhookDelivery
+		filter := bson.M{"subscription_id": delivery.SubscriptionID, "event_id": delivery.EventID}
+		if err := collection.FindOne(ctx, filter).Decode(&existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	if err != nil {
+		logger.Error("Failed to queue webhook delivery", "subscription_id", delivery.SubscriptionID, "event_id", delivery.EventID, "error", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		logger.Error("Failed to convert inserted ID to ObjectID", "insertedID", result.InsertedID)
+		return nil, fmt.Errorf("failed to convert inserted ID to ObjectID: %v", result.InsertedID)
+	}
+	delivery.Id = insertedID
+	return delivery, nil
+}
+
+func (wr *webhookRepositoryImpl) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	collection := wr.conn.Collection("webhook_deliveries")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		logger.Error("Invalid ID format", "id", id, "error", err)
+		return nil, domain.ErrInvalidInput
+	}
+
+	var delivery domain.WebhookDelivery
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (wr *webhookRepositoryImpl) GetDeliveries(ctx context.Context, query *domain.WebhookDeliveryQuery) ([]*domain.WebhookDelivery, int64, error) {
+	collection := wr.conn.Collection("webhook_deliveries")
+	filter := bson.M{"subscription_id": query.SubscriptionID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count webhook deliveries", "subscription_id", query.SubscriptionID, "error", err)
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.Limit)).
+		SetLimit(int64(query.Limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find webhook deliveries", "subscription_id", query.SubscriptionID, "error", err)
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []*domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// DueDeliveries returns pending deliveries whose next attempt is due, oldest
+// first.
+func (wr *webhookRepositoryImpl) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	collection := wr.conn.Collection("webhook_deliveries")
+	filter := bson.M{"status": domain.WebhookDeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to find due webhook deliveries", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*domain.WebhookDelivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// SaveDelivery writes the outcome of a delivery attempt.
+func (wr *webhookRepositoryImpl) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	collection := wr.conn.Collection("webhook_deliveries")
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": delivery.Id}, delivery)
+	if err != nil {
+		logger.Error("Failed to save webhook delivery", "id", delivery.Id.Hex(), "error", err)
+	}
+	return err
+}
+
+// DeleteDeliveries removes the deliveries of the given events from the
+// delivery log, including those still waiting to be sent.
+func (wr *webhookRepositoryImpl) DeleteDeliveries(ctx context.Context, eventIDs []string) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+	collection := wr.conn.Collection("webhook_deliveries")
+	result, err := collection.DeleteMany(ctx, bson.M{"event_id": bson.M{"$in": eventIDs}})
+	if err != nil {
+		logger.Error("Failed to delete webhook deliveries", "error", err)
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
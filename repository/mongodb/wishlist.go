@@ -0,0 +1,171 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ domain.WishlistRepository = (*wishlistRepositoryImpl)(nil)
+
+type wishlistRepositoryImpl struct {
+	conn *mongo.Database
+}
+
+func NewWishlistRepository(db *mongo.Database) domain.WishlistRepository {
+	return &wishlistRepositoryImpl{
+		conn: db,
+	}
+}
+
+// EnsureWishlistIndexes keeps one wishlist per customer and indexes the
+// product ids that back-in-stock lookups search by.
+func EnsureWishlistIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("wishlists")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "customer_id", Value: 1}},
+			Options: options.Index().SetName("wishlists_customer").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "items.product_id", Value: 1}},
+			Options: options.Index().SetName("wishlists_product"),
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to create wishlist indexes", "error", err)
+	}
+	return err
+}
+
+func (wr *wishlistRepositoryImpl) GetByCustomerID(ctx context.Context, customerID string) (*domain.Wishlist, error) {
+	collection := wr.conn.Collection("wishlists")
+	var wishlist domain.Wishlist
+	err := collection.FindOne(ctx, bson.M{"customer_id": customerID}).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		logger.Error("Failed to find wishlist", "customer_id", customerID, "error", err)
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// AddItem saves a product to the wishlist, creating the wishlist on first
+// use. Saving a line that is already there only updates its notify flag.
+func (wr *wishlistRepositoryImpl) AddItem(ctx context.Context, customerID string, item *domain.WishlistItem) (*domain.Wishlist, error) {
+	collection := wr.conn.Collection("wishlists")
+	line := wishlistLine(item.ProductID, item.VariantID)
+	now := time.Now()
+
+	wishlist, err := wr.findOneAndUpdate(ctx, collection,
+		bson.M{"customer_id": customerID, "items": bson.M{"$elemMatch": line}},
+		bson.M{"$set": bson.M{"items.$.notify_back_in_stock": item.NotifyBackInStock, "updated_at": now}},
+		false,
+	)
+	if !errors.Is(err, domain.ErrNotFound) {
+		return wishlist, err
+	}
+
+	wishlist, err = wr.findOneAndUpdate(ctx, collection,
+		bson.M{"customer_id": customerID, "items": bson.M{"$not": bson.M{"$elemMatch": line}}},
+		bson.M{"$push": bson.M{"items": item}, "$set": bson.M{"updated_at": now}},
+		true,
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: wishlist was changed by another request", domain.ErrConflict)
+	}
+	if err != nil {
+		logger.Error("Failed to add item to wishlist", "customer_id", customerID, "product_id", item.ProductID, "error", err)
+		return nil, err
+	}
+	logger.Info("Added item to wishlist", "customer_id", customerID, "product_id", item.ProductID, "variant_id", item.VariantID)
+	return wishlist, nil
+}
+
+// RemoveItem removes exactly the given line; an empty variantID is the line
+// saved without a variant.
+func (wr *wishlistRepositoryImpl) RemoveItem(ctx context.Context, customerID string, productID string, variantID string) (*domain.Wishlist, error) {
+	collection := wr.conn.Collection("wishlists")
+	line := wishlistLine(productID, variantID)
+	wishlist, err := wr.findOneAndUpdate(ctx, collection,
+		bson.M{"customer_id": customerID, "items": bson.M{"$elemMatch": line}},
+		bson.M{"$pull": bson.M{"items": line}, "$set": bson.M{"updated_at": time.Now()}},
+		false,
+	)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: product %s is not in the wishlist", domain.ErrNotFound, productID)
+	}
+	if err != nil {
+		logger.Error("Failed to remove item from wishlist", "customer_id", customerID, "product_id", productID, "error", err)
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// GetWatching returns the wishlists with a line of the product that asked to
+// be told when it is back in stock.
+func (wr *wishlistRepositoryImpl) GetWatching(ctx context.Context, productID string) ([]*domain.Wishlist, error) {
+	collection := wr.conn.Collection("wishlists")
+	cursor, err := collection.Find(ctx, bson.M{"items": bson.M{"$elemMatch": bson.M{
+		"product_id":           productID,
+		"notify_back_in_stock": true,
+	}}})
+	if err != nil {
+		logger.Error("Failed to find wishlists watching product", "product_id", productID, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var wishlists []*domain.Wishlist
+	if err := cursor.All(ctx, &wishlists); err != nil {
+		logger.Error("Failed to decode wishlists", "error", err)
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (wr *wishlistRepositoryImpl) Delete(ctx context.Context, customerID string) error {
+	collection := wr.conn.Collection("wishlists")
+	if _, err := collection.DeleteOne(ctx, bson.M{"customer_id": customerID}); err != nil {
+		logger.Error("Failed to delete wishlist", "customer_id", customerID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (wr *wishlistRepositoryImpl) findOneAndUpdate(ctx context.Context, collection *mongo.Collection, filter bson.M, update bson.M, upsert bool) (*domain.Wishlist, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(upsert)
+	result := collection.FindOneAndUpdate(ctx, filter, update, opts)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Err()
+	}
+
+	var wishlist domain.Wishlist
+	if err := result.Decode(&wishlist); err != nil {
+		logger.Error("Failed to decode wishlist", "error", err)
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// wishlistLine matches one saved line. Lines without a variant have no
+// variant_id field, which a null comparison matches.
+func wishlistLine(productID, variantID string) bson.M {
+	line := bson.M{"product_id": productID, "variant_id": nil}
+	if variantID != "" {
+		line["variant_id"] = variantID
+	}
+	return line
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"intern-project-v2/utils"
+	"strings"
+)
+
+var _ domain.AccountUsecase = (*accountUsecaseImpl)(nil)
+
+// errWrongPassword is returned when a change to the account is confirmed
+// with the wrong current password.
+var errWrongPassword = fmt.Errorf("%w: current password is incorrect", domain.ErrForbidden)
+
+type accountUsecaseImpl struct {
+	authRepo     domain.AuthRepository
+	customerRepo domain.CustomerRepository
+	tokenRepo    domain.AuthTokenRepository
+	policy       *domain.AuthPolicy
+	notifier     domain.Notifier
+	privacy      domain.PrivacyUsecase
+}
+
+func NewAccountUsecase(
+	authRepo domain.AuthRepository,
+	customerRepo domain.CustomerRepository,
+	tokenRepo domain.AuthTokenRepository,
+	policy *domain.AuthPolicy,
+	notifier domain.Notifier,
+	privacy domain.PrivacyUsecase,
+) domain.AccountUsecase {
+	return &accountUsecaseImpl{
+		authRepo:     authRepo,
+		customerRepo: customerRepo,
+		tokenRepo:    tokenRepo,
+		policy:       policy,
+		notifier:     notifier,
+		privacy:      privacy,
+	}
+}
+
+func (au *accountUsecaseImpl) Get(ctx context.Context, actor *domain.Actor) (*domain.Customer, error) {
+	return au.authRepo.GetByID(ctx, actor.CustomerID)
+}
+
+// UpdateProfile changes the name and phone number. The email address and
+// password have their own, confirmed, operations.
+func (au *accountUsecaseImpl) UpdateProfile(ctx context.Context, actor *domain.Actor, req *domain.ProfileRequest) (*domain.Customer, error) {
+	name, phone := strings.TrimSpace(req.Name), strings.TrimSpace(req.Phone)
+	if name == "" && phone == "" {
+		return nil, fmt.Errorf("%w: nothing to update", domain.ErrInvalidInput)
+	}
+	return au.customerRepo.Update(ctx, actor.CustomerID, &domain.CustomerRequest{Name: name, Phone: phone})
+}
+
+// ChangePassword replaces the password and signs out every other session.
+// The returned token replaces the caller's.
+func (au *accountUsecaseImpl) ChangePassword(ctx context.Context, actor *domain.Actor, req *domain.ChangePasswordRequest) (*domain.AccountSession, error) {
+	if len(req.NewPassword) < domain.MinPasswordLength {
+		return nil, fmt.Errorf("%w: password must be at least %d characters", domain.ErrInvalidInput, domain.MinPasswordLength)
+	}
+	cust, err := au.confirm(ctx, actor, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+	hashed := &domain.Customer{Password: req.NewPassword}
+	if err := hashed.HashPassword(); err != nil {
+		return nil, err
+	}
+	if err := au.authRepo.SetPassword(ctx, actor.CustomerID, hashed.Password, sessionTime()); err != nil {
+		return nil, err
+	}
+	return newSession(cust, actor.MFA)
+}
+
+// ChangeEmail moves the account to a new address, which has to be verified
+// again; a verification link is sent to it.
+func (au *accountUsecaseImpl) ChangeEmail(ctx context.Context, actor *domain.Actor, req *domain.ChangeEmailRequest) (*domain.AccountSession, error) {
+	email := strings.TrimSpace(req.Email)
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: a valid email address is required", domain.ErrInvalidInput)
+	}
+	cust, err := au.confirm(ctx, actor, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(email, cust.Email) {
+		return nil, fmt.Errorf("%w: this is already the account's email address", domain.ErrInvalidInput)
+	}
+	if _, err := au.authRepo.Login(ctx, email); err == nil {
+		return nil, fmt.Errorf("%w: the email address is in use", domain.ErrConflict)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	if err := au.authRepo.SetEmail(ctx, actor.CustomerID, email); err != nil {
+		return nil, err
+	}
+	cust.Email, cust.EmailVerified, cust.EmailVerifiedAt = email, false, nil
+
+	if err := au.tokenRepo.Revoke(ctx, actor.CustomerID, domain.TokenEmailVerification, sessionTime()); err != nil {
+		logger.Error("Failed to revoke verification links", "customer_id", actor.CustomerID, "error", err)
+	}
+	verifyURL, expiresAt, err := issueToken(ctx, au.tokenRepo, au.policy, cust, domain.TokenEmailVerification)
+	if err != nil {
+		// The address is changed; the customer can ask for another link.
+		logger.Error("Failed to issue email verification token", "customer_id", actor.CustomerID, "error", err)
+	} else {
+		notify(ctx, au.notifier, email, domain.EmailVerification, &domain.EmailVerificationEmail{
+			Name:      cust.Name,
+			VerifyURL: verifyURL,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return newSession(cust, actor.MFA)
+}
+
+// Delete erases the caller's account once they confirmed their password.
+// Past orders are kept under a pseudonym; see PrivacyUsecase.Erase.
+func (au *accountUsecaseImpl) Delete(ctx context.Context, actor *domain.Actor, req *domain.DeleteAccountRequest) error {
+	if _, err := au.confirm(ctx, actor, req.CurrentPassword); err != nil {
+		return err
+	}
+	_, err := au.privacy.Erase(ctx, actor, actor.CustomerID)
+	return err
+}
+
+// confirm loads the caller and checks their current password.
+func (au *accountUsecaseImpl) confirm(ctx context.Context, actor *domain.Actor, password string) (*domain.Customer, error) {
+	cust, err := au.authRepo.GetByID(ctx, actor.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if !cust.CheckPassword(password) {
+		return nil, errWrongPassword
+	}
+	return cust, nil
+}
+
+// newSession issues a login token; mfa records that the customer passed the
+// second factor.
+func newSession(cust *domain.Customer, mfa bool) (*domain.AccountSession, error) {
+	if cust.Role == "" {
+		cust.Role = domain.RoleCustomer
+	}
+	token, err := utils.GenerateJWT(cust.Id.Hex(), cust.Email, cust.Role, mfa)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AccountSession{Customer: cust, Token: token}, nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func accountCustomer(t *testing.T) *domain.Customer {
+	customer := &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com", Password: "current password", EmailVerified: true}
+	assert.NoError(t, customer.HashPassword())
+	return customer
+}
+
+func TestAccountUsecase_ChangePassword(t *testing.T) {
+	customer := accountCustomer(t)
+	actor := &domain.Actor{CustomerID: customer.Id.Hex(), Email: customer.Email, Role: domain.RoleCustomer}
+
+	tests := []struct {
+		name          string
+		request       *domain.ChangePasswordRequest
+		mockSetup     func(*MockAuthRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Other sessions are signed out",
+			request: &domain.ChangePasswordRequest{CurrentPassword: "current password", NewPassword: "new password"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				ar.On("SetPassword", mock.Anything, customer.Id.Hex(), mock.MatchedBy(func(hashed string) bool {
+					return (&domain.Customer{Password: hashed}).CheckPassword("new password")
+				}), mock.MatchedBy(func(changedAt time.Time) bool {
+					return time.Since(changedAt) < time.Minute && changedAt.Equal(changedAt.Truncate(time.Second))
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "Error - Wrong current password",
+			request: &domain.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "new password"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+		{
+			name:          "Error - New password too short",
+			request:       &domain.ChangePasswordRequest{CurrentPassword: "current password", NewPassword: "short"},
+			mockSetup:     func(ar *MockAuthRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authRepo := new(MockAuthRepository)
+			tt.mockSetup(authRepo)
+			usecase := NewAccountUsecase(authRepo, nil, nil, testAuthPolicy(), nil, nil)
+
+			// Act
+			result, err := usecase.ChangePassword(context.Background(), actor, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.Token)
+			}
+			authRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountUsecase_ChangeEmail(t *testing.T) {
+	customer := accountCustomer(t)
+	actor := &domain.Actor{CustomerID: customer.Id.Hex(), Email: customer.Email, Role: domain.RoleCustomer}
+
+	t.Run("Success - New address is unverified and gets a link", func(t *testing.T) {
+		// Arrange
+		authRepo := new(MockAuthRepository)
+		tokenRepo := new(MockAuthTokenRepository)
+		notifier := new(MockNotifier)
+		authRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+		authRepo.On("Login", mock.Anything, "ann@new.example.com").Return(nil, domain.ErrNotFound)
+		authRepo.On("SetEmail", mock.Anything, customer.Id.Hex(), "ann@new.example.com").Return(nil)
+		tokenRepo.On("Revoke", mock.Anything, customer.Id.Hex(), domain.TokenEmailVerification, mock.Anything).Return(nil)
+		tokenRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		notifier.On("Notify", mock.Anything, "ann@new.example.com", domain.EmailVerification, mock.MatchedBy(func(data *domain.EmailVerificationEmail) bool {
+			return strings.HasPrefix(data.VerifyURL, "https://api.example.com/api/auth/verify-email?token=")
+		})).Return(nil)
+		usecase := NewAccountUsecase(authRepo, nil, tokenRepo, testAuthPolicy(), notifier, nil)
+
+		// Act
+		result, err := usecase.ChangeEmail(context.Background(), actor, &domain.ChangeEmailRequest{Email: "ann@new.example.com", CurrentPassword: "current password"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "ann@new.example.com", result.Customer.Email)
+		assert.False(t, result.Customer.EmailVerified)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("Error - Address belongs to another account", func(t *testing.T) {
+		// Arrange
+		authRepo := new(MockAuthRepository)
+		authRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+		authRepo.On("Login", mock.Anything, "bob@example.com").Return(&domain.Customer{Id: bson.NewObjectID()}, nil)
+		usecase := NewAccountUsecase(authRepo, nil, nil, testAuthPolicy(), nil, nil)
+
+		// Act
+		result, err := usecase.ChangeEmail(context.Background(), actor, &domain.ChangeEmailRequest{Email: "bob@example.com", CurrentPassword: "current password"})
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, result)
+		authRepo.AssertNotCalled(t, "SetEmail", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountUsecase_Delete(t *testing.T) {
+	customer := accountCustomer(t)
+	actor := &domain.Actor{CustomerID: customer.Id.Hex(), Email: customer.Email, Role: domain.RoleCustomer}
+
+	tests := []struct {
+		name          string
+		request       *domain.DeleteAccountRequest
+		mockSetup     func(*MockAuthRepository, *privacyMocks)
+		expectedError error
+	}{
+		{
+			name:    "Success - Personal data is erased",
+			request: &domain.DeleteAccountRequest{CurrentPassword: "current password"},
+			mockSetup: func(ar *MockAuthRepository, pm *privacyMocks) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				pm.expectErasure(customer)
+			},
+		},
+		{
+			name:    "Error - Open orders",
+			request: &domain.DeleteAccountRequest{CurrentPassword: "current password"},
+			mockSetup: func(ar *MockAuthRepository, pm *privacyMocks) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				pm.customers.On("GetByIDIncludingDeleted", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				pm.orders.On("CountOpenByCustomer", mock.Anything, customer.Id.Hex()).Return(int64(1), nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:    "Error - Wrong current password",
+			request: &domain.DeleteAccountRequest{CurrentPassword: "guess"},
+			mockSetup: func(ar *MockAuthRepository, pm *privacyMocks) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authRepo := new(MockAuthRepository)
+			mocks := newPrivacyMocks()
+			tt.mockSetup(authRepo, mocks)
+			usecase := NewAccountUsecase(authRepo, mocks.customers, nil, testAuthPolicy(), nil, mocks.usecase())
+
+			// Act
+			err := usecase.Delete(context.Background(), actor, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			authRepo.AssertExpectations(t)
+			mocks.assertExpectations(t)
+		})
+	}
+}
+
+func TestAuthUsecase_ValidateSession(t *testing.T) {
+	signedOutAt := time.Now().Truncate(time.Second)
+	customer := &domain.Customer{Id: bson.NewObjectID(), SessionsValidAfter: &signedOutAt}
+	authRepo := new(MockAuthRepository)
+	authRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+	deleted := bson.NewObjectID().Hex()
+	authRepo.On("GetByID", mock.Anything, deleted).Return(nil, domain.ErrNotFound)
+	usecase := NewAuthUsecase(authRepo, nil, testAuthPolicy(), nil, nil, nil)
+
+	assert.NoError(t, usecase.ValidateSession(context.Background(), customer.Id.Hex(), signedOutAt))
+	assert.ErrorIs(t, usecase.ValidateSession(context.Background(), customer.Id.Hex(), signedOutAt.Add(-time.Second)), domain.ErrUnauthorized)
+	assert.ErrorIs(t, usecase.ValidateSession(context.Background(), deleted, signedOutAt), domain.ErrUnauthorized)
+}
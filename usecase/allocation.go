@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"intern-project-v2/domain"
+	"math"
+	"slices"
+)
+
+// earthRadiusKm is the mean radius used for distances between warehouses and
+// shipping addresses.
+const earthRadiusKm = 6371.0
+
+// stockSource is a place one line of an order can be shipped from. An empty
+// warehouseID is the stock no warehouse holds yet.
+type stockSource struct {
+	warehouseID string
+	stock       int
+	distance    float64
+}
+
+// allocate decides which warehouses ship quantity units of a product, or of
+// one of its variants. Active warehouses holding the line are the sources;
+// unassigned stock comes last, as if it were farther than any warehouse.
+//
+// nearest and most_stock ship the line from a single source and fail when
+// none holds enough. split fills the line from as many sources as needed,
+// nearest first when the order has a destination and fullest first
+// otherwise.
+func allocate(strategy string, shipTo *domain.GeoPoint, warehouses []*domain.Warehouse, product *domain.Product, variantID string, quantity int) ([]*domain.StockAllocation, error) {
+	levels := product.WarehouseStockOf(variantID)
+	var sources []*stockSource
+	available := 0
+	for _, warehouse := range warehouses {
+		id := warehouse.Id.Hex()
+		if !warehouse.Active || levels[id] <= 0 {
+			continue
+		}
+		sources = append(sources, &stockSource{warehouseID: id, stock: levels[id], distance: distanceKm(shipTo, warehouse.Location)})
+		available += levels[id]
+	}
+	if unassigned := product.UnassignedStock(variantID); unassigned > 0 {
+		sources = append(sources, &stockSource{stock: unassigned, distance: math.Inf(1)})
+		available += unassigned
+	}
+	if available < quantity {
+		return nil, fmt.Errorf("%w: not enough stock of product %s", domain.ErrConflict, product.Id.Hex())
+	}
+
+	nearest := func(a, b *stockSource) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(b.stock, a.stock))
+	}
+	mostStock := func(a, b *stockSource) int {
+		return cmp.Compare(b.stock, a.stock)
+	}
+	allocation := func(source *stockSource, quantity int) *domain.StockAllocation {
+		return &domain.StockAllocation{WarehouseID: source.warehouseID, ProductID: product.Id.Hex(), VariantID: variantID, Quantity: quantity}
+	}
+
+	switch strategy {
+	case domain.AllocationNearest, domain.AllocationMostStock:
+		if strategy == domain.AllocationNearest {
+			slices.SortStableFunc(sources, nearest)
+		} else {
+			slices.SortStableFunc(sources, mostStock)
+		}
+		for _, source := range sources {
+			if source.stock >= quantity {
+				return []*domain.StockAllocation{allocation(source, quantity)}, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: no single warehouse holds %d of product %s, use split allocation", domain.ErrConflict, quantity, product.Id.Hex())
+	default:
+		if shipTo != nil {
+			slices.SortStableFunc(sources, nearest)
+		} else {
+			slices.SortStableFunc(sources, mostStock)
+		}
+		var allocations []*domain.StockAllocation
+		for _, source := range sources {
+			if quantity == 0 {
+				break
+			}
+			take := min(source.stock, quantity)
+			allocations = append(allocations, allocation(source, take))
+			quantity -= take
+		}
+		return allocations, nil
+	}
+}
+
+// validateAllocation checks the allocation rule of a new order, defaulting to
+// split. Shipping from the nearest warehouse needs a destination.
+func validateAllocation(orderReq *domain.OrderRequest) error {
+	if orderReq.Allocation == "" {
+		orderReq.Allocation = domain.AllocationSplit
+	}
+	if !slices.Contains(domain.AllocationStrategies, orderReq.Allocation) {
+		return fmt.Errorf("%w: allocation must be one of %v", domain.ErrInvalidInput, domain.AllocationStrategies)
+	}
+	if orderReq.ShipTo == nil {
+		if orderReq.Allocation == domain.AllocationNearest {
+			return fmt.Errorf("%w: nearest allocation needs ship_to", domain.ErrInvalidInput)
+		}
+		return nil
+	}
+	if !validGeoPoint(orderReq.ShipTo) {
+		return fmt.Errorf("%w: ship_to is out of range", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func validGeoPoint(point *domain.GeoPoint) bool {
+	return point.Latitude >= -90 && point.Latitude <= 90 && point.Longitude >= -180 && point.Longitude <= 180
+}
+
+// distanceKm returns the great-circle distance between two points. Unknown
+// points are infinitely far away.
+func distanceKm(from *domain.GeoPoint, to *domain.GeoPoint) float64 {
+	if from == nil || to == nil {
+		return math.Inf(1)
+	}
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (to.Longitude - from.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"slices"
+	"strings"
+	"time"
+)
+
+var _ domain.APIKeyUsecase = (*apiKeyUsecaseImpl)(nil)
+
+// apiKeyTouchInterval limits how often the last use of a key is written, so
+// a busy client does not cause a write per request.
+const apiKeyTouchInterval = time.Minute
+
+var errInvalidAPIKey = fmt.Errorf("%w: invalid API key", domain.ErrUnauthorized)
+
+type apiKeyUsecaseImpl struct {
+	apiKeyRepo domain.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(apiKeyRepo domain.APIKeyRepository) domain.APIKeyUsecase {
+	return &apiKeyUsecaseImpl{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (ku *apiKeyUsecaseImpl) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	return ku.apiKeyRepo.GetAll(ctx)
+}
+
+func (ku *apiKeyUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return ku.apiKeyRepo.GetByID(ctx, id)
+}
+
+// Create issues a key. The key is returned this once; only its hash is
+// stored.
+func (ku *apiKeyUsecaseImpl) Create(ctx context.Context, actor *domain.Actor, keyReq *domain.APIKeyRequest) (*domain.IssuedAPIKey, error) {
+	name := strings.TrimSpace(keyReq.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	scopes, err := validScopes(keyReq.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit := keyReq.RateLimit
+	if rateLimit == 0 {
+		rateLimit = domain.DefaultAPIKeyRateLimit
+	}
+	if rateLimit < 1 || rateLimit > domain.MaxAPIKeyRateLimit {
+		return nil, fmt.Errorf("%w: rate_limit must be between 1 and %d requests per minute", domain.ErrInvalidInput, domain.MaxAPIKeyRateLimit)
+	}
+	now := time.Now()
+	if keyReq.ExpiresAt != nil && !keyReq.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidInput)
+	}
+
+	prefix, plain, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key, err := ku.apiKeyRepo.Create(ctx, &domain.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashToken(plain),
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		ExpiresAt: keyReq.ExpiresAt,
+		CreatedBy: actor.CustomerID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("API key issued", "prefix", key.Prefix, "scopes", key.Scopes, "created_by", actor.CustomerID)
+	return &domain.IssuedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+// Revoke stops a key from working. It is kept, so its use stays traceable.
+func (ku *apiKeyUsecaseImpl) Revoke(ctx context.Context, id string) (*domain.APIKey, error) {
+	key, err := ku.apiKeyRepo.Revoke(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("API key revoked", "prefix", key.Prefix)
+	return key, nil
+}
+
+// Authenticate returns the key a request was sent with. Revoked and expired
+// keys are rejected.
+func (ku *apiKeyUsecaseImpl) Authenticate(ctx context.Context, plain string) (*domain.APIKey, error) {
+	prefix, ok := apiKeyPrefix(plain)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+	key, err := ku.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashToken(plain))) != 1 {
+		logger.Warn("API key with a known prefix and a wrong secret", "prefix", prefix)
+		return nil, errInvalidAPIKey
+	}
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: API key was revoked", domain.ErrUnauthorized)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: API key expired", domain.ErrUnauthorized)
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// The request goes ahead even when the last use cannot be recorded.
+		if err := ku.apiKeyRepo.Touch(ctx, key.Id.Hex(), now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+// validScopes checks the requested scopes and returns them sorted without
+// duplicates.
+func validScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", domain.ErrInvalidInput)
+	}
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		resource, access, _ := strings.Cut(scope, ":")
+		if !slices.Contains(domain.APIKeyResources, resource) || (access != "read" && access != "write") {
+			return nil, fmt.Errorf("%w: unknown scope %q, use <resource>:read or <resource>:write with one of %s", domain.ErrInvalidInput, scope, strings.Join(domain.APIKeyResources, ", "))
+		}
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+// newAPIKey returns a key of the form omk_<id>_<secret> and its prefix,
+// omk_<id>.
+func newAPIKey() (string, string, error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix := domain.APIKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + hex.EncodeToString(secret), nil
+}
+
+func apiKeyPrefix(plain string) (string, bool) {
+	id, secret, found := strings.Cut(strings.TrimPrefix(plain, domain.APIKeyPrefix), "_")
+	if !strings.HasPrefix(plain, domain.APIKeyPrefix) || !found || id == "" || secret == "" {
+		return "", false
+	}
+	return domain.APIKeyPrefix + id, true
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (*domain.APIKey, error) {
+	args := m.Called(ctx, id, revokedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+func TestAPIKeyUsecase_Create(t *testing.T) {
+	admin := &domain.Actor{CustomerID: bson.NewObjectID().Hex(), Role: domain.RoleAdmin, MFA: true}
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		request       *domain.APIKeyRequest
+		expectedError error
+	}{
+		{
+			name:    "Success - Defaults and sorted scopes",
+			request: &domain.APIKeyRequest{Name: " ERP ", Scopes: []string{"orders:read", "inventory:write", "orders:read"}},
+		},
+		{
+			name:          "Error - Missing name",
+			request:       &domain.APIKeyRequest{Scopes: []string{"orders:read"}},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Unknown scope",
+			request:       &domain.APIKeyRequest{Name: "ERP", Scopes: []string{"orders:delete"}},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - No scopes",
+			request:       &domain.APIKeyRequest{Name: "ERP"},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Rate limit too high",
+			request:       &domain.APIKeyRequest{Name: "ERP", Scopes: []string{"orders:read"}, RateLimit: domain.MaxAPIKeyRateLimit + 1},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Expiry in the past",
+			request:       &domain.APIKeyRequest{Name: "ERP", Scopes: []string{"orders:read"}, ExpiresAt: &past},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			keyRepo := new(MockAPIKeyRepository)
+			if tt.expectedError == nil {
+				created := &domain.APIKey{}
+				keyRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).
+					Run(func(args mock.Arguments) { *created = *args.Get(1).(*domain.APIKey) }).
+					Return(created, nil)
+			}
+			usecase := NewAPIKeyUsecase(keyRepo)
+
+			// Act
+			issued, err := usecase.Create(context.Background(), admin, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, issued)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "ERP", issued.Name)
+				assert.Equal(t, []string{"inventory:write", "orders:read"}, issued.Scopes)
+				assert.Equal(t, domain.DefaultAPIKeyRateLimit, issued.RateLimit)
+				assert.Equal(t, admin.CustomerID, issued.CreatedBy)
+				assert.True(t, strings.HasPrefix(issued.Key, issued.Prefix+"_"))
+				assert.True(t, strings.HasPrefix(issued.Prefix, domain.APIKeyPrefix))
+				assert.Equal(t, hashToken(issued.Key), issued.Hash)
+			}
+			keyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	prefix, plain, err := newAPIKey()
+	assert.NoError(t, err)
+	recently := time.Now().Add(-10 * time.Second)
+	past := time.Now().Add(-time.Hour)
+	newKey := func() *domain.APIKey {
+		return &domain.APIKey{Id: bson.NewObjectID(), Prefix: prefix, Hash: hashToken(plain), Scopes: []string{"orders:read"}}
+	}
+
+	tests := []struct {
+		name          string
+		plain         string
+		mockSetup     func(*MockAPIKeyRepository, *domain.APIKey)
+		expectedError error
+	}{
+		{
+			name:  "Success - First use is recorded",
+			plain: plain,
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(key, nil)
+				kr.On("Touch", mock.Anything, key.Id.Hex(), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name:  "Success - Recent use is not recorded again",
+			plain: plain,
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				key.LastUsedAt = &recently
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(key, nil)
+			},
+		},
+		{
+			name:  "Error - Wrong secret",
+			plain: prefix + "_0000",
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(key, nil)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:  "Error - Unknown key",
+			plain: plain,
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(nil, domain.ErrNotFound)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:  "Error - Revoked",
+			plain: plain,
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				key.RevokedAt = &past
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(key, nil)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:  "Error - Expired",
+			plain: plain,
+			mockSetup: func(kr *MockAPIKeyRepository, key *domain.APIKey) {
+				key.ExpiresAt = &past
+				kr.On("GetByPrefix", mock.Anything, prefix).Return(key, nil)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:          "Error - Not an API key",
+			plain:         "Bearer something",
+			mockSetup:     func(kr *MockAPIKeyRepository, key *domain.APIKey) {},
+			expectedError: domain.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			keyRepo := new(MockAPIKeyRepository)
+			key := newKey()
+			tt.mockSetup(keyRepo, key)
+			usecase := NewAPIKeyUsecase(keyRepo)
+
+			// Act
+			result, err := usecase.Authenticate(context.Background(), tt.plain)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, key.Id, result.Id)
+				assert.NotNil(t, result.LastUsedAt)
+			}
+			keyRepo.AssertExpectations(t)
+		})
+	}
+}
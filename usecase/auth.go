@@ -2,19 +2,44 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"intern-project-v2/domain"
-	"intern-project-v2/utils"
+	"intern-project-v2/logger"
+	"strings"
+	"time"
 )
 
 var _ domain.AuthUsecase = (*authUsecaseImpl)(nil)
 
+// errBadCredentials does not say whether the email or the password was
+// wrong, so login cannot be used to find out who has an account.
+var errBadCredentials = fmt.Errorf("%w: invalid email or password", domain.ErrUnauthorized)
+
 type authUsecaseImpl struct {
-	authRepo domain.AuthRepository
+	authRepo  domain.AuthRepository
+	tokenRepo domain.AuthTokenRepository
+	policy    *domain.AuthPolicy
+	notifier  domain.Notifier
+	events    domain.EventPublisher
+	tx        domain.Transactor
 }
 
-func NewAuthUsecase(authRepo domain.AuthRepository) domain.AuthUsecase {
+func NewAuthUsecase(
+	authRepo domain.AuthRepository,
+	tokenRepo domain.AuthTokenRepository,
+	policy *domain.AuthPolicy,
+	notifier domain.Notifier,
+	events domain.EventPublisher,
+	tx domain.Transactor,
+) domain.AuthUsecase {
 	return &authUsecaseImpl{
-		authRepo: authRepo,
+		authRepo:  authRepo,
+		tokenRepo: tokenRepo,
+		policy:    policy,
+		notifier:  notifier,
+		events:    events,
+		tx:        tx,
 	}
 }
 func (au *authUsecaseImpl) Register(ctx context.Context, customer *domain.CustomerRegiser) (*domain.Customer, error) {
@@ -23,27 +48,170 @@ func (au *authUsecaseImpl) Register(ctx context.Context, customer *domain.Custom
 		Email:    customer.Email,
 		Password: customer.Password,
 		Phone:    customer.Phone,
+		Role:     domain.RoleCustomer,
 	}
 
 	if err := cust.HashPassword(); err != nil {
 		return nil, err
 	}
 
-	if err := au.authRepo.Register(ctx, cust); err != nil {
+	err := withinTransaction(ctx, au.tx, func(ctx context.Context) error {
+		if err := au.authRepo.Register(ctx, cust); err != nil {
+			return err
+		}
+		return publish(ctx, au.events, domain.EventCustomerRegistered, cust.Id.Hex(), &domain.CustomerRegisteredPayload{
+			CustomerID: cust.Id.Hex(),
+			Name:       cust.Name,
+			Email:      cust.Email,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// The account exists either way; without a link the customer can ask
+	// for another verification email.
+	verifyURL, _, err := issueToken(ctx, au.tokenRepo, au.policy, cust, domain.TokenEmailVerification)
+	if err != nil {
+		logger.Error("Failed to issue email verification token", "customer_id", cust.Id.Hex(), "error", err)
+	}
+	notify(ctx, au.notifier, cust.Email, domain.EmailWelcome, &domain.WelcomeEmail{Name: cust.Name, VerifyURL: verifyURL})
+
 	return cust, nil
 }
-func (au *authUsecaseImpl) Login(ctx context.Context, customer *domain.CustomerLogin) (*domain.Customer, string, error) {
-	token, err := utils.GenerateJWT(customer.Email)
+
+// Login checks the password. Customers with two-factor authentication get
+// a challenge to answer with VerifyMFA instead of a login token. Admins who
+// have not enrolled yet get a token without admin access, to enroll with.
+func (au *authUsecaseImpl) Login(ctx context.Context, customer *domain.CustomerLogin) (*domain.LoginResult, error) {
+	cust, err := au.authRepo.Login(ctx, customer.Email)
 	if err != nil {
-		return nil, "", err
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, errBadCredentials
+		}
+		return nil, err
 	}
-	cust, err := au.authRepo.Login(ctx, customer.Email)
+	if !cust.CheckPassword(customer.Password) {
+		return nil, errBadCredentials
+	}
+	if cust.MFAEnabled {
+		return au.challenge(cust)
+	}
+	session, err := newSession(cust, false)
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+	return &domain.LoginResult{
+		Customer:              cust,
+		Token:                 session.Token,
+		MFAEnrollmentRequired: cust.Role == domain.RoleAdmin,
+	}, nil
+}
+
+// ForgotPassword emails a password reset link. It succeeds for unknown
+// addresses too, so it cannot be used to find out who has an account.
+// Earlier reset links stop working.
+func (au *authUsecaseImpl) ForgotPassword(ctx context.Context, req *domain.ForgotPasswordRequest) error {
+	cust, err := au.customerByEmail(ctx, req.Email)
+	if err != nil || cust == nil {
+		return err
+	}
+	if err := au.tokenRepo.Revoke(ctx, cust.Id.Hex(), domain.TokenPasswordReset, time.Now()); err != nil {
+		return err
 	}
+	resetURL, expiresAt, err := issueToken(ctx, au.tokenRepo, au.policy, cust, domain.TokenPasswordReset)
+	if err != nil {
+		return err
+	}
+	notify(ctx, au.notifier, cust.Email, domain.EmailPasswordReset, &domain.PasswordResetEmail{
+		Name:      cust.Name,
+		ResetURL:  resetURL,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
 
-	return cust, token, nil
+// ResetPassword sets a new password with a token from a reset email and
+// signs out every session. As the token was mailed to the customer, it also
+// verifies their address.
+func (au *authUsecaseImpl) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	if len(req.Password) < domain.MinPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", domain.ErrInvalidInput, domain.MinPasswordLength)
+	}
+	cust := &domain.Customer{Password: req.Password}
+	if err := cust.HashPassword(); err != nil {
+		return err
+	}
+	now := sessionTime()
+	return withinTransaction(ctx, au.tx, func(ctx context.Context) error {
+		token, err := consumeToken(ctx, au.tokenRepo, domain.TokenPasswordReset, req.Token, now)
+		if err != nil {
+			return err
+		}
+		if err := au.authRepo.SetPassword(ctx, token.CustomerID, cust.Password, now); err != nil {
+			return err
+		}
+		return au.authRepo.MarkEmailVerified(ctx, token.CustomerID, now)
+	})
+}
+
+func (au *authUsecaseImpl) VerifyEmail(ctx context.Context, token string) error {
+	now := time.Now()
+	verification, err := consumeToken(ctx, au.tokenRepo, domain.TokenEmailVerification, token, now)
+	if err != nil {
+		return err
+	}
+	return au.authRepo.MarkEmailVerified(ctx, verification.CustomerID, now)
+}
+
+// ResendVerification emails a new verification link to an unverified
+// customer. Like ForgotPassword it succeeds for unknown addresses.
+func (au *authUsecaseImpl) ResendVerification(ctx context.Context, req *domain.ResendVerificationRequest) error {
+	cust, err := au.customerByEmail(ctx, req.Email)
+	if err != nil || cust == nil || cust.EmailVerified {
+		return err
+	}
+	if err := au.tokenRepo.Revoke(ctx, cust.Id.Hex(), domain.TokenEmailVerification, time.Now()); err != nil {
+		return err
+	}
+	verifyURL, expiresAt, err := issueToken(ctx, au.tokenRepo, au.policy, cust, domain.TokenEmailVerification)
+	if err != nil {
+		return err
+	}
+	notify(ctx, au.notifier, cust.Email, domain.EmailVerification, &domain.EmailVerificationEmail{
+		Name:      cust.Name,
+		VerifyURL: verifyURL,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
+
+// customerByEmail returns nil without an error for unknown addresses.
+func (au *authUsecaseImpl) customerByEmail(ctx context.Context, email string) (*domain.Customer, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, fmt.Errorf("%w: email is required", domain.ErrInvalidInput)
+	}
+	cust, err := au.authRepo.Login(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		logger.Info("Emailed link requested for unknown address")
+		return nil, nil
+	}
+	return cust, err
+}
+
+// ValidateSession accepts tokens of existing customers issued after their
+// last password change.
+func (au *authUsecaseImpl) ValidateSession(ctx context.Context, customerID string, issuedAt time.Time) error {
+	cust, err := au.authRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidInput) {
+			return fmt.Errorf("%w: the account no longer exists", domain.ErrUnauthorized)
+		}
+		return err
+	}
+	if cust.SessionsValidAfter != nil && issuedAt.Before(*cust.SessionsValidAfter) {
+		return fmt.Errorf("%w: the session has been signed out", domain.ErrUnauthorized)
+	}
+	return nil
 }
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"intern-project-v2/domain"
+	"intern-project-v2/utils"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// testKeys sign the tokens of the tests.
+var testKeys *utils.KeySet
+
+// TestMain signs the tokens of the tests with a throwaway key.
+func TestMain(m *testing.M) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	testKeys, err = utils.NewKeySet(&utils.SigningKey{ID: "test", Private: private})
+	if err != nil {
+		panic(err)
+	}
+	utils.SetKeySet(testKeys)
+	os.Exit(m.Run())
+}
+
+type MockAuthRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthRepository) Register(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) Login(ctx context.Context, email string) (*domain.Customer, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetByID(ctx context.Context, id string) (*domain.Customer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
+func (m *MockAuthRepository) SetPassword(ctx context.Context, customerID string, hashedPassword string, changedAt time.Time) error {
+	args := m.Called(ctx, customerID, hashedPassword, changedAt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) SetEmail(ctx context.Context, customerID string, email string) error {
+	args := m.Called(ctx, customerID, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) MarkEmailVerified(ctx context.Context, customerID string, verifiedAt time.Time) error {
+	args := m.Called(ctx, customerID, verifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) SetPendingMFASecret(ctx context.Context, customerID string, secret string) error {
+	args := m.Called(ctx, customerID, secret)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) EnableMFA(ctx context.Context, customerID string, secret string, recoveryCodes []string, step int64) error {
+	args := m.Called(ctx, customerID, secret, recoveryCodes, step)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) DisableMFA(ctx context.Context, customerID string) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) SetRecoveryCodes(ctx context.Context, customerID string, recoveryCodes []string) error {
+	args := m.Called(ctx, customerID, recoveryCodes)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseTOTPStep(ctx context.Context, customerID string, step int64) error {
+	args := m.Called(ctx, customerID, step)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseRecoveryCode(ctx context.Context, customerID string, hash string) error {
+	args := m.Called(ctx, customerID, hash)
+	return args.Error(0)
+}
+
+type MockAuthTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthTokenRepository) Create(ctx context.Context, token *domain.AuthToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthTokenRepository) Consume(ctx context.Context, purpose string, hash string, now time.Time) (*domain.AuthToken, error) {
+	args := m.Called(ctx, purpose, hash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuthToken), args.Error(1)
+}
+
+func (m *MockAuthTokenRepository) Revoke(ctx context.Context, customerID string, purpose string, now time.Time) error {
+	args := m.Called(ctx, customerID, purpose, now)
+	return args.Error(0)
+}
+
+func (m *MockAuthTokenRepository) DeleteByCustomer(ctx context.Context, customerID string) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func testAuthPolicy() *domain.AuthPolicy {
+	return &domain.AuthPolicy{
+		PasswordResetURL:     "https://shop.example.com/reset-password",
+		VerificationURL:      "https://api.example.com/api/auth/verify-email",
+		ResetTokenTTL:        time.Hour,
+		VerificationTokenTTL: 48 * time.Hour,
+		MFAIssuer:            "Order Management",
+		MFAChallengeTTL:      5 * time.Minute,
+	}
+}
+
+func TestAuthUsecase_Login(t *testing.T) {
+	customer := &domain.Customer{Id: bson.NewObjectID(), Email: "ann@example.com", Password: "correct horse"}
+	assert.NoError(t, customer.HashPassword())
+
+	tests := []struct {
+		name      string
+		request   *domain.CustomerLogin
+		mockSetup func(*MockAuthRepository)
+	}{
+		{
+			name:    "Error - Wrong password",
+			request: &domain.CustomerLogin{Email: "ann@example.com", Password: "wrong"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("Login", mock.Anything, "ann@example.com").Return(customer, nil)
+			},
+		},
+		{
+			name:    "Error - Unknown email",
+			request: &domain.CustomerLogin{Email: "bob@example.com", Password: "correct horse"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("Login", mock.Anything, "bob@example.com").Return(nil, domain.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authRepo := new(MockAuthRepository)
+			tt.mockSetup(authRepo)
+			usecase := NewAuthUsecase(authRepo, nil, testAuthPolicy(), nil, nil, nil)
+
+			// Act
+			result, err := usecase.Login(context.Background(), tt.request)
+
+			// Assert
+			assert.ErrorIs(t, err, domain.ErrUnauthorized)
+			assert.Nil(t, result)
+			authRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthUsecase_ForgotPassword(t *testing.T) {
+	customer := &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com"}
+
+	t.Run("Success - Emails a link whose token matches the stored hash", func(t *testing.T) {
+		// Arrange
+		authRepo := new(MockAuthRepository)
+		tokenRepo := new(MockAuthTokenRepository)
+		notifier := new(MockNotifier)
+		var stored *domain.AuthToken
+		authRepo.On("Login", mock.Anything, "ann@example.com").Return(customer, nil)
+		tokenRepo.On("Revoke", mock.Anything, customer.Id.Hex(), domain.TokenPasswordReset, mock.Anything).Return(nil)
+		tokenRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*domain.AuthToken)
+		}).Return(nil)
+		var emailed *domain.PasswordResetEmail
+		notifier.On("Notify", mock.Anything, customer.Email, domain.EmailPasswordReset, mock.Anything).Run(func(args mock.Arguments) {
+			emailed = args.Get(3).(*domain.PasswordResetEmail)
+		}).Return(nil)
+		usecase := NewAuthUsecase(authRepo, tokenRepo, testAuthPolicy(), notifier, nil, nil)
+
+		// Act
+		err := usecase.ForgotPassword(context.Background(), &domain.ForgotPasswordRequest{Email: " ann@example.com "})
+
+		// Assert
+		assert.NoError(t, err)
+		link, parseErr := url.Parse(emailed.ResetURL)
+		assert.NoError(t, parseErr)
+		plain := link.Query().Get("token")
+		assert.NotEmpty(t, plain)
+		assert.NotEqual(t, plain, stored.Hash, "only the hash of the token may be stored")
+		assert.Equal(t, hashToken(plain), stored.Hash)
+		assert.Equal(t, domain.TokenPasswordReset, stored.Purpose)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("Unknown email - Succeeds without sending anything", func(t *testing.T) {
+		// Arrange
+		authRepo := new(MockAuthRepository)
+		tokenRepo := new(MockAuthTokenRepository)
+		authRepo.On("Login", mock.Anything, "bob@example.com").Return(nil, domain.ErrNotFound)
+		usecase := NewAuthUsecase(authRepo, tokenRepo, testAuthPolicy(), nil, nil, nil)
+
+		// Act
+		err := usecase.ForgotPassword(context.Background(), &domain.ForgotPasswordRequest{Email: "bob@example.com"})
+
+		// Assert
+		assert.NoError(t, err)
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_ResetPassword(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+
+	tests := []struct {
+		name          string
+		request       *domain.ResetPasswordRequest
+		mockSetup     func(*MockAuthRepository, *MockAuthTokenRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Password is hashed and the address counts as verified",
+			request: &domain.ResetPasswordRequest{Token: "plain-token", Password: "new password"},
+			mockSetup: func(ar *MockAuthRepository, tr *MockAuthTokenRepository) {
+				tr.On("Consume", mock.Anything, domain.TokenPasswordReset, hashToken("plain-token"), mock.Anything).
+					Return(&domain.AuthToken{CustomerID: customerID}, nil)
+				ar.On("SetPassword", mock.Anything, customerID, mock.MatchedBy(func(hashed string) bool {
+					return (&domain.Customer{Password: hashed}).CheckPassword("new password")
+				}), mock.Anything).Return(nil)
+				ar.On("MarkEmailVerified", mock.Anything, customerID, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:    "Error - Used, expired or unknown token",
+			request: &domain.ResetPasswordRequest{Token: "plain-token", Password: "new password"},
+			mockSetup: func(ar *MockAuthRepository, tr *MockAuthTokenRepository) {
+				tr.On("Consume", mock.Anything, domain.TokenPasswordReset, hashToken("plain-token"), mock.Anything).
+					Return(nil, domain.ErrNotFound)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Password too short",
+			request:       &domain.ResetPasswordRequest{Token: "plain-token", Password: "short"},
+			mockSetup:     func(ar *MockAuthRepository, tr *MockAuthTokenRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Missing token",
+			request:       &domain.ResetPasswordRequest{Password: "new password"},
+			mockSetup:     func(ar *MockAuthRepository, tr *MockAuthTokenRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authRepo := new(MockAuthRepository)
+			tokenRepo := new(MockAuthTokenRepository)
+			tt.mockSetup(authRepo, tokenRepo)
+			usecase := NewAuthUsecase(authRepo, tokenRepo, testAuthPolicy(), nil, nil, nil)
+
+			// Act
+			err := usecase.ResetPassword(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			authRepo.AssertExpectations(t)
+			tokenRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	_, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	newPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	oldKey := &utils.SigningKey{ID: "old", Private: oldPrivate, ActiveFrom: now.Add(-48 * time.Hour), RetireAt: now.Add(time.Hour)}
+	newKey := &utils.SigningKey{ID: "new", Private: newPrivate, ActiveFrom: now.Add(-time.Minute)}
+	keys, err := utils.NewKeySet(oldKey, newKey)
+	assert.NoError(t, err)
+
+	previous := &utils.SigningKey{ID: "old", Private: oldPrivate}
+	previousKeys, err := utils.NewKeySet(previous)
+	assert.NoError(t, err)
+	utils.SetKeySet(previousKeys)
+	oldToken, err := utils.GenerateJWT("customer-1", "ann@example.com", domain.RoleCustomer, false)
+	assert.NoError(t, err)
+
+	utils.SetKeySet(keys)
+	defer utils.SetKeySet(testKeys)
+
+	// Act
+	newToken, err := utils.GenerateJWT("customer-1", "ann@example.com", domain.RoleCustomer, false)
+	assert.NoError(t, err)
+	newClaims, newErr := utils.ParseJWT(newToken)
+	oldClaims, oldErr := utils.ParseJWT(oldToken)
+	jwks := keys.JWKS(now)
+	retired := keys.JWKS(now.Add(2 * time.Hour))
+
+	// Assert
+	signer, err := keys.Signer(now)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", signer.ID)
+	assert.NoError(t, newErr)
+	assert.Equal(t, "customer-1", newClaims.Subject)
+	assert.NoError(t, oldErr, "tokens of the old key stay valid until it retires")
+	assert.Equal(t, "customer-1", oldClaims.Subject)
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Algorithm)
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "RS256", jwks.Keys[1].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	assert.Len(t, retired.Keys, 1)
+	assert.Equal(t, "new", retired.Keys[0].KeyID)
+}
@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"intern-project-v2/domain"
+	"time"
 )
 
 var _ domain.CartUsecase = (*cartUsecaseImpl)(nil)
@@ -11,32 +14,36 @@ type cartUsecaseImpl struct {
 	cartRepo     domain.CartRepository
 	productRepo  domain.ProductRepository
 	customerRepo domain.CustomerRepository
+	events       domain.EventPublisher
+	tx           domain.Transactor
 }
 
+// abandonedCartBatch bounds how many carts one DetectAbandoned run reports.
+const abandonedCartBatch = 100
+
 func NewCartUsecase(
 	cartRepo domain.CartRepository,
 	productRepo domain.ProductRepository,
 	customerRepo domain.CustomerRepository,
+	events domain.EventPublisher,
+	tx domain.Transactor,
 ) domain.CartUsecase {
 	return &cartUsecaseImpl{
 		cartRepo:     cartRepo,
 		productRepo:  productRepo,
 		customerRepo: customerRepo,
+		events:       events,
+		tx:           tx,
 	}
 }
 
 func (cu *cartUsecaseImpl) AddToCart(ctx context.Context, customerID string, cartItemReq *domain.CartItemRequest) (*domain.Cart, error) {
-	productInfo, err := cu.productRepo.GetByID(ctx, cartItemReq.ProductID)
-	if err != nil {
+	if err := ensureCustomerExists(ctx, cu.customerRepo, customerID); err != nil {
 		return nil, err
 	}
-
-	cartItem := &domain.CartItem{
-		ProductID:    cartItemReq.ProductID,
-		ProductName:  cartItemReq.ProductName,
-		Quantity:     cartItemReq.Quantity,
-		ProductPrice: productInfo.Price,
-		Subtotal:     float64(cartItemReq.Quantity) * productInfo.Price,
+	cartItem, err := cu.buildCartItem(ctx, cartItemReq)
+	if err != nil {
+		return nil, err
 	}
 	cart, err := cu.cartRepo.AddToCart(ctx, customerID, cartItem)
 	if err != nil {
@@ -54,37 +61,233 @@ func (cu *cartUsecaseImpl) GetCartByCustomerId(ctx context.Context, customerID s
 }
 
 func (cu *cartUsecaseImpl) UpdateCartItem(ctx context.Context, customerID string, cartItemReq *domain.CartItemRequest) (*domain.Cart, error) {
+	cartItem, err := cu.buildCartItem(ctx, cartItemReq)
+	if err != nil {
+		return nil, err
+	}
+	cart, err := cu.cartRepo.UpdateCartItem(ctx, customerID, cartItem)
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (cu *cartUsecaseImpl) RemoveCartItem(ctx context.Context, customerID string, productID string, variantID string) (*domain.Cart, error) {
+	cart, err := cu.cartRepo.RemoveCartItem(ctx, customerID, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (cu *cartUsecaseImpl) ClearCart(ctx context.Context, customerID string) error {
+	err := cu.cartRepo.ClearCart(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DetectAbandoned reports carts that have sat idle for longer than idle with
+// a CartAbandoned event, once per period of inactivity, and returns how many
+// it reported. A cart that changes while it is being looked at is skipped.
+func (cu *cartUsecaseImpl) DetectAbandoned(ctx context.Context, idle time.Duration) (int, error) {
+	carts, err := cu.cartRepo.FindAbandoned(ctx, time.Now().Add(-idle), abandonedCartBatch)
+	if err != nil {
+		return 0, err
+	}
+	reported := 0
+	for _, cart := range carts {
+		var marked bool
+		err := withinTransaction(ctx, cu.tx, func(ctx context.Context) error {
+			var err error
+			marked, err = cu.cartRepo.MarkAbandoned(ctx, cart)
+			if err != nil || !marked {
+				return err
+			}
+			return publish(ctx, cu.events, domain.EventCartAbandoned, cart.Id.Hex(), &domain.CartAbandonedPayload{
+				CartID:     cart.Id.Hex(),
+				CustomerID: cart.CustomerID,
+				TotalItems: cart.TotalItems,
+				TotalPrice: cart.TotalPrice,
+				UpdatedAt:  cart.UpdatedAt,
+			})
+		})
+		if err != nil {
+			return reported, err
+		}
+		if marked {
+			reported++
+		}
+	}
+	return reported, nil
+}
+
+// buildCartItem prices a cart line. Products sold by variant require a valid
+// variant in stock, and the line takes the variant's price and SKU.
+func (cu *cartUsecaseImpl) buildCartItem(ctx context.Context, cartItemReq *domain.CartItemRequest) (*domain.CartItem, error) {
 	productInfo, err := cu.productRepo.GetByID(ctx, cartItemReq.ProductID)
 	if err != nil {
 		return nil, err
 	}
+	variant, err := resolveVariant(productInfo, cartItemReq.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant != nil && variant.Stock < cartItemReq.Quantity {
+		return nil, fmt.Errorf("%w: only %d of variant %s in stock", domain.ErrConflict, variant.Stock, variant.SKU)
+	}
 
+	price := productInfo.PriceOf(variant)
 	cartItem := &domain.CartItem{
 		ProductID:    cartItemReq.ProductID,
 		ProductName:  cartItemReq.ProductName,
 		Quantity:     cartItemReq.Quantity,
-		ProductPrice: productInfo.Price,
-		Subtotal:     float64(cartItemReq.Quantity) * productInfo.Price,
+		ProductPrice: price,
+		Subtotal:     float64(cartItemReq.Quantity) * price,
+	}
+	if variant != nil {
+		cartItem.VariantID = variant.Id
+		cartItem.SKU = variant.SKU
+	}
+	return cartItem, nil
+}
+
+// BatchUpdate applies a whole list of cart changes at once. Every product the
+// request touches is fetched in one batched lookup and the resulting cart is
+// saved in a single write, so either all changes apply or none do.
+func (cu *cartUsecaseImpl) BatchUpdate(ctx context.Context, actor *domain.Actor, customerID string, batchReq *domain.CartBatchRequest) (*domain.Cart, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	if (batchReq.Items == nil) == (batchReq.Operations == nil) {
+		return nil, fmt.Errorf("%w: send either items or operations", domain.ErrInvalidInput)
+	}
+	if err := ensureCustomerExists(ctx, cu.customerRepo, customerID); err != nil {
+		return nil, err
+	}
+
+	cart, err := cu.cartRepo.GetCartByCustomerId(ctx, customerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		cart, err = &domain.Cart{CustomerID: customerID}, nil
 	}
-	cart, err := cu.cartRepo.UpdateCartItem(ctx, customerID, cartItem)
 	if err != nil {
 		return nil, err
 	}
-	return cart, nil
-}
 
-func (cu *cartUsecaseImpl) RemoveCartItem(ctx context.Context, customerID string, productID string) (*domain.Cart, error) {
-	cart, err := cu.cartRepo.RemoveCartItem(ctx, customerID, productID)
+	var productIDs []string
+	for _, item := range batchReq.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	for _, op := range batchReq.Operations {
+		if op.Op != domain.CartOpRemove {
+			productIDs = append(productIDs, op.ProductID)
+		}
+	}
+	products, err := loadProducts(ctx, cu.productRepo, productIDs)
 	if err != nil {
 		return nil, err
 	}
-	return cart, nil
+
+	lines := newCartLines(cart.Items)
+	if batchReq.Items != nil {
+		lines = newCartLines(nil)
+		for _, item := range batchReq.Items {
+			op := &domain.CartOperation{Op: domain.CartOpAdd, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+			if err := lines.apply(op, products[item.ProductID]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, op := range batchReq.Operations {
+		if err := lines.apply(op, products[op.ProductID]); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+
+	cart.Items = lines.items
+	return cu.cartRepo.Save(ctx, cart)
 }
 
-func (cu *cartUsecaseImpl) ClearCart(ctx context.Context, customerID string) error {
-	err := cu.cartRepo.ClearCart(ctx, customerID)
+// cartLines is a cart being edited in memory, keyed by product and variant.
+type cartLines struct {
+	items []*domain.CartItem
+}
+
+func newCartLines(items []*domain.CartItem) *cartLines {
+	return &cartLines{items: append([]*domain.CartItem{}, items...)}
+}
+
+func (l *cartLines) find(productID, variantID string) int {
+	for i, item := range l.items {
+		if item.ProductID == productID && item.VariantID == variantID {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply performs one operation. Lines that are added or updated are validated
+// against the catalog and repriced at the current price.
+func (l *cartLines) apply(op *domain.CartOperation, product *domain.Product) error {
+	if op.Op == domain.CartOpRemove {
+		kept := l.items[:0]
+		removed := false
+		for _, item := range l.items {
+			if item.ProductID == op.ProductID && (op.VariantID == "" || item.VariantID == op.VariantID) {
+				removed = true
+				continue
+			}
+			kept = append(kept, item)
+		}
+		if !removed {
+			return fmt.Errorf("%w: product %s is not in the cart", domain.ErrInvalidInput, op.ProductID)
+		}
+		l.items = kept
+		return nil
+	}
+
+	if op.Op != domain.CartOpAdd && op.Op != domain.CartOpUpdate {
+		return fmt.Errorf("%w: unknown operation %q", domain.ErrInvalidInput, op.Op)
+	}
+	variant, err := resolveVariant(product, op.VariantID)
 	if err != nil {
 		return err
 	}
+	index := l.find(op.ProductID, op.VariantID)
+
+	quantity := op.Quantity
+	switch {
+	case op.Op == domain.CartOpUpdate && index < 0:
+		return fmt.Errorf("%w: product %s is not in the cart", domain.ErrInvalidInput, op.ProductID)
+	case op.Op == domain.CartOpUpdate && quantity == 0:
+		l.items = append(l.items[:index], l.items[index+1:]...)
+		return nil
+	case quantity <= 0:
+		return fmt.Errorf("%w: quantity for product %s must be positive", domain.ErrInvalidInput, op.ProductID)
+	case op.Op == domain.CartOpAdd && index >= 0:
+		quantity += l.items[index].Quantity
+	}
+	if variant != nil && variant.Stock < quantity {
+		return fmt.Errorf("%w: only %d of variant %s in stock", domain.ErrConflict, variant.Stock, variant.SKU)
+	}
+
+	price := product.PriceOf(variant)
+	item := &domain.CartItem{
+		ProductID:    op.ProductID,
+		ProductName:  product.Name,
+		Quantity:     quantity,
+		ProductPrice: price,
+		Subtotal:     float64(quantity) * price,
+	}
+	if variant != nil {
+		item.VariantID = variant.Id
+		item.SKU = variant.SKU
+	}
+	if index >= 0 {
+		l.items[index] = item
+	} else {
+		l.items = append(l.items, item)
+	}
 	return nil
 }
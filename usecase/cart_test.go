@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/events"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) AddToCart(ctx context.Context, customerID string, cartItem *domain.CartItem) (*domain.Cart, error) {
+	args := m.Called(ctx, customerID, cartItem)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) GetCartByCustomerId(ctx context.Context, customerID string) (*domain.Cart, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) UpdateCartItem(ctx context.Context, customerID string, cartItem *domain.CartItem) (*domain.Cart, error) {
+	args := m.Called(ctx, customerID, cartItem)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) RemoveCartItem(ctx context.Context, customerID string, productID string, variantID string) (*domain.Cart, error) {
+	args := m.Called(ctx, customerID, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) ClearCart(ctx context.Context, customerID string) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveProductFromCarts(ctx context.Context, productID string) (int64, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCartRepository) Save(ctx context.Context, cart *domain.Cart) (*domain.Cart, error) {
+	args := m.Called(ctx, cart)
+	if fn, ok := args.Get(0).(func(context.Context, *domain.Cart) *domain.Cart); ok {
+		return fn(ctx, cart), args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) FindAbandoned(ctx context.Context, idleSince time.Time, limit int) ([]*domain.Cart, error) {
+	args := m.Called(ctx, idleSince, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) MarkAbandoned(ctx context.Context, cart *domain.Cart) (bool, error) {
+	args := m.Called(ctx, cart)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCartUsecase_BatchUpdate(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+	owner := &domain.Actor{CustomerID: customerID, Role: domain.RoleCustomer}
+	hat := &domain.Product{Id: bson.NewObjectID(), Name: "Hat", Price: 10}
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 2},
+	}}
+	hatID, shirtID := hat.Id.Hex(), shirt.Id.Hex()
+	existing := func() *domain.Cart {
+		return &domain.Cart{Id: bson.NewObjectID(), CustomerID: customerID, Version: 3, Items: []*domain.CartItem{
+			{ProductID: hatID, ProductName: "Hat", ProductPrice: 8, Quantity: 1},
+		}}
+	}
+
+	tests := []struct {
+		name          string
+		actor         *domain.Actor
+		request       *domain.CartBatchRequest
+		expectedItems map[string]int
+		expectedError error
+	}{
+		{
+			name:  "Success - Items replace the cart",
+			actor: owner,
+			request: &domain.CartBatchRequest{Items: []*domain.CartItemRequest{
+				{ProductID: shirtID, VariantID: "v-m", Quantity: 2},
+			}},
+			expectedItems: map[string]int{shirtID: 2},
+		},
+		{
+			name:          "Success - Empty items clear the cart",
+			actor:         owner,
+			request:       &domain.CartBatchRequest{Items: []*domain.CartItemRequest{}},
+			expectedItems: map[string]int{},
+		},
+		{
+			name:  "Success - Operations apply in order",
+			actor: owner,
+			request: &domain.CartBatchRequest{Operations: []*domain.CartOperation{
+				{Op: domain.CartOpAdd, ProductID: hatID, Quantity: 2},
+				{Op: domain.CartOpAdd, ProductID: shirtID, VariantID: "v-m", Quantity: 1},
+				{Op: domain.CartOpUpdate, ProductID: shirtID, VariantID: "v-m", Quantity: 2},
+			}},
+			expectedItems: map[string]int{hatID: 3, shirtID: 2},
+		},
+		{
+			name:  "Error - Variant out of stock rejects the whole batch",
+			actor: owner,
+			request: &domain.CartBatchRequest{Operations: []*domain.CartOperation{
+				{Op: domain.CartOpRemove, ProductID: hatID},
+				{Op: domain.CartOpAdd, ProductID: shirtID, VariantID: "v-m", Quantity: 3},
+			}},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:          "Error - Both items and operations",
+			actor:         owner,
+			request:       &domain.CartBatchRequest{Items: []*domain.CartItemRequest{}, Operations: []*domain.CartOperation{}},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Another customer's cart",
+			actor:         &domain.Actor{CustomerID: "someone-else", Role: domain.RoleCustomer},
+			request:       &domain.CartBatchRequest{Items: []*domain.CartItemRequest{}},
+			expectedError: domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cartRepo := new(MockCartRepository)
+			productRepo := new(MockProductRepository)
+			customerRepo := new(MockCustomerRepository)
+			customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil).Maybe()
+			cartRepo.On("GetCartByCustomerId", mock.Anything, customerID).Return(existing(), nil).Maybe()
+			productRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*domain.Product{hat, shirt}, nil).Maybe()
+			cartRepo.On("Save", mock.Anything, mock.Anything).Return(func(ctx context.Context, cart *domain.Cart) *domain.Cart {
+				return cart
+			}, nil).Maybe()
+			usecase := NewCartUsecase(cartRepo, productRepo, customerRepo, nil, nil)
+
+			// Act
+			cart, err := usecase.BatchUpdate(context.Background(), tt.actor, customerID, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				cartRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			quantities := map[string]int{}
+			for _, item := range cart.Items {
+				quantities[item.ProductID] += item.Quantity
+			}
+			assert.Equal(t, tt.expectedItems, quantities)
+			productRepo.AssertNumberOfCalls(t, "GetByIDs", 1)
+		})
+	}
+}
+
+func TestCartUsecase_DetectAbandoned(t *testing.T) {
+	// Arrange
+	idle := &domain.Cart{Id: bson.NewObjectID(), CustomerID: "c-1", TotalItems: 2, TotalPrice: 40, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	touched := &domain.Cart{Id: bson.NewObjectID(), CustomerID: "c-2", TotalItems: 1, TotalPrice: 10, UpdatedAt: time.Now().Add(-30 * time.Hour)}
+	cartRepo := new(MockCartRepository)
+	cartRepo.On("FindAbandoned", mock.Anything, mock.MatchedBy(func(idleSince time.Time) bool {
+		return time.Since(idleSince) >= 24*time.Hour
+	}), abandonedCartBatch).Return([]*domain.Cart{idle, touched}, nil)
+	cartRepo.On("MarkAbandoned", mock.Anything, idle).Return(true, nil)
+	// The second customer changed the cart after it was looked up.
+	cartRepo.On("MarkAbandoned", mock.Anything, touched).Return(false, nil)
+	bus := events.NewMemoryBus()
+	usecase := NewCartUsecase(cartRepo, nil, nil, bus, nil)
+
+	// Act
+	reported, err := usecase.DetectAbandoned(context.Background(), 24*time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reported)
+	published := bus.Events(domain.EventCartAbandoned)
+	assert.Len(t, published, 1)
+	assert.Equal(t, idle.Id.Hex(), published[0].AggregateID)
+	assert.JSONEq(t, `{"cart_id":"`+idle.Id.Hex()+`","customer_id":"c-1","total_items":2,"total_price":40,"updated_at":"`+idle.UpdatedAt.Format(time.RFC3339Nano)+`"}`, string(published[0].Payload))
+	cartRepo.AssertExpectations(t)
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxCategoryDepth bounds breadcrumb walks so a corrupted parent chain cannot
+// loop forever.
+const maxCategoryDepth = 32
+
+var _ domain.CategoryUsecase = (*categoryUsecaseImpl)(nil)
+
+type categoryUsecaseImpl struct {
+	categoryRepo domain.CategoryRepository
+	productRepo  domain.ProductRepository
+}
+
+func NewCategoryUsecase(categoryRepo domain.CategoryRepository, productRepo domain.ProductRepository) domain.CategoryUsecase {
+	return &categoryUsecaseImpl{
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+	}
+}
+
+func (cu *categoryUsecaseImpl) GetAll(ctx context.Context) ([]*domain.Category, error) {
+	categories, err := cu.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (cu *categoryUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	category, err := cu.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (cu *categoryUsecaseImpl) Create(ctx context.Context, categoryReq *domain.CategoryRequest) (*domain.Category, error) {
+	category := &domain.Category{CreatedAt: time.Now()}
+	if err := cu.apply(ctx, category, categoryReq); err != nil {
+		return nil, err
+	}
+	return cu.categoryRepo.Create(ctx, category)
+}
+
+// Update replaces the name, slug and parent of a category. An empty
+// parent_id moves the category to the root.
+func (cu *categoryUsecaseImpl) Update(ctx context.Context, id string, categoryReq *domain.CategoryRequest) (*domain.Category, error) {
+	category, err := cu.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if categoryReq.ParentID != "" {
+		if categoryReq.ParentID == id {
+			return nil, fmt.Errorf("%w: a category cannot be its own parent", domain.ErrInvalidInput)
+		}
+		descendants, err := cu.categoryRepo.GetDescendantIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, descendant := range descendants {
+			if descendant == categoryReq.ParentID {
+				return nil, fmt.Errorf("%w: a category cannot be moved under its own descendant", domain.ErrInvalidInput)
+			}
+		}
+	}
+	if err := cu.apply(ctx, category, categoryReq); err != nil {
+		return nil, err
+	}
+	return cu.categoryRepo.Update(ctx, category)
+}
+
+func (cu *categoryUsecaseImpl) Delete(ctx context.Context, id string) (*domain.Category, error) {
+	children, err := cu.categoryRepo.CountChildren(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if children > 0 {
+		return nil, fmt.Errorf("%w: category still has %d subcategories", domain.ErrConflict, children)
+	}
+
+	category, err := cu.categoryRepo.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cu.productRepo.RemoveCategory(ctx, id); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetProducts returns the products of a category and of all its descendants.
+func (cu *categoryUsecaseImpl) GetProducts(ctx context.Context, id string) ([]*domain.Product, error) {
+	descendants, err := cu.categoryRepo.GetDescendantIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	products, err := cu.productRepo.GetByCategoryIDs(ctx, append([]string{id}, descendants...))
+	if err != nil {
+		return nil, err
+	}
+	if err := attachBreadcrumbs(ctx, cu.categoryRepo, products...); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (cu *categoryUsecaseImpl) apply(ctx context.Context, category *domain.Category, categoryReq *domain.CategoryRequest) error {
+	name := strings.TrimSpace(categoryReq.Name)
+	if name == "" {
+		return fmt.Errorf("%w: category name is required", domain.ErrInvalidInput)
+	}
+	category.Name = name
+	category.Slug = slugify(categoryReq.Slug)
+	if category.Slug == "" {
+		category.Slug = slugify(name)
+	}
+
+	category.ParentID = nil
+	if categoryReq.ParentID != "" {
+		parent, err := cu.categoryRepo.GetByID(ctx, categoryReq.ParentID)
+		if err != nil {
+			return fmt.Errorf("%w: parent category %s does not exist", domain.ErrInvalidInput, categoryReq.ParentID)
+		}
+		category.ParentID = &parent.Id
+	}
+	return nil
+}
+
+// loadCategories indexes every category by id. Categories are a small
+// collection, so this single query replaces per-product lookups.
+func loadCategories(ctx context.Context, categoryRepo domain.CategoryRepository) (map[string]*domain.Category, error) {
+	categories, err := categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Category, len(categories))
+	for _, category := range categories {
+		byID[category.Id.Hex()] = category
+	}
+	return byID, nil
+}
+
+// attachBreadcrumbs fills in the root-to-leaf path of every category the
+// products are assigned to.
+func attachBreadcrumbs(ctx context.Context, categoryRepo domain.CategoryRepository, products ...*domain.Product) error {
+	categories, err := loadCategories(ctx, categoryRepo)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		product.Breadcrumbs = nil
+		for _, categoryID := range product.CategoryIds {
+			if trail := breadcrumb(categories, categoryID); len(trail) > 0 {
+				product.Breadcrumbs = append(product.Breadcrumbs, trail)
+			}
+		}
+	}
+	return nil
+}
+
+func breadcrumb(categories map[string]*domain.Category, categoryID string) []*domain.CategoryRef {
+	var trail []*domain.CategoryRef
+	current, ok := categories[categoryID]
+	for depth := 0; ok && depth < maxCategoryDepth; depth++ {
+		trail = append([]*domain.CategoryRef{{
+			Id:   current.Id.Hex(),
+			Name: current.Name,
+			Slug: current.Slug,
+		}}, trail...)
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = categories[current.ParentID.Hex()]
+	}
+	return trail
+}
+
+// ensureCategoriesExist rejects assignments to unknown categories.
+func ensureCategoriesExist(ctx context.Context, categoryRepo domain.CategoryRepository, categoryIDs []string) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	categories, err := loadCategories(ctx, categoryRepo)
+	if err != nil {
+		return err
+	}
+	for _, id := range categoryIDs {
+		if _, ok := categories[id]; !ok {
+			return fmt.Errorf("%w: category %s does not exist", domain.ErrInvalidInput, id)
+		}
+	}
+	return nil
+}
+
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]*domain.Category, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id string) (*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockCategoryRepository) CountChildren(ctx context.Context, id string) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestCategoryUsecase_Update(t *testing.T) {
+	root := &domain.Category{Id: bson.NewObjectID(), Name: "Clothing", Slug: "clothing"}
+	child := &domain.Category{Id: bson.NewObjectID(), Name: "Shirts", Slug: "shirts", ParentID: &root.Id}
+	rootID, childID := root.Id.Hex(), child.Id.Hex()
+
+	tests := []struct {
+		name          string
+		id            string
+		request       *domain.CategoryRequest
+		setupMock     func(*MockCategoryRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Move to root and derive slug",
+			id:      childID,
+			request: &domain.CategoryRequest{Name: "Dress Shirts"},
+			setupMock: func(repo *MockCategoryRepository) {
+				repo.On("GetByID", mock.Anything, childID).Return(child, nil)
+				repo.On("Update", mock.Anything, mock.MatchedBy(func(c *domain.Category) bool {
+					return c.Slug == "dress-shirts" && c.ParentID == nil
+				})).Return(child, nil)
+			},
+		},
+		{
+			name:    "Error - Own parent",
+			id:      rootID,
+			request: &domain.CategoryRequest{Name: "Clothing", ParentID: rootID},
+			setupMock: func(repo *MockCategoryRepository) {
+				repo.On("GetByID", mock.Anything, rootID).Return(root, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:    "Error - Parent is a descendant",
+			id:      rootID,
+			request: &domain.CategoryRequest{Name: "Clothing", ParentID: childID},
+			setupMock: func(repo *MockCategoryRepository) {
+				repo.On("GetByID", mock.Anything, rootID).Return(root, nil)
+				repo.On("GetDescendantIDs", mock.Anything, rootID).Return([]string{childID}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			categoryRepo := new(MockCategoryRepository)
+			tt.setupMock(categoryRepo)
+			usecase := NewCategoryUsecase(categoryRepo, new(MockProductRepository))
+
+			// Act
+			_, err := usecase.Update(context.Background(), tt.id, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			categoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCategoryUsecase_Delete(t *testing.T) {
+	t.Run("Error - Has subcategories", func(t *testing.T) {
+		categoryRepo := new(MockCategoryRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo.On("CountChildren", mock.Anything, "c1").Return(int64(2), nil)
+		usecase := NewCategoryUsecase(categoryRepo, productRepo)
+
+		_, err := usecase.Delete(context.Background(), "c1")
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		productRepo.AssertNotCalled(t, "RemoveCategory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success - Unassigns products", func(t *testing.T) {
+		categoryRepo := new(MockCategoryRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo.On("CountChildren", mock.Anything, "c1").Return(int64(0), nil)
+		categoryRepo.On("Delete", mock.Anything, "c1").Return(&domain.Category{Name: "Hats"}, nil)
+		productRepo.On("RemoveCategory", mock.Anything, "c1").Return(int64(3), nil)
+		usecase := NewCategoryUsecase(categoryRepo, productRepo)
+
+		category, err := usecase.Delete(context.Background(), "c1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hats", category.Name)
+		productRepo.AssertExpectations(t)
+	})
+}
+
+func TestCategoryUsecase_GetProducts(t *testing.T) {
+	root := &domain.Category{Id: bson.NewObjectID(), Name: "Clothing", Slug: "clothing"}
+	child := &domain.Category{Id: bson.NewObjectID(), Name: "Shirts", Slug: "shirts", ParentID: &root.Id}
+	rootID, childID := root.Id.Hex(), child.Id.Hex()
+	product := &domain.Product{Name: "Oxford Shirt", CategoryIds: []string{childID}}
+
+	categoryRepo := new(MockCategoryRepository)
+	productRepo := new(MockProductRepository)
+	categoryRepo.On("GetDescendantIDs", mock.Anything, rootID).Return([]string{childID}, nil)
+	categoryRepo.On("GetAll", mock.Anything).Return([]*domain.Category{root, child}, nil)
+	productRepo.On("GetByCategoryIDs", mock.Anything, []string{rootID, childID}).Return([]*domain.Product{product}, nil)
+	usecase := NewCategoryUsecase(categoryRepo, productRepo)
+
+	products, err := usecase.GetProducts(context.Background(), rootID)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, [][]*domain.CategoryRef{{
+		{Id: rootID, Name: "Clothing", Slug: "clothing"},
+		{Id: childID, Name: "Shirts", Slug: "shirts"},
+	}}, products[0].Breadcrumbs)
+}
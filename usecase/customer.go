@@ -2,18 +2,22 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"intern-project-v2/domain"
+	"time"
 )
 
 var _ domain.CustomerUsecase = (*customerUsecaseImpl)(nil)
 
 type customerUsecaseImpl struct {
 	customerRepo domain.CustomerRepository
+	orderRepo    domain.OrderRepository
 }
 
-func NewCustomerUsecase(customerRepo domain.CustomerRepository) domain.CustomerUsecase {
+func NewCustomerUsecase(customerRepo domain.CustomerRepository, orderRepo domain.OrderRepository) domain.CustomerUsecase {
 	return &customerUsecaseImpl{
 		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
 	}
 }
 func (cu *customerUsecaseImpl) GetAll(ctx context.Context) ([]*domain.Customer, error) {
@@ -49,9 +53,38 @@ func (cu *customerUsecaseImpl) Update(ctx context.Context, id string, customerRe
 }
 
 func (cu *customerUsecaseImpl) Delete(ctx context.Context, id string) (*domain.Customer, error) {
+	openOrders, err := cu.orderRepo.CountOpenByCustomer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if openOrders > 0 {
+		return nil, fmt.Errorf("%w: customer has %d open orders", domain.ErrConflict, openOrders)
+	}
+
 	cus, err := cu.customerRepo.Delete(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	return cus, nil
 }
+
+func (cu *customerUsecaseImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Customer, error) {
+	customers, err := cu.customerRepo.GetAllIncludingDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (cu *customerUsecaseImpl) Restore(ctx context.Context, id string) (*domain.Customer, error) {
+	cus, err := cu.customerRepo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return cus, nil
+}
+
+// Purge permanently removes customers soft-deleted longer than the retention period.
+func (cu *customerUsecaseImpl) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return cu.customerRepo.Purge(ctx, time.Now().Add(-retention))
+}
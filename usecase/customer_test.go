@@ -5,6 +5,7 @@ import (
 	"errors"
 	"intern-project-v2/domain"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,6 +53,37 @@ func (m *MockCustomerRepository) Delete(ctx context.Context, id string) (*domain
 	return args.Get(0).(*domain.Customer), args.Error(1)
 }
 
+func (m *MockCustomerRepository) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Customer, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Restore(ctx context.Context, id string) (*domain.Customer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Anonymize(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*domain.Customer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
 func TestCustomerUsecase_GetAll(t *testing.T) {
 	// Test cases
 	tests := []struct {
@@ -119,7 +151,7 @@ func TestCustomerUsecase_GetAll(t *testing.T) {
 			mockRepo := new(MockCustomerRepository)
 			tt.mockSetup(mockRepo)
 
-			usecase := NewCustomerUsecase(mockRepo)
+			usecase := NewCustomerUsecase(mockRepo, new(MockOrderRepository))
 			ctx := context.Background()
 
 			// Act
@@ -195,7 +227,7 @@ func TestCustomerUsecase_GetByID(t *testing.T) {
 			mockRepo := new(MockCustomerRepository)
 			tt.mockSetup(mockRepo)
 
-			usecase := NewCustomerUsecase(mockRepo)
+			usecase := NewCustomerUsecase(mockRepo, new(MockOrderRepository))
 			ctx := context.Background()
 
 			// Act
@@ -277,7 +309,7 @@ func TestCustomerUsecase_Create(t *testing.T) {
 			mockRepo := new(MockCustomerRepository)
 			tt.mockSetup(mockRepo)
 
-			usecase := NewCustomerUsecase(mockRepo)
+			usecase := NewCustomerUsecase(mockRepo, new(MockOrderRepository))
 			ctx := context.Background()
 
 			// Act
@@ -300,3 +332,58 @@ func TestCustomerUsecase_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomerUsecase_Delete(t *testing.T) {
+	tests := []struct {
+		name          string
+		customerID    string
+		mockSetup     func(*MockCustomerRepository, *MockOrderRepository)
+		expectedError error
+	}{
+		{
+			name:       "Success - Customer without open orders",
+			customerID: "64f1a2b3c4d5e6f7a8b9c0d1",
+			mockSetup: func(mockRepo *MockCustomerRepository, orderRepo *MockOrderRepository) {
+				orderRepo.On("CountOpenByCustomer", mock.Anything, "64f1a2b3c4d5e6f7a8b9c0d1").Return(int64(0), nil)
+				mockRepo.On("Delete", mock.Anything, "64f1a2b3c4d5e6f7a8b9c0d1").Return(&domain.Customer{Name: "John Doe"}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Error - Customer has open orders",
+			customerID: "64f1a2b3c4d5e6f7a8b9c0d1",
+			mockSetup: func(mockRepo *MockCustomerRepository, orderRepo *MockOrderRepository) {
+				orderRepo.On("CountOpenByCustomer", mock.Anything, "64f1a2b3c4d5e6f7a8b9c0d1").Return(int64(2), nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockCustomerRepository)
+			orderRepo := new(MockOrderRepository)
+			tt.mockSetup(mockRepo, orderRepo)
+
+			usecase := NewCustomerUsecase(mockRepo, orderRepo)
+			ctx := context.Background()
+
+			// Act
+			result, err := usecase.Delete(ctx, tt.customerID)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+			orderRepo.AssertExpectations(t)
+		})
+	}
+}
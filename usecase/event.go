@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+)
+
+// withinTransaction runs fn in a transaction. Usecases built without a
+// transactor, such as in tests, run fn directly.
+func withinTransaction(ctx context.Context, tx domain.Transactor, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+	return tx.WithinTransaction(ctx, fn)
+}
+
+// publish creates an event about an aggregate and publishes it. Usecases
+// built without a publisher publish nothing.
+func publish(ctx context.Context, publisher domain.EventPublisher, eventType string, aggregateID string, payload interface{}) error {
+	if publisher == nil {
+		return nil
+	}
+	event, err := domain.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, event)
+}
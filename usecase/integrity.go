@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"strings"
+)
+
+// ensureCustomerExists rejects references to unknown or deleted customers.
+func ensureCustomerExists(ctx context.Context, customerRepo domain.CustomerRepository, customerID string) error {
+	_, err := loadCustomer(ctx, customerRepo, customerID)
+	return err
+}
+
+// loadCustomer fetches the customer a request refers to. An unknown customer
+// is invalid input rather than not found, as it is the request that is wrong.
+func loadCustomer(ctx context.Context, customerRepo domain.CustomerRepository, customerID string) (*domain.Customer, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("%w: customer id is required", domain.ErrInvalidInput)
+	}
+	customer, err := customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: customer %s does not exist", domain.ErrInvalidInput, customerID)
+		}
+		return nil, err
+	}
+	return customer, nil
+}
+
+// loadProducts fetches every referenced product in one batched query and
+// fails if any of them is unknown or deleted. Duplicate ids are allowed.
+func loadProducts(ctx context.Context, productRepo domain.ProductRepository, productIDs []string) (map[string]*domain.Product, error) {
+	unique := make([]string, 0, len(productIDs))
+	seen := make(map[string]bool, len(productIDs))
+	for _, id := range productIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	products, err := productRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Product, len(products))
+	for _, product := range products {
+		byID[product.Id.Hex()] = product
+	}
+
+	var missing []string
+	for _, id := range unique {
+		if _, ok := byID[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: unknown products %s", domain.ErrInvalidInput, strings.Join(missing, ", "))
+	}
+	return byID, nil
+}
+
+// resolveVariant checks a variant reference against a product. Products sold
+// by variant need one of their variants; other products must not name one.
+func resolveVariant(product *domain.Product, variantID string) (*domain.ProductVariant, error) {
+	productID := product.Id.Hex()
+	if !product.HasVariants() {
+		if variantID != "" {
+			return nil, fmt.Errorf("%w: product %s has no variants", domain.ErrInvalidInput, productID)
+		}
+		return nil, nil
+	}
+	if variantID == "" {
+		return nil, fmt.Errorf("%w: a variant of product %s must be chosen", domain.ErrInvalidInput, productID)
+	}
+	variant := product.Variant(variantID)
+	if variant == nil {
+		return nil, fmt.Errorf("%w: product %s has no variant %s", domain.ErrInvalidInput, productID, variantID)
+	}
+	return variant, nil
+}
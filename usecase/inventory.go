@@ -0,0 +1,533 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var _ domain.InventoryUsecase = (*inventoryUsecaseImpl)(nil)
+
+const (
+	defaultMovementLimit = 50
+	maxMovementLimit     = 200
+)
+
+type inventoryUsecaseImpl struct {
+	inventoryRepo domain.InventoryRepository
+	productRepo   domain.ProductRepository
+	warehouseRepo domain.WarehouseRepository
+	restock       domain.RestockListener
+	lowStock      domain.LowStockListener
+	events        domain.EventPublisher
+	tx            domain.Transactor
+}
+
+func NewInventoryUsecase(
+	inventoryRepo domain.InventoryRepository,
+	productRepo domain.ProductRepository,
+	warehouseRepo domain.WarehouseRepository,
+	restock domain.RestockListener,
+	lowStock domain.LowStockListener,
+	events domain.EventPublisher,
+	tx domain.Transactor,
+) domain.InventoryUsecase {
+	return &inventoryUsecaseImpl{
+		inventoryRepo: inventoryRepo,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		restock:       restock,
+		lowStock:      lowStock,
+		events:        events,
+		tx:            tx,
+	}
+}
+
+// Adjust applies a manual stock movement. Adjustments may go either way but
+// need a reason; restocks only add stock.
+func (iu *inventoryUsecaseImpl) Adjust(ctx context.Context, actor *domain.Actor, productID string, adjustmentReq *domain.StockAdjustmentRequest) (*domain.Product, error) {
+	adjustmentReq.Reason = strings.TrimSpace(adjustmentReq.Reason)
+	switch adjustmentReq.Type {
+	case "":
+		adjustmentReq.Type = domain.StockMovementAdjustment
+	case domain.StockMovementAdjustment, domain.StockMovementRestock:
+	default:
+		return nil, fmt.Errorf("%w: type must be adjustment or restock", domain.ErrInvalidInput)
+	}
+	if adjustmentReq.Quantity == 0 {
+		return nil, fmt.Errorf("%w: quantity cannot be zero", domain.ErrInvalidInput)
+	}
+	if adjustmentReq.Type == domain.StockMovementRestock && adjustmentReq.Quantity < 0 {
+		return nil, fmt.Errorf("%w: a restock can only add stock", domain.ErrInvalidInput)
+	}
+	if adjustmentReq.Type == domain.StockMovementAdjustment && adjustmentReq.Reason == "" {
+		return nil, fmt.Errorf("%w: an adjustment needs a reason", domain.ErrInvalidInput)
+	}
+
+	product, err := iu.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveVariant(product, adjustmentReq.VariantID); err != nil {
+		return nil, err
+	}
+	if adjustmentReq.WarehouseID != "" {
+		if _, err := iu.warehouseRepo.GetByID(ctx, adjustmentReq.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+	return iu.move(ctx, &domain.StockMovement{
+		ProductID:   productID,
+		VariantID:   adjustmentReq.VariantID,
+		WarehouseID: adjustmentReq.WarehouseID,
+		Type:        adjustmentReq.Type,
+		Quantity:    adjustmentReq.Quantity,
+		Reason:      adjustmentReq.Reason,
+		Actor:       actor.Email,
+	})
+}
+
+// SetStock moves the stock of a product, or of one variant, to an absolute
+// level and records the difference as an adjustment. Once warehouses hold
+// some of the stock, it can only be changed per warehouse.
+func (iu *inventoryUsecaseImpl) SetStock(ctx context.Context, actor *domain.Actor, productID string, variantID string, stock int, reason string) (*domain.Product, error) {
+	if stock < 0 {
+		return nil, fmt.Errorf("%w: stock cannot be negative", domain.ErrInvalidInput)
+	}
+	product, err := iu.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if variantID == "" && product.HasVariants() {
+		return nil, fmt.Errorf("%w: the stock of product %s is kept per variant", domain.ErrInvalidInput, productID)
+	}
+	if _, err := resolveVariant(product, variantID); err != nil {
+		return nil, err
+	}
+	if holdsStock(product.WarehouseStockOf(variantID)) {
+		return nil, fmt.Errorf("%w: product %s keeps stock in warehouses; adjust the stock of a warehouse instead", domain.ErrInvalidInput, productID)
+	}
+	delta := stock - product.StockOf(variantID)
+	if delta == 0 {
+		return product, nil
+	}
+	return iu.move(ctx, &domain.StockMovement{
+		ProductID: productID,
+		VariantID: variantID,
+		Type:      domain.StockMovementAdjustment,
+		Quantity:  delta,
+		Reason:    reason,
+		Actor:     actor.Email,
+	})
+}
+
+// RecordInitialStock opens the ledger of a new product with the stock it was
+// created with.
+func (iu *inventoryUsecaseImpl) RecordInitialStock(ctx context.Context, product *domain.Product) error {
+	for _, variantID := range stockLines(product) {
+		stock := product.StockOf(variantID)
+		if stock == 0 {
+			continue
+		}
+		err := iu.record(ctx, &domain.StockMovement{
+			ProductID:  product.Id.Hex(),
+			VariantID:  variantID,
+			Type:       domain.StockMovementAdjustment,
+			Quantity:   stock,
+			StockAfter: stock,
+			Reason:     "initial stock",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSale allocates the units of an order to warehouses, following the
+// allocation rule of the order, and takes them out of stock. Either every
+// line is taken, or the lines already taken are put back and the error of
+// the line that was short is returned.
+func (iu *inventoryUsecaseImpl) RecordSale(ctx context.Context, order *domain.Order) ([]*domain.StockAllocation, error) {
+	lines := orderLines(order)
+	productIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	products, err := loadProducts(ctx, iu.productRepo, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	warehouses, err := iu.warehouseRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := order.Id.Hex()
+	var allocations []*domain.StockAllocation
+	var taken []*domain.StockMovement
+	for _, line := range lines {
+		parts, err := allocate(order.Allocation, order.ShipTo, warehouses, products[line.ProductID], line.VariantID, line.Quantity)
+		if err != nil {
+			iu.rollbackSale(ctx, reference, taken)
+			return nil, err
+		}
+		for _, part := range parts {
+			movement := &domain.StockMovement{
+				ProductID:   part.ProductID,
+				VariantID:   part.VariantID,
+				WarehouseID: part.WarehouseID,
+				Type:        domain.StockMovementSale,
+				Quantity:    -part.Quantity,
+				Reference:   reference,
+			}
+			if _, err := iu.move(ctx, movement); err != nil {
+				iu.rollbackSale(ctx, reference, taken)
+				return nil, err
+			}
+			taken = append(taken, movement)
+		}
+		allocations = append(allocations, parts...)
+	}
+	return allocations, nil
+}
+
+func (iu *inventoryUsecaseImpl) rollbackSale(ctx context.Context, reference string, taken []*domain.StockMovement) {
+	for _, sale := range taken {
+		_, err := iu.move(ctx, &domain.StockMovement{
+			ProductID:   sale.ProductID,
+			VariantID:   sale.VariantID,
+			WarehouseID: sale.WarehouseID,
+			Type:        domain.StockMovementAdjustment,
+			Quantity:    -sale.Quantity,
+			Reason:      "sale rolled back",
+			Reference:   reference,
+		})
+		if err != nil {
+			logger.Error("Failed to put back stock of a failed sale", "order_id", reference, "product_id", sale.ProductID, "error", err)
+		}
+	}
+}
+
+// RecordCancellation puts the stock of a cancelled order back where its sale
+// took it from, in one transaction, so the order can only be cancelled once
+// all of it is back.
+func (iu *inventoryUsecaseImpl) RecordCancellation(ctx context.Context, order *domain.Order) error {
+	reference := order.Id.Hex()
+	return withinTransaction(ctx, iu.tx, func(ctx context.Context) error {
+		for _, allocation := range order.Allocations {
+			_, err := iu.move(ctx, &domain.StockMovement{
+				ProductID:   allocation.ProductID,
+				VariantID:   allocation.VariantID,
+				WarehouseID: allocation.WarehouseID,
+				Type:        domain.StockMovementAdjustment,
+				Quantity:    allocation.Quantity,
+				Reason:      "order cancelled",
+				Reference:   reference,
+			})
+			if err != nil {
+				logger.Error("Failed to put back stock of a cancelled order", "order_id", reference, "product_id", allocation.ProductID, "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecordReturn puts the items of an approved return back in stock. Returned
+// units are not assigned to a warehouse until they are transferred to one.
+func (iu *inventoryUsecaseImpl) RecordReturn(ctx context.Context, actor *domain.Actor, ret *domain.Return) error {
+	// All items are restocked in one transaction, so a failure part way
+	// through leaves nothing restocked and the return can be retried.
+	return withinTransaction(ctx, iu.tx, func(ctx context.Context) error {
+		for _, item := range ret.Items {
+			_, err := iu.move(ctx, &domain.StockMovement{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Type:      domain.StockMovementReturn,
+				Quantity:  item.Quantity,
+				Actor:     actor.Email,
+				Reference: ret.Id.Hex(),
+			})
+			if err != nil {
+				logger.Error("Failed to restock returned product", "return_id", ret.Id.Hex(), "product_id", item.ProductID, "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (iu *inventoryUsecaseImpl) GetMovements(ctx context.Context, query *domain.StockMovementQuery) (*domain.StockMovementResult, error) {
+	if query.Type != "" && !slices.Contains(domain.StockMovementTypes, query.Type) {
+		return nil, fmt.Errorf("%w: unknown movement type %q", domain.ErrInvalidInput, query.Type)
+	}
+	query.Page = clampPage(query.Page)
+	query.Limit = clampLimit(query.Limit, defaultMovementLimit, maxMovementLimit)
+
+	movements, total, err := iu.inventoryRepo.GetMovements(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StockMovementResult{
+		Movements: movements,
+		Total:     total,
+		Page:      query.Page,
+		Limit:     query.Limit,
+	}, nil
+}
+
+// Verify compares the stock of a product with the sum of its ledger. Stock
+// written outside the ledger, such as by imports or by products created
+// before it existed, shows up as a difference.
+func (iu *inventoryUsecaseImpl) Verify(ctx context.Context, productID string) (*domain.StockVerification, error) {
+	product, err := iu.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := iu.inventoryRepo.Totals(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	verification := &domain.StockVerification{ProductID: productID, Consistent: true}
+	for _, variantID := range stockLines(product) {
+		level := &domain.StockLevel{
+			VariantID:   variantID,
+			SKU:         product.SKU,
+			Stock:       product.StockOf(variantID),
+			LedgerStock: totals[variantID],
+		}
+		if variant := product.Variant(variantID); variant != nil {
+			level.SKU = variant.SKU
+		}
+		level.Difference = level.Stock - level.LedgerStock
+		if level.Difference != 0 {
+			verification.Consistent = false
+		}
+		verification.Levels = append(verification.Levels, level)
+	}
+	return verification, nil
+}
+
+// Reconcile records an adjustment for every difference Verify finds, taking
+// the product stock as the truth. The stock itself is not changed.
+func (iu *inventoryUsecaseImpl) Reconcile(ctx context.Context, actor *domain.Actor, productID string) (*domain.StockVerification, error) {
+	verification, err := iu.Verify(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range verification.Levels {
+		if level.Difference == 0 {
+			continue
+		}
+		err := iu.record(ctx, &domain.StockMovement{
+			ProductID:  productID,
+			VariantID:  level.VariantID,
+			Type:       domain.StockMovementAdjustment,
+			Quantity:   level.Difference,
+			StockAfter: level.Stock,
+			Reason:     "reconciled with the product stock",
+			Actor:      actor.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Reconciled stock ledger", "product_id", productID, "variant_id", level.VariantID, "difference", level.Difference)
+		level.LedgerStock = level.Stock
+		level.Difference = 0
+	}
+	verification.Consistent = true
+	return verification, nil
+}
+
+// Transfer moves stock of a product, or of one variant, between warehouses
+// and records it as a pair of transfer movements sharing a reference. The
+// total stock does not change.
+func (iu *inventoryUsecaseImpl) Transfer(ctx context.Context, actor *domain.Actor, productID string, transferReq *domain.StockTransferRequest) (*domain.Product, error) {
+	transferReq.Reason = strings.TrimSpace(transferReq.Reason)
+	if transferReq.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", domain.ErrInvalidInput)
+	}
+	if transferReq.To == "" {
+		return nil, fmt.Errorf("%w: a transfer needs a destination warehouse", domain.ErrInvalidInput)
+	}
+	if transferReq.From == transferReq.To {
+		return nil, fmt.Errorf("%w: cannot transfer stock to the warehouse it is in", domain.ErrInvalidInput)
+	}
+	product, err := iu.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveVariant(product, transferReq.VariantID); err != nil {
+		return nil, err
+	}
+	for _, warehouseID := range []string{transferReq.From, transferReq.To} {
+		if warehouseID == "" {
+			continue
+		}
+		if _, err := iu.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = withinTransaction(ctx, iu.tx, func(ctx context.Context) error {
+		var err error
+		product, err = iu.productRepo.TransferStock(ctx, productID, transferReq.VariantID, transferReq.From, transferReq.To, transferReq.Quantity)
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: not enough stock of product %s to transfer", domain.ErrConflict, productID)
+		}
+		if err != nil {
+			return err
+		}
+
+		reference := bson.NewObjectID().Hex()
+		stock := product.StockOf(transferReq.VariantID)
+		for _, leg := range []struct {
+			warehouseID string
+			quantity    int
+		}{{transferReq.From, -transferReq.Quantity}, {transferReq.To, transferReq.Quantity}} {
+			movement := &domain.StockMovement{
+				ProductID:   productID,
+				VariantID:   transferReq.VariantID,
+				WarehouseID: leg.warehouseID,
+				Type:        domain.StockMovementTransfer,
+				Quantity:    leg.quantity,
+				StockAfter:  stock,
+				Reason:      transferReq.Reason,
+				Actor:       actor.Email,
+				Reference:   reference,
+			}
+			if err := iu.record(ctx, movement); err != nil {
+				logger.Error("Failed to record stock transfer", "product_id", productID, "reference", reference, "error", err)
+				return err
+			}
+			if err := iu.publishMovement(ctx, movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Transferred stock", "product_id", productID, "variant_id", transferReq.VariantID, "from", transferReq.From, "to", transferReq.To, "quantity", transferReq.Quantity)
+	return product, nil
+}
+
+func (iu *inventoryUsecaseImpl) SetReorderThreshold(ctx context.Context, productID string, thresholdReq *domain.ReorderThresholdRequest) (*domain.Product, error) {
+	if thresholdReq.Threshold != nil && *thresholdReq.Threshold < 0 {
+		return nil, fmt.Errorf("%w: threshold cannot be negative", domain.ErrInvalidInput)
+	}
+	return iu.productRepo.SetReorderThreshold(ctx, productID, thresholdReq.Threshold)
+}
+
+// move applies a movement to the stock, appends it to the ledger and
+// publishes ProductStockChanged, all in one transaction. Stock never goes
+// below zero; a movement that would take it there fails with ErrConflict.
+func (iu *inventoryUsecaseImpl) move(ctx context.Context, movement *domain.StockMovement) (*domain.Product, error) {
+	var product *domain.Product
+	err := withinTransaction(ctx, iu.tx, func(ctx context.Context) error {
+		var err error
+		product, err = iu.apply(ctx, movement)
+		if err != nil {
+			return err
+		}
+		return iu.publishMovement(ctx, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	iu.notify(ctx, product, movement)
+	return product, nil
+}
+
+func (iu *inventoryUsecaseImpl) apply(ctx context.Context, movement *domain.StockMovement) (*domain.Product, error) {
+	var product *domain.Product
+	var err error
+	if movement.WarehouseID != "" {
+		product, err = iu.productRepo.AdjustWarehouseStock(ctx, movement.ProductID, movement.VariantID, movement.WarehouseID, movement.Quantity)
+	} else if movement.VariantID != "" {
+		product, err = iu.productRepo.AdjustVariantStock(ctx, movement.ProductID, movement.VariantID, movement.Quantity)
+	} else {
+		product, err = iu.productRepo.AdjustStock(ctx, movement.ProductID, movement.Quantity)
+	}
+	if errors.Is(err, domain.ErrNotFound) && movement.Quantity < 0 {
+		return nil, fmt.Errorf("%w: not enough stock of product %s", domain.ErrConflict, movement.ProductID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	movement.StockAfter = product.StockOf(movement.VariantID)
+	if err := iu.record(ctx, movement); err != nil {
+		// Failing the transaction puts the stock back, so the ledger never
+		// misses a change.
+		logger.Error("Failed to record stock movement", "product_id", movement.ProductID, "quantity", movement.Quantity, "error", err)
+		return nil, err
+	}
+	return product, nil
+}
+
+func (iu *inventoryUsecaseImpl) publishMovement(ctx context.Context, movement *domain.StockMovement) error {
+	return publish(ctx, iu.events, domain.EventProductStockChanged, movement.ProductID, &domain.ProductStockChangedPayload{
+		ProductID:   movement.ProductID,
+		VariantID:   movement.VariantID,
+		WarehouseID: movement.WarehouseID,
+		Movement:    movement.Type,
+		Quantity:    movement.Quantity,
+		Stock:       movement.StockAfter,
+	})
+}
+
+func (iu *inventoryUsecaseImpl) record(ctx context.Context, movement *domain.StockMovement) error {
+	movement.CreatedAt = time.Now()
+	_, err := iu.inventoryRepo.Record(ctx, movement)
+	return err
+}
+
+// notify tells the restock listener about stock coming back from zero and the
+// low stock listener about stock dropping to the reorder threshold.
+func (iu *inventoryUsecaseImpl) notify(ctx context.Context, product *domain.Product, movement *domain.StockMovement) {
+	if movement.Quantity > 0 {
+		if restock := restockedBy(product, movement.VariantID, movement.Quantity); restock != nil && iu.restock != nil {
+			iu.restock.Restocked(ctx, restock)
+		}
+		return
+	}
+	threshold := product.ReorderPoint()
+	if iu.lowStock == nil || movement.StockAfter > threshold || movement.StockAfter-movement.Quantity <= threshold {
+		return
+	}
+	alert := &domain.LowStockAlert{
+		Product:   product,
+		VariantID: movement.VariantID,
+		SKU:       product.SKU,
+		Stock:     movement.StockAfter,
+		Threshold: threshold,
+		Movement:  movement,
+	}
+	if variant := product.Variant(movement.VariantID); variant != nil {
+		alert.SKU = variant.SKU
+	}
+	iu.lowStock.LowStock(ctx, alert)
+}
+
+// stockLines lists the keys stock is kept under: every variant of a product
+// sold by variant, otherwise just the product itself.
+func stockLines(product *domain.Product) []string {
+	if !product.HasVariants() {
+		return []string{""}
+	}
+	lines := make([]string, 0, len(product.Variants))
+	for _, variant := range product.Variants {
+		lines = append(lines, variant.Id)
+	}
+	return lines
+}
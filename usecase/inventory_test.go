@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"intern-project-v2/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockInventoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryRepository) Record(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, error) {
+	args := m.Called(ctx, movement)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockMovement), args.Error(1)
+}
+
+func (m *MockInventoryRepository) GetMovements(ctx context.Context, query *domain.StockMovementQuery) ([]*domain.StockMovement, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.StockMovement), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInventoryRepository) Totals(ctx context.Context, productID string) (map[string]int, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+type MockLowStockListener struct {
+	mock.Mock
+}
+
+func (m *MockLowStockListener) LowStock(ctx context.Context, alert *domain.LowStockAlert) {
+	m.Called(ctx, alert)
+}
+
+func TestInventoryUsecase_Adjust(t *testing.T) {
+	admin := &domain.Actor{CustomerID: "admin-1", Email: "admin@example.com", Role: domain.RoleAdmin}
+	threshold := 3
+	product := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 4, ReorderThreshold: &threshold}
+	productID := product.Id.Hex()
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name          string
+		request       *domain.StockAdjustmentRequest
+		mockSetup     func(*MockInventoryRepository, *MockProductRepository, *MockLowStockListener)
+		expectedError error
+	}{
+		{
+			name:    "Success - Dropping to the threshold raises an alert",
+			request: &domain.StockAdjustmentRequest{Quantity: -2, Reason: " damaged in storage "},
+			mockSetup: func(ir *MockInventoryRepository, pr *MockProductRepository, ls *MockLowStockListener) {
+				pr.On("GetByID", mock.Anything, productID).Return(product, nil)
+				pr.On("AdjustStock", mock.Anything, productID, -2).Return(&domain.Product{Id: product.Id, Stock: 2, ReorderThreshold: &threshold}, nil)
+				ir.On("Record", mock.Anything, mock.MatchedBy(func(movement *domain.StockMovement) bool {
+					return movement.Type == domain.StockMovementAdjustment && movement.StockAfter == 2 &&
+						movement.Reason == "damaged in storage" && movement.Actor == "admin@example.com"
+				})).Return(&domain.StockMovement{}, nil)
+				ls.On("LowStock", mock.Anything, mock.MatchedBy(func(alert *domain.LowStockAlert) bool {
+					return alert.Stock == 2 && alert.Threshold == 3
+				})).Once()
+			},
+		},
+		{
+			name:    "Error - Taking more than is in stock",
+			request: &domain.StockAdjustmentRequest{Quantity: -10, Reason: "stocktake"},
+			mockSetup: func(ir *MockInventoryRepository, pr *MockProductRepository, ls *MockLowStockListener) {
+				pr.On("GetByID", mock.Anything, productID).Return(product, nil)
+				pr.On("AdjustStock", mock.Anything, productID, -10).Return(nil, domain.ErrNotFound)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:    "Error - Ledger write fails",
+			request: &domain.StockAdjustmentRequest{Quantity: 1, Reason: "found"},
+			mockSetup: func(ir *MockInventoryRepository, pr *MockProductRepository, ls *MockLowStockListener) {
+				pr.On("GetByID", mock.Anything, productID).Return(product, nil)
+				pr.On("AdjustStock", mock.Anything, productID, 1).Return(&domain.Product{Id: product.Id, Stock: 5}, nil)
+				ir.On("Record", mock.Anything, mock.AnythingOfType("*domain.StockMovement")).Return(nil, errWrite)
+			},
+			expectedError: errWrite,
+		},
+		{
+			name:          "Error - Adjustment without a reason",
+			request:       &domain.StockAdjustmentRequest{Quantity: 5},
+			mockSetup:     func(ir *MockInventoryRepository, pr *MockProductRepository, ls *MockLowStockListener) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Restock that removes stock",
+			request:       &domain.StockAdjustmentRequest{Type: domain.StockMovementRestock, Quantity: -1},
+			mockSetup:     func(ir *MockInventoryRepository, pr *MockProductRepository, ls *MockLowStockListener) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			inventoryRepo := new(MockInventoryRepository)
+			productRepo := new(MockProductRepository)
+			lowStock := new(MockLowStockListener)
+			tt.mockSetup(inventoryRepo, productRepo, lowStock)
+			usecase := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, lowStock, nil, nil)
+
+			// Act
+			result, err := usecase.Adjust(context.Background(), admin, productID, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				inventoryRepo.AssertExpectations(t)
+				lowStock.AssertNotCalled(t, "LowStock", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			inventoryRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+			lowStock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInventoryUsecase_SetStock(t *testing.T) {
+	admin := &domain.Actor{CustomerID: "admin-1", Email: "admin@example.com", Role: domain.RoleAdmin}
+	productID := bson.NewObjectID()
+
+	tests := []struct {
+		name          string
+		product       *domain.Product
+		variantID     string
+		expectedError error
+	}{
+		{
+			name:    "Success - Stock outside warehouses",
+			product: &domain.Product{Id: productID, Stock: 4},
+		},
+		{
+			name:          "Error - Product keeps stock in a warehouse",
+			product:       &domain.Product{Id: productID, Stock: 4, WarehouseStock: map[string]int{"w1": 3}},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name: "Error - Variant keeps stock in a warehouse",
+			product: &domain.Product{Id: productID, Stock: 4, Variants: []*domain.ProductVariant{
+				{Id: "v1", Stock: 4, WarehouseStock: map[string]int{"w1": 4}},
+			}},
+			variantID:     "v1",
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			inventoryRepo := new(MockInventoryRepository)
+			productRepo := new(MockProductRepository)
+			productRepo.On("GetByID", mock.Anything, productID.Hex()).Return(tt.product, nil)
+			if tt.expectedError == nil {
+				productRepo.On("AdjustStock", mock.Anything, productID.Hex(), 6).Return(&domain.Product{Id: productID, Stock: 10}, nil)
+				inventoryRepo.On("Record", mock.Anything, mock.AnythingOfType("*domain.StockMovement")).Return(&domain.StockMovement{}, nil)
+			}
+			usecase := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.SetStock(context.Background(), admin, productID.Hex(), tt.variantID, 10, "stocktake")
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				productRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything, mock.Anything)
+				productRepo.AssertNotCalled(t, "AdjustVariantStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 10, result.Stock)
+			productRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInventoryUsecase_Reconcile(t *testing.T) {
+	// Arrange
+	admin := &domain.Actor{Email: "admin@example.com", Role: domain.RoleAdmin}
+	product := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 3},
+		{Id: "v-l", SKU: "SHIRT-L", Stock: 2},
+	}}
+	productID := product.Id.Hex()
+	inventoryRepo := new(MockInventoryRepository)
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", mock.Anything, productID).Return(product, nil)
+	inventoryRepo.On("Totals", mock.Anything, productID).Return(map[string]int{"v-m": 3, "v-l": 7}, nil)
+	inventoryRepo.On("Record", mock.Anything, mock.MatchedBy(func(movement *domain.StockMovement) bool {
+		return movement.VariantID == "v-l" && movement.Quantity == -5 && movement.StockAfter == 2
+	})).Return(&domain.StockMovement{}, nil).Once()
+	usecase := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, nil, nil, nil)
+
+	// Act
+	before, err := usecase.Verify(context.Background(), productID)
+	assert.NoError(t, err)
+	after, reconcileErr := usecase.Reconcile(context.Background(), admin, productID)
+
+	// Assert
+	assert.False(t, before.Consistent)
+	assert.Equal(t, -5, before.Levels[1].Difference)
+	assert.Equal(t, "SHIRT-L", before.Levels[1].SKU)
+	assert.NoError(t, reconcileErr)
+	assert.True(t, after.Consistent)
+	inventoryRepo.AssertExpectations(t)
+}
+
+func TestInventoryUsecase_Transfer(t *testing.T) {
+	admin := &domain.Actor{Email: "admin@example.com", Role: domain.RoleAdmin}
+	bangkok := &domain.Warehouse{Id: bson.NewObjectID(), Code: "BKK", Active: true}
+	chiangMai := &domain.Warehouse{Id: bson.NewObjectID(), Code: "CNX", Active: true}
+	product := &domain.Product{Id: bson.NewObjectID(), Name: "Lamp", Price: 30, Stock: 10, WarehouseStock: map[string]int{bangkok.Id.Hex(): 6}}
+	productID := product.Id.Hex()
+
+	t.Run("Success - Records both legs under one reference", func(t *testing.T) {
+		// Arrange
+		inventoryRepo := new(MockInventoryRepository)
+		productRepo := new(MockProductRepository)
+		warehouseRepo := new(MockWarehouseRepository)
+		productRepo.On("GetByID", mock.Anything, productID).Return(product, nil)
+		warehouseRepo.On("GetByID", mock.Anything, bangkok.Id.Hex()).Return(bangkok, nil)
+		warehouseRepo.On("GetByID", mock.Anything, chiangMai.Id.Hex()).Return(chiangMai, nil)
+		productRepo.On("TransferStock", mock.Anything, productID, "", bangkok.Id.Hex(), chiangMai.Id.Hex(), 4).Return(product, nil)
+		var legs []*domain.StockMovement
+		inventoryRepo.On("Record", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			legs = append(legs, args.Get(1).(*domain.StockMovement))
+		}).Return(&domain.StockMovement{}, nil).Twice()
+		usecase := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil)
+
+		// Act
+		result, err := usecase.Transfer(context.Background(), admin, productID, &domain.StockTransferRequest{
+			From: bangkok.Id.Hex(), To: chiangMai.Id.Hex(), Quantity: 4,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Len(t, legs, 2)
+		assert.Equal(t, -4, legs[0].Quantity)
+		assert.Equal(t, bangkok.Id.Hex(), legs[0].WarehouseID)
+		assert.Equal(t, chiangMai.Id.Hex(), legs[1].WarehouseID)
+		assert.Equal(t, legs[0].Reference, legs[1].Reference)
+		assert.Equal(t, domain.StockMovementTransfer, legs[1].Type)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error - Not enough stock in the source", func(t *testing.T) {
+		// Arrange
+		inventoryRepo := new(MockInventoryRepository)
+		productRepo := new(MockProductRepository)
+		warehouseRepo := new(MockWarehouseRepository)
+		productRepo.On("GetByID", mock.Anything, productID).Return(product, nil)
+		warehouseRepo.On("GetByID", mock.Anything, chiangMai.Id.Hex()).Return(chiangMai, nil)
+		productRepo.On("TransferStock", mock.Anything, productID, "", "", chiangMai.Id.Hex(), 5).Return(nil, domain.ErrNotFound)
+		usecase := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil)
+
+		// Act
+		result, err := usecase.Transfer(context.Background(), admin, productID, &domain.StockTransferRequest{
+			To: chiangMai.Id.Hex(), Quantity: 5,
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, result)
+		inventoryRepo.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - Transfer to the same warehouse", func(t *testing.T) {
+		usecase := NewInventoryUsecase(nil, nil, nil, nil, nil, nil, nil)
+
+		result, err := usecase.Transfer(context.Background(), admin, productID, &domain.StockTransferRequest{
+			From: bangkok.Id.Hex(), To: bangkok.Id.Hex(), Quantity: 1,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"intern-project-v2/totp"
+	"intern-project-v2/utils"
+	"strings"
+	"time"
+)
+
+// totpSkew accepts codes of the periods next to the current one, for clocks
+// that drift apart.
+const totpSkew = 1
+
+var (
+	// errWrongCode is returned when an MFA change is confirmed with a wrong
+	// code.
+	errWrongCode = fmt.Errorf("%w: authentication code is incorrect", domain.ErrForbidden)
+	// errBadChallenge does not say whether the challenge or the code was
+	// wrong.
+	errBadChallenge = fmt.Errorf("%w: invalid or expired challenge or code", domain.ErrUnauthorized)
+	errMFARequired  = fmt.Errorf("%w: two-factor authentication is mandatory for admins", domain.ErrForbidden)
+	errMFAEnabled   = fmt.Errorf("%w: two-factor authentication is already on", domain.ErrConflict)
+	errMFADisabled  = fmt.Errorf("%w: two-factor authentication is off", domain.ErrConflict)
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// challenge answers the first login step of a customer with two-factor
+// authentication.
+func (au *authUsecaseImpl) challenge(cust *domain.Customer) (*domain.LoginResult, error) {
+	token, err := utils.GeneratePurposeJWT(cust.Id.Hex(), domain.TokenMFAChallenge, au.policy.MFAChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(au.policy.MFAChallengeTTL)
+	return &domain.LoginResult{MFARequired: true, ChallengeToken: token, ChallengeExpiresAt: &expiresAt}, nil
+}
+
+// VerifyMFA is the second login step: it exchanges the challenge token and a
+// code for a login token.
+func (au *authUsecaseImpl) VerifyMFA(ctx context.Context, req *domain.MFAVerifyRequest) (*domain.LoginResult, error) {
+	customerID, err := utils.ParsePurposeJWT(req.ChallengeToken, domain.TokenMFAChallenge)
+	if err != nil {
+		return nil, errBadChallenge
+	}
+	cust, err := au.authRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, errBadChallenge
+		}
+		return nil, err
+	}
+	if !cust.MFAEnabled {
+		return nil, errBadChallenge
+	}
+	ok, err := verifySecondFactor(ctx, au.authRepo, cust, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		logger.Warn("Failed second login step", "customer_id", customerID)
+		return nil, errBadChallenge
+	}
+	session, err := newSession(cust, true)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Customer: cust, Token: session.Token}, nil
+}
+
+// EnrollMFA starts turning on two-factor authentication. The new secret only
+// takes effect once ConfirmMFA proves the authenticator app has it.
+func (au *accountUsecaseImpl) EnrollMFA(ctx context.Context, actor *domain.Actor, req *domain.MFAEnrollRequest) (*domain.MFAEnrollment, error) {
+	cust, err := au.confirm(ctx, actor, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+	if cust.MFAEnabled {
+		return nil, errMFAEnabled
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := au.authRepo.SetPendingMFASecret(ctx, actor.CustomerID, secret); err != nil {
+		return nil, err
+	}
+	return &domain.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.URI(au.policy.MFAIssuer, cust.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA turns on two-factor authentication with a code for the secret
+// from EnrollMFA and hands out the recovery codes.
+func (au *accountUsecaseImpl) ConfirmMFA(ctx context.Context, actor *domain.Actor, req *domain.MFACodeRequest) (*domain.MFAActivation, error) {
+	cust, err := au.authRepo.GetByID(ctx, actor.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if cust.MFAEnabled {
+		return nil, errMFAEnabled
+	}
+	if cust.MFAPendingSecret == "" {
+		return nil, fmt.Errorf("%w: start the enrollment first", domain.ErrInvalidInput)
+	}
+	step, ok := totp.Match(cust.MFAPendingSecret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		return nil, errWrongCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := au.authRepo.EnableMFA(ctx, actor.CustomerID, cust.MFAPendingSecret, hashes, step); err != nil {
+		return nil, err
+	}
+	session, err := newSession(cust, true)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.MFAActivation{RecoveryCodes: codes, Token: session.Token}, nil
+}
+
+// DisableMFA turns two-factor authentication off. Admins cannot.
+func (au *accountUsecaseImpl) DisableMFA(ctx context.Context, actor *domain.Actor, req *domain.MFADisableRequest) error {
+	if actor.Role == domain.RoleAdmin {
+		return errMFARequired
+	}
+	cust, err := au.confirm(ctx, actor, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	if !cust.MFAEnabled {
+		return errMFADisabled
+	}
+	ok, err := verifySecondFactor(ctx, au.authRepo, cust, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errWrongCode
+	}
+	return au.authRepo.DisableMFA(ctx, actor.CustomerID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code with new ones.
+func (au *accountUsecaseImpl) RegenerateRecoveryCodes(ctx context.Context, actor *domain.Actor, req *domain.MFACodeRequest) (*domain.MFARecoveryCodes, error) {
+	cust, err := au.authRepo.GetByID(ctx, actor.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if !cust.MFAEnabled {
+		return nil, errMFADisabled
+	}
+	ok, err := verifySecondFactor(ctx, au.authRepo, cust, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errWrongCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := au.authRepo.SetRecoveryCodes(ctx, actor.CustomerID, hashes); err != nil {
+		return nil, err
+	}
+	return &domain.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// verifySecondFactor checks a TOTP code or, failing that, a recovery code,
+// and uses it up. Each code works only once.
+func verifySecondFactor(ctx context.Context, authRepo domain.AuthRepository, cust *domain.Customer, code string) (bool, error) {
+	if code = strings.TrimSpace(code); code == "" {
+		return false, nil
+	}
+	if cust.MFASecret == "" {
+		return false, nil
+	}
+	if step, ok := totp.Match(cust.MFASecret, code, time.Now(), totpSkew); ok {
+		err := authRepo.UseTOTPStep(ctx, cust.Id.Hex(), step)
+		if errors.Is(err, domain.ErrConflict) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	err := authRepo.UseRecoveryCode(ctx, cust.Id.Hex(), hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err == nil {
+		logger.Info("Recovery code used", "customer_id", cust.Id.Hex(), "remaining", len(cust.MFARecoveryCodes)-1)
+	}
+	return err == nil, err
+}
+
+// newRecoveryCodes returns a set of recovery codes to show the customer and
+// the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, domain.RecoveryCodeCount)
+	hashes := make([]string, domain.RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes, which are easily
+// typed differently.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base32"
+	"intern-project-v2/domain"
+	"intern-project-v2/totp"
+	"intern-project-v2/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestTOTP_RFC6238(t *testing.T) {
+	// The SHA-1 test vectors of RFC 6238, appendix B, cut to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func mfaCustomer(t *testing.T, role string) *domain.Customer {
+	secret, err := totp.NewSecret()
+	assert.NoError(t, err)
+	customer := &domain.Customer{
+		Id:               bson.NewObjectID(),
+		Email:            "ann@example.com",
+		Password:         "correct horse",
+		Role:             role,
+		MFAEnabled:       true,
+		MFASecret:        secret,
+		MFARecoveryCodes: []string{hashToken("abcd2345")},
+	}
+	assert.NoError(t, customer.HashPassword())
+	return customer
+}
+
+func currentCode(t *testing.T, secret string) (string, int64) {
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	assert.NoError(t, err)
+	return code, step
+}
+
+func TestAuthUsecase_LoginWithMFA(t *testing.T) {
+	t.Run("Success - Second factor is challenged before a token is issued", func(t *testing.T) {
+		// Arrange
+		customer := mfaCustomer(t, domain.RoleCustomer)
+		authRepo := new(MockAuthRepository)
+		authRepo.On("Login", mock.Anything, customer.Email).Return(customer, nil)
+		usecase := NewAuthUsecase(authRepo, nil, testAuthPolicy(), nil, nil, nil)
+
+		// Act
+		result, err := usecase.Login(context.Background(), &domain.CustomerLogin{Email: customer.Email, Password: "correct horse"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, result.MFARequired)
+		assert.Empty(t, result.Token)
+		assert.Nil(t, result.Customer)
+		customerID, err := utils.ParsePurposeJWT(result.ChallengeToken, domain.TokenMFAChallenge)
+		assert.NoError(t, err)
+		assert.Equal(t, customer.Id.Hex(), customerID)
+		_, err = utils.ParseJWT(result.ChallengeToken)
+		assert.Error(t, err, "a challenge must not pass as a login token")
+	})
+
+	t.Run("Success - Admin without second factor is told to enroll", func(t *testing.T) {
+		// Arrange
+		customer := &domain.Customer{Id: bson.NewObjectID(), Email: "admin@example.com", Password: "correct horse", Role: domain.RoleAdmin}
+		assert.NoError(t, customer.HashPassword())
+		authRepo := new(MockAuthRepository)
+		authRepo.On("Login", mock.Anything, customer.Email).Return(customer, nil)
+		usecase := NewAuthUsecase(authRepo, nil, testAuthPolicy(), nil, nil, nil)
+
+		// Act
+		result, err := usecase.Login(context.Background(), &domain.CustomerLogin{Email: customer.Email, Password: "correct horse"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, result.MFAEnrollmentRequired)
+		claims, err := utils.ParseJWT(result.Token)
+		assert.NoError(t, err)
+		assert.False(t, claims.MFA)
+	})
+}
+
+func TestAuthUsecase_VerifyMFA(t *testing.T) {
+	customer := mfaCustomer(t, domain.RoleAdmin)
+	challenge, err := utils.GeneratePurposeJWT(customer.Id.Hex(), domain.TokenMFAChallenge, time.Minute)
+	assert.NoError(t, err)
+	code, step := currentCode(t, customer.MFASecret)
+
+	tests := []struct {
+		name          string
+		request       *domain.MFAVerifyRequest
+		mockSetup     func(*MockAuthRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Authenticator code",
+			request: &domain.MFAVerifyRequest{ChallengeToken: challenge, Code: code},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				ar.On("UseTOTPStep", mock.Anything, customer.Id.Hex(), step).Return(nil)
+			},
+		},
+		{
+			name:    "Success - Recovery code",
+			request: &domain.MFAVerifyRequest{ChallengeToken: challenge, Code: "ABCD-2345"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				ar.On("UseRecoveryCode", mock.Anything, customer.Id.Hex(), hashToken("abcd2345")).Return(nil)
+			},
+		},
+		{
+			name:    "Error - Code already used",
+			request: &domain.MFAVerifyRequest{ChallengeToken: challenge, Code: code},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				ar.On("UseTOTPStep", mock.Anything, customer.Id.Hex(), step).Return(domain.ErrConflict)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:    "Error - Unknown recovery code",
+			request: &domain.MFAVerifyRequest{ChallengeToken: challenge, Code: "zzzz-zzzz"},
+			mockSetup: func(ar *MockAuthRepository) {
+				ar.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				ar.On("UseRecoveryCode", mock.Anything, customer.Id.Hex(), mock.Anything).Return(domain.ErrNotFound)
+			},
+			expectedError: domain.ErrUnauthorized,
+		},
+		{
+			name:          "Error - Login token instead of a challenge",
+			request:       &domain.MFAVerifyRequest{ChallengeToken: mustLoginToken(t, customer), Code: code},
+			mockSetup:     func(ar *MockAuthRepository) {},
+			expectedError: domain.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authRepo := new(MockAuthRepository)
+			tt.mockSetup(authRepo)
+			usecase := NewAuthUsecase(authRepo, nil, testAuthPolicy(), nil, nil, nil)
+
+			// Act
+			result, err := usecase.VerifyMFA(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				claims, err := utils.ParseJWT(result.Token)
+				assert.NoError(t, err)
+				assert.True(t, claims.MFA)
+				assert.Equal(t, domain.RoleAdmin, claims.Role)
+			}
+			authRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func mustLoginToken(t *testing.T, customer *domain.Customer) string {
+	token, err := utils.GenerateJWT(customer.Id.Hex(), customer.Email, customer.Role, false)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAccountUsecase_EnrollAndConfirmMFA(t *testing.T) {
+	customer := accountCustomer(t)
+	actor := &domain.Actor{CustomerID: customer.Id.Hex(), Email: customer.Email, Role: domain.RoleCustomer}
+
+	// Arrange
+	authRepo := new(MockAuthRepository)
+	authRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+	authRepo.On("SetPendingMFASecret", mock.Anything, customer.Id.Hex(), mock.Anything).
+		Run(func(args mock.Arguments) { customer.MFAPendingSecret = args.String(2) }).
+		Return(nil)
+	usecase := NewAccountUsecase(authRepo, nil, nil, testAuthPolicy(), nil, nil)
+
+	// Act
+	enrollment, err := usecase.EnrollMFA(context.Background(), actor, &domain.MFAEnrollRequest{CurrentPassword: "current password"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, customer.MFAPendingSecret, enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/Order%20Management:ann@example.com?"))
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	t.Run("Error - Wrong code", func(t *testing.T) {
+		_, err := usecase.ConfirmMFA(context.Background(), actor, &domain.MFACodeRequest{Code: "000000x"})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("Success - Recovery codes are shown once and stored hashed", func(t *testing.T) {
+		code, step := currentCode(t, enrollment.Secret)
+		var stored []string
+		authRepo.On("EnableMFA", mock.Anything, customer.Id.Hex(), enrollment.Secret, mock.Anything, step).
+			Run(func(args mock.Arguments) { stored = args.Get(3).([]string) }).
+			Return(nil)
+
+		activation, err := usecase.ConfirmMFA(context.Background(), actor, &domain.MFACodeRequest{Code: code})
+
+		assert.NoError(t, err)
+		assert.Len(t, activation.RecoveryCodes, domain.RecoveryCodeCount)
+		assert.Len(t, stored, domain.RecoveryCodeCount)
+		assert.Equal(t, hashToken(normalizeRecoveryCode(activation.RecoveryCodes[0])), stored[0])
+		assert.NotContains(t, stored, activation.RecoveryCodes[0])
+		claims, err := utils.ParseJWT(activation.Token)
+		assert.NoError(t, err)
+		assert.True(t, claims.MFA)
+	})
+}
+
+func TestAccountUsecase_DisableMFA_AdminsCannot(t *testing.T) {
+	authRepo := new(MockAuthRepository)
+	usecase := NewAccountUsecase(authRepo, nil, nil, testAuthPolicy(), nil, nil)
+	actor := &domain.Actor{CustomerID: bson.NewObjectID().Hex(), Role: domain.RoleAdmin, MFA: true}
+
+	err := usecase.DisableMFA(context.Background(), actor, &domain.MFADisableRequest{CurrentPassword: "current password", Code: "123456"})
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	authRepo.AssertNotCalled(t, "DisableMFA", mock.Anything, mock.Anything)
+}
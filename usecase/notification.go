@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+)
+
+// notify emails a customer. Emails are a courtesy, so failing to queue one
+// is logged rather than failing the request; usecases built without a
+// notifier send nothing.
+func notify(ctx context.Context, notifier domain.Notifier, to string, template string, data interface{}) {
+	if notifier == nil || to == "" {
+		return
+	}
+	if err := notifier.Notify(ctx, to, template, data); err != nil {
+		logger.Error("Failed to queue email", "template", template, "to", to, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"intern-project-v2/domain"
+	"intern-project-v2/notification"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailTemplates(t *testing.T) {
+	placedAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		template        string
+		data            interface{}
+		expectedSubject string
+		expectedBody    []string
+	}{
+		{
+			name:            "Welcome - Name is escaped in the body but not the subject",
+			template:        domain.EmailWelcome,
+			data:            &domain.WelcomeEmail{Name: "Tom & <b>Jerry</b>"},
+			expectedSubject: "Welcome to Order Management, Tom & <b>Jerry</b>",
+			expectedBody:    []string{"Tom &amp; &lt;b&gt;Jerry&lt;/b&gt;"},
+		},
+		{
+			name:     "Order confirmation - Lines and total",
+			template: domain.EmailOrderConfirmation,
+			data: &domain.OrderConfirmationEmail{
+				Name:        "Ann",
+				OrderID:     "abc123",
+				Items:       []*domain.OrderItem{{ProductID: "p1", SKU: "SHIRT-M", Quantity: 2, UnitPrice: 10}},
+				TotalAmount: 20,
+				PlacedAt:    placedAt,
+			},
+			expectedSubject: "Order abc123 confirmed",
+			expectedBody:    []string{"SHIRT-M", "20.00", "1 Mar 2026 09:30"},
+		},
+		{
+			name:            "Password reset - Link",
+			template:        domain.EmailPasswordReset,
+			data:            &domain.PasswordResetEmail{Name: "Ann", ResetURL: "https://shop.example.com/reset?token=t1", ExpiresAt: placedAt},
+			expectedSubject: "Reset your password",
+			expectedBody:    []string{`href="https://shop.example.com/reset?token=t1"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			email, err := notification.Render("ann@example.com", tt.template, tt.data)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, "ann@example.com", email.To)
+			assert.Equal(t, tt.expectedSubject, email.Subject)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, email.HTML, expected)
+			}
+		})
+	}
+
+	_, err := notification.Render("ann@example.com", "unknown", nil)
+	assert.Error(t, err)
+}
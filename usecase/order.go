@@ -2,18 +2,53 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"slices"
+	"time"
 )
 
 var _ domain.OrderUsecase = (*orderUsecaseImpl)(nil)
 
+const (
+	defaultOrderHistoryLimit = 20
+	maxOrderHistoryLimit     = 100
+)
+
 type orderUsecaseImpl struct {
-	orderRepo domain.OrderRepository
+	orderRepo    domain.OrderRepository
+	customerRepo domain.CustomerRepository
+	productRepo  domain.ProductRepository
+	cartUsecase  domain.CartUsecase
+	inventory    domain.InventoryUsecase
+	policy       *domain.AuthPolicy
+	notifier     domain.Notifier
+	events       domain.EventPublisher
+	tx           domain.Transactor
 }
 
-func NewOrderUsecase(orderRepo domain.OrderRepository) domain.OrderUsecase {
+func NewOrderUsecase(
+	orderRepo domain.OrderRepository,
+	customerRepo domain.CustomerRepository,
+	productRepo domain.ProductRepository,
+	cartUsecase domain.CartUsecase,
+	inventory domain.InventoryUsecase,
+	policy *domain.AuthPolicy,
+	notifier domain.Notifier,
+	events domain.EventPublisher,
+	tx domain.Transactor,
+) domain.OrderUsecase {
 	return &orderUsecaseImpl{
-		orderRepo: orderRepo,
+		orderRepo:    orderRepo,
+		customerRepo: customerRepo,
+		productRepo:  productRepo,
+		cartUsecase:  cartUsecase,
+		inventory:    inventory,
+		policy:       policy,
+		notifier:     notifier,
+		events:       events,
+		tx:           tx,
 	}
 }
 func (ou *orderUsecaseImpl) GetAll(ctx context.Context) ([]*domain.Order, error) {
@@ -31,23 +66,265 @@ func (ou *orderUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.Ord
 	return order, nil
 }
 func (ou *orderUsecaseImpl) Create(ctx context.Context, order *domain.OrderRequest) (*domain.Order, error) {
+	if len(order.ProductIds) == 0 && len(order.Items) == 0 {
+		return nil, fmt.Errorf("%w: an order needs at least one product", domain.ErrInvalidInput)
+	}
+	customer, err := loadCustomer(ctx, ou.customerRepo, order.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	if ou.policy != nil && ou.policy.RequireVerifiedEmail && !customer.EmailVerified {
+		return nil, fmt.Errorf("%w: verify your email address before placing an order", domain.ErrForbidden)
+	}
+	if err := validateAllocation(order); err != nil {
+		return nil, err
+	}
+	if err := ou.resolveLines(ctx, order); err != nil {
+		return nil, err
+	}
+
+	var ord *domain.Order
+	err = withinTransaction(ctx, ou.tx, func(ctx context.Context) error {
+		var err error
+		ord, err = ou.place(ctx, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	notify(ctx, ou.notifier, customer.Email, domain.EmailOrderConfirmation, &domain.OrderConfirmationEmail{
+		Name:        customer.Name,
+		OrderID:     ord.Id.Hex(),
+		Items:       ord.Items,
+		TotalAmount: ord.TotalAmount,
+		PlacedAt:    ord.CreatedAt,
+	})
+	return ord, nil
+}
+
+// place saves an order, takes its stock and publishes OrderPlaced.
+func (ou *orderUsecaseImpl) place(ctx context.Context, order *domain.OrderRequest) (*domain.Order, error) {
 	ord, err := ou.orderRepo.Create(ctx, order)
 	if err != nil {
 		return nil, err
 	}
+	allocations, err := ou.inventory.RecordSale(ctx, ord)
+	if err != nil {
+		// An order whose stock could not be taken cannot be fulfilled, so it
+		// is withdrawn again.
+		if _, deleteErr := ou.orderRepo.Delete(ctx, ord.Id.Hex()); deleteErr != nil {
+			logger.Error("Failed to withdraw order without stock", "order_id", ord.Id.Hex(), "error", deleteErr)
+		}
+		return nil, err
+	}
+	ord.Allocations = allocations
+	if err := ou.orderRepo.SetAllocations(ctx, ord.Id.Hex(), allocations); err != nil {
+		// The stock is taken either way; the ledger still says where from.
+		logger.Error("Failed to save order allocations", "order_id", ord.Id.Hex(), "error", err)
+	}
+	err = publish(ctx, ou.events, domain.EventOrderPlaced, ord.Id.Hex(), &domain.OrderPlacedPayload{
+		OrderID:     ord.Id.Hex(),
+		CustomerID:  ord.CustomerId,
+		TotalAmount: ord.TotalAmount,
+		ProductIDs:  ord.ProductIds,
+		Items:       ord.Items,
+		Allocations: allocations,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return ord, nil
 }
 func (ou *orderUsecaseImpl) Update(ctx context.Context, id string, orderReq *domain.OrderRequest) (*domain.Order, error) {
+	if orderReq.CustomerId != "" {
+		if err := ensureCustomerExists(ctx, ou.customerRepo, orderReq.CustomerId); err != nil {
+			return nil, err
+		}
+	}
+	// The lines decide the stock the order holds. An order that needs other
+	// products is cancelled and placed again.
+	if len(orderReq.ProductIds) > 0 || len(orderReq.Items) > 0 {
+		return nil, fmt.Errorf("%w: the lines of an order cannot be changed", domain.ErrInvalidInput)
+	}
 	ord, err := ou.orderRepo.Update(ctx, id, orderReq)
 	if err != nil {
 		return nil, err
 	}
 	return ord, nil
 }
+
+// Delete cancels an order, puts its stock back and publishes OrderCancelled.
+// Orders with refunds are refused: their returned units are already back in
+// stock and would be counted twice.
 func (ou *orderUsecaseImpl) Delete(ctx context.Context, id string) (*domain.Order, error) {
-	ord, err := ou.orderRepo.Delete(ctx, id)
+	current, err := ou.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.RefundedAmount > 0 {
+		return nil, fmt.Errorf("%w: order %s has refunds and cannot be cancelled", domain.ErrConflict, id)
+	}
+
+	var ord *domain.Order
+	err = withinTransaction(ctx, ou.tx, func(ctx context.Context) error {
+		var err error
+		ord, err = ou.orderRepo.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := ou.inventory.RecordCancellation(ctx, ord); err != nil {
+			// An order whose stock could not be put back still holds it.
+			if _, restoreErr := ou.orderRepo.Restore(ctx, id); restoreErr != nil {
+				logger.Error("Failed to bring back order whose stock was not put back", "order_id", id, "error", restoreErr)
+			}
+			return err
+		}
+		return publish(ctx, ou.events, domain.EventOrderCancelled, ord.Id.Hex(), &domain.OrderCancelledPayload{
+			OrderID:     ord.Id.Hex(),
+			CustomerID:  ord.CustomerId,
+			TotalAmount: ord.TotalAmount,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 	return ord, nil
 }
+
+func (ou *orderUsecaseImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Order, error) {
+	orders, err := ou.orderRepo.GetAllIncludingDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Restore brings back a cancelled order and takes its stock again. An order
+// whose stock has been sold in the meantime stays cancelled.
+func (ou *orderUsecaseImpl) Restore(ctx context.Context, id string) (*domain.Order, error) {
+	var ord *domain.Order
+	err := withinTransaction(ctx, ou.tx, func(ctx context.Context) error {
+		var err error
+		ord, err = ou.orderRepo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+		allocations, err := ou.inventory.RecordSale(ctx, ord)
+		if err != nil {
+			if _, deleteErr := ou.orderRepo.Delete(ctx, id); deleteErr != nil {
+				logger.Error("Failed to cancel again order without stock", "order_id", id, "error", deleteErr)
+			}
+			return err
+		}
+		ord.Allocations = allocations
+		if err := ou.orderRepo.SetAllocations(ctx, id, allocations); err != nil {
+			// The stock is taken either way; the ledger still says where from.
+			logger.Error("Failed to save order allocations", "order_id", id, "error", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+// Purge permanently removes orders soft-deleted longer than the retention period.
+func (ou *orderUsecaseImpl) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return ou.orderRepo.Purge(ctx, time.Now().Add(-retention))
+}
+
+// resolveLines validates the products of an order. Items are checked against
+// the chosen variants and priced from the catalog, then mirrored into
+// ProductIds, one entry per unit, and the total. Plain product ids become
+// items first, so every order keeps the prices it was placed at.
+func (ou *orderUsecaseImpl) resolveLines(ctx context.Context, orderReq *domain.OrderRequest) error {
+	if len(orderReq.Items) == 0 {
+		// Plain product ids cannot say which variant to take stock from, so
+		// their products must not have variants.
+		index := make(map[string]*domain.OrderItem)
+		for _, productID := range orderReq.ProductIds {
+			if item, ok := index[productID]; ok {
+				item.Quantity++
+				continue
+			}
+			index[productID] = &domain.OrderItem{ProductID: productID, Quantity: 1}
+			orderReq.Items = append(orderReq.Items, index[productID])
+		}
+	}
+
+	productIDs := make([]string, 0, len(orderReq.Items))
+	for _, item := range orderReq.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity for product %s must be positive", domain.ErrInvalidInput, item.ProductID)
+		}
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := loadProducts(ctx, ou.productRepo, productIDs)
+	if err != nil {
+		return err
+	}
+
+	orderReq.ProductIds = nil
+	orderReq.TotalAmount = 0
+	for _, item := range orderReq.Items {
+		product := products[item.ProductID]
+		variant, err := resolveVariant(product, item.VariantID)
+		if err != nil {
+			return err
+		}
+		item.SKU = ""
+		if variant != nil {
+			item.SKU = variant.SKU
+		}
+		item.UnitPrice = product.PriceOf(variant)
+		orderReq.TotalAmount += item.UnitPrice * float64(item.Quantity)
+		for i := 0; i < item.Quantity; i++ {
+			orderReq.ProductIds = append(orderReq.ProductIds, item.ProductID)
+		}
+	}
+	return nil
+}
+
+// GetByCustomer pages through one customer's order history. Customers may
+// only read their own orders.
+func (ou *orderUsecaseImpl) GetByCustomer(ctx context.Context, actor *domain.Actor, query *domain.OrderHistoryQuery) (*domain.OrderHistoryResult, error) {
+	if !actor.IsAdmin() && actor.CustomerID != query.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	query.Page = clampPage(query.Page)
+	if query.Limit < 1 {
+		query.Limit = defaultOrderHistoryLimit
+	}
+	if query.Limit > maxOrderHistoryLimit {
+		query.Limit = maxOrderHistoryLimit
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, fmt.Errorf("%w: from is after to", domain.ErrInvalidInput)
+	}
+	if (query.MinAmount != nil && *query.MinAmount < 0) || (query.MaxAmount != nil && *query.MaxAmount < 0) {
+		return nil, fmt.Errorf("%w: amounts cannot be negative", domain.ErrInvalidInput)
+	}
+	if query.MinAmount != nil && query.MaxAmount != nil && *query.MinAmount > *query.MaxAmount {
+		return nil, fmt.Errorf("%w: min_amount is greater than max_amount", domain.ErrInvalidInput)
+	}
+	for _, status := range query.Statuses {
+		if !slices.Contains(domain.OrderStatuses, status) {
+			return nil, fmt.Errorf("%w: unknown order status %q", domain.ErrInvalidInput, status)
+		}
+	}
+	if _, err := ou.customerRepo.GetByID(ctx, query.CustomerID); err != nil {
+		return nil, err
+	}
+
+	orders, total, err := ou.orderRepo.GetByCustomer(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OrderHistoryResult{
+		Orders: orders,
+		Total:  total,
+		Page:   query.Page,
+		Limit:  query.Limit,
+	}, nil
+}
@@ -0,0 +1,713 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/events"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestOrderUsecase_Create(t *testing.T) {
+	productA := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5}
+	productB := &domain.Product{Id: bson.NewObjectID(), Name: "Hat", Price: 10, Stock: 5}
+	large := 25.0
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 3},
+		{Id: "v-l", SKU: "SHIRT-L", Price: &large, Stock: 3},
+	}}
+	bangkok := &domain.Warehouse{Id: bson.NewObjectID(), Code: "BKK", Active: true, Location: &domain.GeoPoint{Latitude: 13.75, Longitude: 100.5}}
+	chiangMai := &domain.Warehouse{Id: bson.NewObjectID(), Code: "CNX", Active: true, Location: &domain.GeoPoint{Latitude: 18.8, Longitude: 98.98}}
+	stocked := &domain.Product{Id: bson.NewObjectID(), Name: "Lamp", Price: 30, Stock: 12, WarehouseStock: map[string]int{
+		bangkok.Id.Hex():   3,
+		chiangMai.Id.Hex(): 9,
+	}}
+	customerID := bson.NewObjectID().Hex()
+
+	tests := []struct {
+		name          string
+		orderReq      *domain.OrderRequest
+		mockSetup     func(*MockOrderRepository, *MockCustomerRepository, *MockProductRepository)
+		expectedError error
+	}{
+		{
+			name: "Success - Products are looked up in one batch",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				ProductIds: []string{productA.Id.Hex(), productB.Id.Hex(), productA.Id.Hex()},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, []string{productA.Id.Hex(), productB.Id.Hex()}).
+					Return([]*domain.Product{productA, productB}, nil).Twice()
+				// The prices are kept as items, as if the order had been placed with them.
+				or.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.OrderRequest) bool {
+					return len(req.Items) == 2 && req.Items[0].Quantity == 2 && req.Items[0].UnitPrice == 20 &&
+						req.Items[1].Quantity == 1 && req.Items[1].UnitPrice == 10 && req.TotalAmount == 50
+				})).Return(&domain.Order{
+					Id:         bson.NewObjectID(),
+					ProductIds: []string{productA.Id.Hex(), productB.Id.Hex(), productA.Id.Hex()},
+				}, nil)
+				pr.On("AdjustStock", mock.Anything, productA.Id.Hex(), -2).Return(productA, nil)
+				pr.On("AdjustStock", mock.Anything, productB.Id.Hex(), -1).Return(productB, nil)
+				or.On("SetAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "Success - Items are priced from the chosen variant",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				Items:      []*domain.OrderItem{{ProductID: shirt.Id.Hex(), VariantID: "v-l", Quantity: 2, UnitPrice: 1}},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+				or.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.OrderRequest) bool {
+					item := req.Items[0]
+					return item.UnitPrice == 25 && item.SKU == "SHIRT-L" && req.TotalAmount == 50 && len(req.ProductIds) == 2
+				})).Return(&domain.Order{
+					Id:    bson.NewObjectID(),
+					Items: []*domain.OrderItem{{ProductID: shirt.Id.Hex(), VariantID: "v-l", Quantity: 2, UnitPrice: 25}},
+				}, nil)
+				pr.On("AdjustVariantStock", mock.Anything, shirt.Id.Hex(), "v-l", -2).Return(shirt, nil)
+				or.On("SetAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "Success - Split shipment takes the nearest warehouse first",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				Items:      []*domain.OrderItem{{ProductID: stocked.Id.Hex(), Quantity: 5}},
+				ShipTo:     &domain.GeoPoint{Latitude: 13.7, Longitude: 100.5},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, []string{stocked.Id.Hex()}).Return([]*domain.Product{stocked}, nil)
+				order := &domain.Order{
+					Id:         bson.NewObjectID(),
+					Items:      []*domain.OrderItem{{ProductID: stocked.Id.Hex(), Quantity: 5}},
+					Allocation: domain.AllocationSplit,
+					ShipTo:     &domain.GeoPoint{Latitude: 13.7, Longitude: 100.5},
+				}
+				or.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.OrderRequest) bool {
+					return req.Allocation == domain.AllocationSplit
+				})).Return(order, nil)
+				pr.On("AdjustWarehouseStock", mock.Anything, stocked.Id.Hex(), "", bangkok.Id.Hex(), -3).Return(stocked, nil)
+				pr.On("AdjustWarehouseStock", mock.Anything, stocked.Id.Hex(), "", chiangMai.Id.Hex(), -2).Return(stocked, nil)
+				or.On("SetAllocations", mock.Anything, order.Id.Hex(), mock.MatchedBy(func(allocations []*domain.StockAllocation) bool {
+					return len(allocations) == 2 && allocations[0].WarehouseID == bangkok.Id.Hex() && allocations[1].Quantity == 2
+				})).Return(nil)
+			},
+		},
+		{
+			name: "Error - Nearest allocation without a destination",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				ProductIds: []string{productA.Id.Hex()},
+				Allocation: domain.AllocationNearest,
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name: "Error - Variant product ordered without a variant",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				Items:      []*domain.OrderItem{{ProductID: shirt.Id.Hex(), Quantity: 1}},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name: "Error - Variant product ordered by product id",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				ProductIds: []string{shirt.Id.Hex()},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:     "Error - Unknown customer",
+			orderReq: &domain.OrderRequest{CustomerId: customerID, ProductIds: []string{productA.Id.Hex()}},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(nil, domain.ErrNotFound)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name: "Error - Unknown product",
+			orderReq: &domain.OrderRequest{
+				CustomerId: customerID,
+				ProductIds: []string{productA.Id.Hex(), "missing"},
+			},
+			mockSetup: func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {
+				cr.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+				pr.On("GetByIDs", mock.Anything, mock.Anything).Return([]*domain.Product{productA}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Empty order",
+			orderReq:      &domain.OrderRequest{CustomerId: customerID},
+			mockSetup:     func(or *MockOrderRepository, cr *MockCustomerRepository, pr *MockProductRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			customerRepo := new(MockCustomerRepository)
+			productRepo := new(MockProductRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			tt.mockSetup(orderRepo, customerRepo, productRepo)
+			warehouseRepo := new(MockWarehouseRepository)
+			inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil).Maybe()
+			warehouseRepo.On("GetAll", mock.Anything).Return([]*domain.Warehouse{bangkok, chiangMai}, nil).Maybe()
+
+			inventory := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil)
+			usecase := NewOrderUsecase(orderRepo, customerRepo, productRepo, nil, inventory, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.Create(context.Background(), tt.orderReq)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+			}
+
+			orderRepo.AssertExpectations(t)
+			customerRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderUsecase_CreateWithoutStock(t *testing.T) {
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5}
+	// The last hat is sold to someone else between the lookup and the sale.
+	hat := &domain.Product{Id: bson.NewObjectID(), Name: "Hat", Price: 10, Stock: 1}
+	customerID := bson.NewObjectID().Hex()
+	order := &domain.Order{
+		Id:         bson.NewObjectID(),
+		CustomerId: customerID,
+		ProductIds: []string{shirt.Id.Hex(), hat.Id.Hex()},
+	}
+
+	// Arrange
+	orderRepo := new(MockOrderRepository)
+	customerRepo := new(MockCustomerRepository)
+	productRepo := new(MockProductRepository)
+	inventoryRepo := new(MockInventoryRepository)
+	warehouseRepo := new(MockWarehouseRepository)
+	customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+	productRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*domain.Product{shirt, hat}, nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything).Return(order, nil)
+	productRepo.On("AdjustStock", mock.Anything, shirt.Id.Hex(), -1).Return(shirt, nil)
+	productRepo.On("AdjustStock", mock.Anything, hat.Id.Hex(), -1).Return(nil, domain.ErrNotFound)
+	productRepo.On("AdjustStock", mock.Anything, shirt.Id.Hex(), 1).Return(shirt, nil)
+	inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil)
+	warehouseRepo.On("GetAll", mock.Anything).Return(nil, nil)
+	orderRepo.On("Delete", mock.Anything, order.Id.Hex()).Return(order, nil)
+	usecase := NewOrderUsecase(orderRepo, customerRepo, productRepo, nil, NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil), nil, nil, nil, nil)
+
+	// Act
+	result, err := usecase.Create(context.Background(), &domain.OrderRequest{CustomerId: customerID, ProductIds: order.ProductIds})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Nil(t, result)
+	orderRepo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+	inventoryRepo.AssertNumberOfCalls(t, "Record", 2)
+}
+
+func TestOrderUsecase_PublishesEvents(t *testing.T) {
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5}
+	customerID := bson.NewObjectID().Hex()
+	order := &domain.Order{
+		Id:          bson.NewObjectID(),
+		CustomerId:  customerID,
+		ProductIds:  []string{shirt.Id.Hex(), shirt.Id.Hex()},
+		TotalAmount: 40,
+	}
+
+	// Arrange
+	orderRepo := new(MockOrderRepository)
+	customerRepo := new(MockCustomerRepository)
+	productRepo := new(MockProductRepository)
+	inventoryRepo := new(MockInventoryRepository)
+	warehouseRepo := new(MockWarehouseRepository)
+	customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil)
+	productRepo.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+	warehouseRepo.On("GetAll", mock.Anything).Return(nil, nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything).Return(order, nil)
+	productRepo.On("AdjustStock", mock.Anything, shirt.Id.Hex(), -2).Return(&domain.Product{Id: shirt.Id, Stock: 3}, nil)
+	inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil)
+	orderRepo.On("SetAllocations", mock.Anything, order.Id.Hex(), mock.Anything).Return(nil)
+	orderRepo.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+	orderRepo.On("Delete", mock.Anything, order.Id.Hex()).Return(order, nil)
+	productRepo.On("AdjustStock", mock.Anything, shirt.Id.Hex(), 2).Return(&domain.Product{Id: shirt.Id, Stock: 5}, nil)
+	bus := events.NewMemoryBus()
+	inventory := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, bus, nil)
+	usecase := NewOrderUsecase(orderRepo, customerRepo, productRepo, nil, inventory, nil, nil, bus, nil)
+
+	// Act
+	_, createErr := usecase.Create(context.Background(), &domain.OrderRequest{CustomerId: customerID, ProductIds: order.ProductIds})
+	_, deleteErr := usecase.Delete(context.Background(), order.Id.Hex())
+
+	// Assert
+	assert.NoError(t, createErr)
+	assert.NoError(t, deleteErr)
+	published := bus.Events()
+	assert.Len(t, published, 4)
+	assert.Equal(t, []string{domain.EventProductStockChanged, domain.EventOrderPlaced, domain.EventProductStockChanged, domain.EventOrderCancelled},
+		[]string{published[0].Type, published[1].Type, published[2].Type, published[3].Type})
+	assert.JSONEq(t, `{"product_id":"`+shirt.Id.Hex()+`","movement":"sale","quantity":-2,"stock":3}`, string(published[0].Payload))
+	assert.Equal(t, order.Id.Hex(), published[1].AggregateID)
+}
+
+func TestOrderUsecase_Delete(t *testing.T) {
+	bangkok, chiangMai := bson.NewObjectID().Hex(), bson.NewObjectID().Hex()
+	lamp := &domain.Product{Id: bson.NewObjectID(), Name: "Lamp", Price: 30, Stock: 12}
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Variants: []*domain.ProductVariant{{Id: "v-l", SKU: "SHIRT-L", Stock: 3}}}
+	order := &domain.Order{
+		Id:          bson.NewObjectID(),
+		CustomerId:  bson.NewObjectID().Hex(),
+		TotalAmount: 170,
+		Allocations: []*domain.StockAllocation{
+			{WarehouseID: bangkok, ProductID: lamp.Id.Hex(), Quantity: 3},
+			{WarehouseID: chiangMai, ProductID: lamp.Id.Hex(), Quantity: 2},
+			{ProductID: shirt.Id.Hex(), VariantID: "v-l", Quantity: 1},
+		},
+	}
+	refunded := &domain.Order{Id: order.Id, CustomerId: order.CustomerId, TotalAmount: 170, RefundedAmount: 30, Allocations: order.Allocations}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(*MockOrderRepository, *MockProductRepository)
+		expectedError error
+	}{
+		{
+			name: "Success - Stock goes back where the sale took it from",
+			mockSetup: func(or *MockOrderRepository, pr *MockProductRepository) {
+				or.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+				or.On("Delete", mock.Anything, order.Id.Hex()).Return(order, nil)
+				pr.On("AdjustWarehouseStock", mock.Anything, lamp.Id.Hex(), "", bangkok, 3).Return(lamp, nil)
+				pr.On("AdjustWarehouseStock", mock.Anything, lamp.Id.Hex(), "", chiangMai, 2).Return(lamp, nil)
+				pr.On("AdjustVariantStock", mock.Anything, shirt.Id.Hex(), "v-l", 1).Return(shirt, nil)
+			},
+		},
+		{
+			name: "Error - Orders with refunds are refused",
+			mockSetup: func(or *MockOrderRepository, pr *MockProductRepository) {
+				or.On("GetByID", mock.Anything, order.Id.Hex()).Return(refunded, nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name: "Error - An order whose stock cannot be put back is brought back",
+			mockSetup: func(or *MockOrderRepository, pr *MockProductRepository) {
+				or.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+				or.On("Delete", mock.Anything, order.Id.Hex()).Return(order, nil)
+				pr.On("AdjustWarehouseStock", mock.Anything, lamp.Id.Hex(), "", bangkok, 3).Return(nil, domain.ErrNotFound)
+				or.On("Restore", mock.Anything, order.Id.Hex()).Return(order, nil)
+			},
+			expectedError: domain.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			productRepo := new(MockProductRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			tt.mockSetup(orderRepo, productRepo)
+			inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil).Maybe()
+			inventory := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, nil, nil, nil)
+			usecase := NewOrderUsecase(orderRepo, nil, productRepo, nil, inventory, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.Delete(context.Background(), order.Id.Hex())
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, order, result)
+				inventoryRepo.AssertNumberOfCalls(t, "Record", 3)
+			}
+			orderRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderUsecase_Restore(t *testing.T) {
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5}
+	order := &domain.Order{
+		Id:          bson.NewObjectID(),
+		CustomerId:  bson.NewObjectID().Hex(),
+		ProductIds:  []string{shirt.Id.Hex(), shirt.Id.Hex()},
+		TotalAmount: 40,
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(*MockOrderRepository, *MockProductRepository)
+		expectedError error
+	}{
+		{
+			name: "Success - The stock is taken again",
+			mockSetup: func(or *MockOrderRepository, pr *MockProductRepository) {
+				or.On("Restore", mock.Anything, order.Id.Hex()).Return(order, nil)
+				pr.On("AdjustStock", mock.Anything, shirt.Id.Hex(), -2).Return(shirt, nil)
+				or.On("SetAllocations", mock.Anything, order.Id.Hex(), []*domain.StockAllocation{
+					{ProductID: shirt.Id.Hex(), Quantity: 2},
+				}).Return(nil)
+			},
+		},
+		{
+			name: "Error - An order whose stock was sold stays cancelled",
+			mockSetup: func(or *MockOrderRepository, pr *MockProductRepository) {
+				or.On("Restore", mock.Anything, order.Id.Hex()).Return(order, nil)
+				pr.On("AdjustStock", mock.Anything, shirt.Id.Hex(), -2).Return(nil, domain.ErrNotFound)
+				or.On("Delete", mock.Anything, order.Id.Hex()).Return(order, nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			productRepo := new(MockProductRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			warehouseRepo := new(MockWarehouseRepository)
+			tt.mockSetup(orderRepo, productRepo)
+			productRepo.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+			warehouseRepo.On("GetAll", mock.Anything).Return(nil, nil)
+			inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil).Maybe()
+			inventory := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil)
+			usecase := NewOrderUsecase(orderRepo, nil, productRepo, nil, inventory, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.Restore(context.Background(), order.Id.Hex())
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, order.Id, result.Id)
+			}
+			orderRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderUsecase_Update(t *testing.T) {
+	order := &domain.Order{Id: bson.NewObjectID(), CustomerId: bson.NewObjectID().Hex(), TotalAmount: 45}
+
+	tests := []struct {
+		name          string
+		orderReq      *domain.OrderRequest
+		mockSetup     func(*MockOrderRepository)
+		expectedError error
+	}{
+		{
+			name:     "Success - The total can be changed",
+			orderReq: &domain.OrderRequest{TotalAmount: 45},
+			mockSetup: func(or *MockOrderRepository) {
+				or.On("Update", mock.Anything, order.Id.Hex(), mock.Anything).Return(order, nil)
+			},
+		},
+		{
+			name:          "Error - Product ids cannot be changed",
+			orderReq:      &domain.OrderRequest{ProductIds: []string{bson.NewObjectID().Hex()}},
+			mockSetup:     func(or *MockOrderRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Items cannot be changed",
+			orderReq:      &domain.OrderRequest{Items: []*domain.OrderItem{{ProductID: bson.NewObjectID().Hex(), Quantity: 1}}},
+			mockSetup:     func(or *MockOrderRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			tt.mockSetup(orderRepo)
+			usecase := NewOrderUsecase(orderRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.Update(context.Background(), order.Id.Hex(), tt.orderReq)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				orderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, order, result)
+			}
+			orderRepo.AssertExpectations(t)
+		})
+	}
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, to string, template string, data interface{}) error {
+	args := m.Called(ctx, to, template, data)
+	return args.Error(0)
+}
+
+func TestOrderUsecase_SendsConfirmation(t *testing.T) {
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5}
+	customer := &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com"}
+	order := &domain.Order{
+		Id:          bson.NewObjectID(),
+		CustomerId:  customer.Id.Hex(),
+		Items:       []*domain.OrderItem{{ProductID: shirt.Id.Hex(), Quantity: 1, UnitPrice: 20}},
+		TotalAmount: 20,
+	}
+
+	// Arrange
+	orderRepo := new(MockOrderRepository)
+	customerRepo := new(MockCustomerRepository)
+	productRepo := new(MockProductRepository)
+	inventoryRepo := new(MockInventoryRepository)
+	warehouseRepo := new(MockWarehouseRepository)
+	notifier := new(MockNotifier)
+	customerRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+	productRepo.On("GetByIDs", mock.Anything, []string{shirt.Id.Hex()}).Return([]*domain.Product{shirt}, nil)
+	warehouseRepo.On("GetAll", mock.Anything).Return(nil, nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything).Return(order, nil)
+	productRepo.On("AdjustStock", mock.Anything, shirt.Id.Hex(), -1).Return(&domain.Product{Id: shirt.Id, Stock: 4}, nil)
+	inventoryRepo.On("Record", mock.Anything, mock.Anything).Return(&domain.StockMovement{}, nil)
+	orderRepo.On("SetAllocations", mock.Anything, order.Id.Hex(), mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, customer.Email, domain.EmailOrderConfirmation, mock.MatchedBy(func(data *domain.OrderConfirmationEmail) bool {
+		return data.Name == "Ann" && data.OrderID == order.Id.Hex() && data.TotalAmount == 20
+	})).Return(nil)
+	inventory := NewInventoryUsecase(inventoryRepo, productRepo, warehouseRepo, nil, nil, nil, nil)
+	usecase := NewOrderUsecase(orderRepo, customerRepo, productRepo, nil, inventory, nil, notifier, nil, nil)
+
+	// Act
+	_, err := usecase.Create(context.Background(), &domain.OrderRequest{CustomerId: customer.Id.Hex(), Items: order.Items})
+
+	// Assert
+	assert.NoError(t, err)
+	notifier.AssertExpectations(t)
+}
+
+func TestOrderUsecase_RequiresVerifiedEmail(t *testing.T) {
+	// Arrange
+	customer := &domain.Customer{Id: bson.NewObjectID(), Email: "ann@example.com"}
+	customerRepo := new(MockCustomerRepository)
+	customerRepo.On("GetByID", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+	usecase := NewOrderUsecase(new(MockOrderRepository), customerRepo, nil, nil, nil, &domain.AuthPolicy{RequireVerifiedEmail: true}, nil, nil, nil)
+
+	// Act
+	result, err := usecase.Create(context.Background(), &domain.OrderRequest{CustomerId: customer.Id.Hex(), ProductIds: []string{bson.NewObjectID().Hex()}})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Nil(t, result)
+}
+
+func TestOrderUsecase_Reorder(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+	owner := &domain.Actor{CustomerID: customerID, Role: domain.RoleCustomer}
+	hat := &domain.Product{Id: bson.NewObjectID(), Name: "Hat", Price: 12, Stock: 10}
+	mug := &domain.Product{Id: bson.NewObjectID(), Name: "Mug", Price: 8, Stock: 1}
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 0},
+	}}
+	gone := bson.NewObjectID().Hex()
+	order := &domain.Order{Id: bson.NewObjectID(), CustomerId: customerID, Items: []*domain.OrderItem{
+		{ProductID: hat.Id.Hex(), Quantity: 2, UnitPrice: 10},
+		{ProductID: mug.Id.Hex(), Quantity: 3, UnitPrice: 8},
+		{ProductID: shirt.Id.Hex(), VariantID: "v-m", Quantity: 1, UnitPrice: 20},
+		{ProductID: gone, Quantity: 1, UnitPrice: 5},
+	}}
+
+	tests := []struct {
+		name            string
+		actor           *domain.Actor
+		expectedItems   map[string]int
+		expectedReasons map[string]string
+		expectedError   error
+	}{
+		{
+			name:          "Success - Available lines are added and the rest reported",
+			actor:         owner,
+			expectedItems: map[string]int{hat.Id.Hex(): 2, mug.Id.Hex(): 1},
+			expectedReasons: map[string]string{
+				hat.Id.Hex():   domain.ReorderPriceChanged,
+				mug.Id.Hex():   domain.ReorderQuantityReduced,
+				shirt.Id.Hex(): domain.ReorderOutOfStock,
+				gone:           domain.ReorderDiscontinued,
+			},
+		},
+		{
+			name:          "Error - Another customer's order",
+			actor:         &domain.Actor{CustomerID: "someone-else", Role: domain.RoleCustomer},
+			expectedError: domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			productRepo := new(MockProductRepository)
+			customerRepo := new(MockCustomerRepository)
+			cartRepo := new(MockCartRepository)
+			orderRepo.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+			productRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*domain.Product{hat, mug, shirt}, nil).Maybe()
+			customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil).Maybe()
+			cartRepo.On("GetCartByCustomerId", mock.Anything, customerID).Return(nil, domain.ErrNotFound).Maybe()
+			cartRepo.On("Save", mock.Anything, mock.Anything).Return(func(ctx context.Context, cart *domain.Cart) *domain.Cart {
+				return cart
+			}, nil).Maybe()
+			cartUsecase := NewCartUsecase(cartRepo, productRepo, customerRepo, nil, nil)
+			usecase := NewOrderUsecase(orderRepo, customerRepo, productRepo, cartUsecase, nil, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.Reorder(context.Background(), tt.actor, order.Id.Hex())
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				cartRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			quantities := map[string]int{}
+			for _, item := range result.Cart.Items {
+				quantities[item.ProductID] += item.Quantity
+			}
+			assert.Equal(t, tt.expectedItems, quantities)
+			reasons := map[string]string{}
+			for _, adjustment := range result.Adjustments {
+				reasons[adjustment.ProductID] = adjustment.Reason
+			}
+			assert.Equal(t, tt.expectedReasons, reasons)
+		})
+	}
+}
+
+func TestOrderUsecase_GetByCustomer(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+	owner := &domain.Actor{CustomerID: customerID, Role: domain.RoleCustomer}
+	minAmount, maxAmount := 50.0, 10.0
+
+	tests := []struct {
+		name          string
+		actor         *domain.Actor
+		query         *domain.OrderHistoryQuery
+		expectedPage  int
+		expectedLimit int
+		expectedError error
+	}{
+		{
+			name:          "Success - Paging defaults are applied",
+			actor:         owner,
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID, Statuses: []string{domain.OrderStatusRefunded}},
+			expectedPage:  1,
+			expectedLimit: defaultOrderHistoryLimit,
+		},
+		{
+			name:          "Success - Admins read any history and the limit is capped",
+			actor:         &domain.Actor{Role: domain.RoleAdmin},
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID, Page: 3, Limit: 1000},
+			expectedPage:  3,
+			expectedLimit: maxOrderHistoryLimit,
+		},
+		{
+			name:          "Success - Page is capped",
+			actor:         owner,
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID, Page: math.MaxInt},
+			expectedPage:  maxPage,
+			expectedLimit: defaultOrderHistoryLimit,
+		},
+		{
+			name:          "Error - Another customer's orders",
+			actor:         &domain.Actor{CustomerID: "someone-else", Role: domain.RoleCustomer},
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID},
+			expectedError: domain.ErrForbidden,
+		},
+		{
+			name:          "Error - Unknown status",
+			actor:         owner,
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID, Statuses: []string{"shipped"}},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Inverted amount range",
+			actor:         owner,
+			query:         &domain.OrderHistoryQuery{CustomerID: customerID, MinAmount: &minAmount, MaxAmount: &maxAmount},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			orderRepo := new(MockOrderRepository)
+			customerRepo := new(MockCustomerRepository)
+			customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil).Maybe()
+			orderRepo.On("GetByCustomer", mock.Anything, tt.query).Return([]*domain.Order{{CustomerId: customerID}}, int64(41), nil).Maybe()
+			usecase := NewOrderUsecase(orderRepo, customerRepo, nil, nil, nil, nil, nil, nil, nil)
+
+			// Act
+			result, err := usecase.GetByCustomer(context.Background(), tt.actor, tt.query)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				orderRepo.AssertNotCalled(t, "GetByCustomer", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(41), result.Total)
+			assert.Equal(t, tt.expectedPage, result.Page)
+			assert.Equal(t, tt.expectedLimit, result.Limit)
+			assert.Len(t, result.Orders, 1)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+)
+
+var _ domain.PrivacyUsecase = (*privacyUsecaseImpl)(nil)
+
+type privacyUsecaseImpl struct {
+	customerRepo domain.CustomerRepository
+	orderRepo    domain.OrderRepository
+	returnRepo   domain.ReturnRepository
+	reviewRepo   domain.ReviewRepository
+	cartRepo     domain.CartRepository
+	wishlistRepo domain.WishlistRepository
+	tokenRepo    domain.AuthTokenRepository
+	outboxRepo   domain.OutboxRepository
+	webhookRepo  domain.WebhookRepository
+	logs         domain.LogSearcher
+	tx           domain.Transactor
+}
+
+func NewPrivacyUsecase(
+	customerRepo domain.CustomerRepository,
+	orderRepo domain.OrderRepository,
+	returnRepo domain.ReturnRepository,
+	reviewRepo domain.ReviewRepository,
+	cartRepo domain.CartRepository,
+	wishlistRepo domain.WishlistRepository,
+	tokenRepo domain.AuthTokenRepository,
+	outboxRepo domain.OutboxRepository,
+	webhookRepo domain.WebhookRepository,
+	logs domain.LogSearcher,
+	tx domain.Transactor,
+) domain.PrivacyUsecase {
+	return &privacyUsecaseImpl{
+		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
+		returnRepo:   returnRepo,
+		reviewRepo:   reviewRepo,
+		cartRepo:     cartRepo,
+		wishlistRepo: wishlistRepo,
+		tokenRepo:    tokenRepo,
+		outboxRepo:   outboxRepo,
+		webhookRepo:  webhookRepo,
+		logs:         logs,
+		tx:           tx,
+	}
+}
+
+// Export collects everything held about a customer. Customers may export
+// their own data; admins anyone's, deleted accounts included.
+func (pu *privacyUsecaseImpl) Export(ctx context.Context, actor *domain.Actor, customerID string) (*domain.PersonalDataExport, error) {
+	cust, err := pu.subject(ctx, actor, customerID)
+	if err != nil {
+		return nil, err
+	}
+	export := &domain.PersonalDataExport{ExportedAt: time.Now(), Customer: cust, Logs: []string{}}
+
+	if export.Cart, err = pu.cartRepo.GetCartByCustomerId(ctx, customerID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if export.Wishlist, err = pu.wishlistRepo.GetByCustomerID(ctx, customerID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if export.Orders, err = pu.orderRepo.GetAllByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Orders == nil {
+		export.Orders = []*domain.Order{}
+	}
+	if export.Returns, err = pu.returnRepo.GetByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if export.Reviews, err = pu.reviewRepo.GetByCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if pu.logs != nil {
+		lines, err := pu.logs.Search(ctx, []string{customerID, cust.Email}, domain.MaxExportedLogLines)
+		if err != nil {
+			// The records are what matters; the logs are rotated away anyway.
+			logger.Error("Failed to search the logs for a data export", "customer_id", customerID, "error", err)
+		} else {
+			export.Logs = lines
+		}
+	}
+	return export, nil
+}
+
+// Erase removes the personal data of a customer. Orders, returns and reviews
+// are kept for the books and the product ratings, but moved to a pseudonym
+// that cannot be traced back to the person. Carts, wishlists, emailed tokens
+// and every event naming the customer, with its webhook deliveries, are
+// deleted, and the profile is scrubbed. Customers with open orders are refused until the
+// orders are settled. Application logs are left to their retention.
+func (pu *privacyUsecaseImpl) Erase(ctx context.Context, actor *domain.Actor, customerID string) (*domain.ErasureResult, error) {
+	cust, err := pu.subject(ctx, actor, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if cust.AnonymizedAt != nil {
+		return nil, fmt.Errorf("%w: customer was already erased", domain.ErrConflict)
+	}
+	openOrders, err := pu.orderRepo.CountOpenByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if openOrders > 0 {
+		return nil, fmt.Errorf("%w: the customer has %d open orders", domain.ErrConflict, openOrders)
+	}
+	pseudonym, err := newPseudonym()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ErasureResult{CustomerID: customerID, Pseudonym: pseudonym, ErasedAt: sessionTime()}
+	err = withinTransaction(ctx, pu.tx, func(ctx context.Context) error {
+		var err error
+		if result.Orders, err = pu.orderRepo.AnonymizeCustomer(ctx, customerID, pseudonym); err != nil {
+			return err
+		}
+		if result.Returns, err = pu.returnRepo.AnonymizeCustomer(ctx, customerID, cust.Email, pseudonym); err != nil {
+			return err
+		}
+		if result.Reviews, err = pu.reviewRepo.AnonymizeCustomer(ctx, customerID, pseudonym); err != nil {
+			return err
+		}
+		if err := pu.cartRepo.ClearCart(ctx, customerID); err != nil {
+			return err
+		}
+		if err := pu.wishlistRepo.Delete(ctx, customerID); err != nil {
+			return err
+		}
+		if err := pu.tokenRepo.DeleteByCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		eventIDs, err := pu.outboxRepo.Forget(ctx, domain.CustomerEventTypes, aboutCustomer(customerID))
+		if err != nil {
+			return err
+		}
+		result.Events = len(eventIDs)
+		if _, err := pu.webhookRepo.DeleteDeliveries(ctx, eventIDs); err != nil {
+			return err
+		}
+		return pu.customerRepo.Anonymize(ctx, customerID, result.ErasedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Customer erased", "customer_id", customerID, "by", actor.CustomerID, "orders", result.Orders, "returns", result.Returns, "reviews", result.Reviews)
+	return result, nil
+}
+
+// subject loads the customer a data subject request is about, after checking
+// the caller may act for them.
+func (pu *privacyUsecaseImpl) subject(ctx context.Context, actor *domain.Actor, customerID string) (*domain.Customer, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	return pu.customerRepo.GetByIDIncludingDeleted(ctx, customerID)
+}
+
+// aboutCustomer matches the events whose payload names the customer.
+func aboutCustomer(customerID string) func(*domain.Event) bool {
+	return func(event *domain.Event) bool {
+		var payload struct {
+			CustomerID string `json:"customer_id"`
+		}
+		return json.Unmarshal(event.Payload, &payload) == nil && payload.CustomerID == customerID
+	}
+}
+
+// newPseudonym names an erased customer in the records that are kept.
+func newPseudonym() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return "anonymized-" + hex.EncodeToString(id), nil
+}
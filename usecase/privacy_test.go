@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.Event, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id string, lastError string, nextAttempt time.Time) error {
+	args := m.Called(ctx, id, lastError, nextAttempt)
+	return args.Error(0)
+}
+
+// Forget matches the events the mock returns for the given types and
+// answers the ids of those that match.
+func (m *MockOutboxRepository) Forget(ctx context.Context, eventTypes []string, match func(*domain.Event) bool) ([]string, error) {
+	args := m.Called(ctx, eventTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	var ids []string
+	for _, event := range args.Get(0).([]*domain.Event) {
+		if match(event) {
+			ids = append(ids, event.Id.Hex())
+		}
+	}
+	return ids, args.Error(1)
+}
+
+type MockLogSearcher struct {
+	mock.Mock
+}
+
+func (m *MockLogSearcher) Search(ctx context.Context, terms []string, limit int) ([]string, error) {
+	args := m.Called(ctx, terms, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// privacyMocks holds a mock for every repository personal data lives in.
+type privacyMocks struct {
+	customers *MockCustomerRepository
+	orders    *MockOrderRepository
+	returns   *MockReturnRepository
+	reviews   *MockReviewRepository
+	carts     *MockCartRepository
+	wishlists *MockWishlistRepository
+	tokens    *MockAuthTokenRepository
+	outbox    *MockOutboxRepository
+	webhooks  *MockWebhookRepository
+	logs      *MockLogSearcher
+}
+
+func newPrivacyMocks() *privacyMocks {
+	return &privacyMocks{
+		customers: new(MockCustomerRepository),
+		orders:    new(MockOrderRepository),
+		returns:   new(MockReturnRepository),
+		reviews:   new(MockReviewRepository),
+		carts:     new(MockCartRepository),
+		wishlists: new(MockWishlistRepository),
+		tokens:    new(MockAuthTokenRepository),
+		outbox:    new(MockOutboxRepository),
+		webhooks:  new(MockWebhookRepository),
+		logs:      new(MockLogSearcher),
+	}
+}
+
+func (pm *privacyMocks) usecase() domain.PrivacyUsecase {
+	return NewPrivacyUsecase(pm.customers, pm.orders, pm.returns, pm.reviews, pm.carts, pm.wishlists, pm.tokens, pm.outbox, pm.webhooks, pm.logs, nil)
+}
+
+// expectErasure sets up a successful erasure of the customer.
+func (pm *privacyMocks) expectErasure(customer *domain.Customer) {
+	id := customer.Id.Hex()
+	pseudonym := mock.MatchedBy(func(pseudonym string) bool {
+		return strings.HasPrefix(pseudonym, "anonymized-") && !strings.Contains(pseudonym, id)
+	})
+	pm.customers.On("GetByIDIncludingDeleted", mock.Anything, id).Return(customer, nil)
+	pm.orders.On("CountOpenByCustomer", mock.Anything, id).Return(int64(0), nil)
+	pm.orders.On("AnonymizeCustomer", mock.Anything, id, pseudonym).Return(int64(3), nil)
+	pm.returns.On("AnonymizeCustomer", mock.Anything, id, customer.Email, pseudonym).Return(int64(1), nil)
+	pm.reviews.On("AnonymizeCustomer", mock.Anything, id, pseudonym).Return(int64(2), nil)
+	pm.carts.On("ClearCart", mock.Anything, id).Return(nil)
+	pm.wishlists.On("Delete", mock.Anything, id).Return(nil)
+	pm.tokens.On("DeleteByCustomer", mock.Anything, id).Return(nil)
+	// Every event naming the customer is forgotten, whatever it is about;
+	// the order of another customer is kept.
+	events := []*domain.Event{
+		newTestEvent(domain.EventCustomerRegistered, id, &domain.CustomerRegisteredPayload{CustomerID: id, Email: customer.Email}),
+		newTestEvent(domain.EventOrderPlaced, "order-1", &domain.OrderPlacedPayload{OrderID: "order-1", CustomerID: id}),
+		newTestEvent(domain.EventOrderCancelled, "order-1", &domain.OrderCancelledPayload{OrderID: "order-1", CustomerID: id}),
+		newTestEvent(domain.EventCartAbandoned, "cart-1", &domain.CartAbandonedPayload{CartID: "cart-1", CustomerID: id}),
+		newTestEvent(domain.EventOrderPlaced, "order-2", &domain.OrderPlacedPayload{OrderID: "order-2", CustomerID: "someone-else"}),
+	}
+	pm.outbox.On("Forget", mock.Anything, domain.CustomerEventTypes).Return(events, nil)
+	pm.webhooks.On("DeleteDeliveries", mock.Anything, []string{
+		events[0].Id.Hex(), events[1].Id.Hex(), events[2].Id.Hex(), events[3].Id.Hex(),
+	}).Return(int64(5), nil)
+	pm.customers.On("Anonymize", mock.Anything, id, mock.Anything).Return(nil)
+}
+
+func newTestEvent(eventType string, aggregateID string, payload interface{}) *domain.Event {
+	event, err := domain.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		panic(err)
+	}
+	return event
+}
+
+func (pm *privacyMocks) assertExpectations(t *testing.T) {
+	mock.AssertExpectationsForObjects(t, pm.customers, pm.orders, pm.returns, pm.reviews, pm.carts, pm.wishlists, pm.tokens, pm.outbox, pm.webhooks, pm.logs)
+}
+
+func TestPrivacyUsecase_Export(t *testing.T) {
+	customer := &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com"}
+	id := customer.Id.Hex()
+
+	t.Run("Success - Customer exports their own data", func(t *testing.T) {
+		// Arrange
+		mocks := newPrivacyMocks()
+		mocks.customers.On("GetByIDIncludingDeleted", mock.Anything, id).Return(customer, nil)
+		mocks.carts.On("GetCartByCustomerId", mock.Anything, id).Return(nil, domain.ErrNotFound)
+		mocks.wishlists.On("GetByCustomerID", mock.Anything, id).Return(&domain.Wishlist{CustomerID: id}, nil)
+		mocks.orders.On("GetAllByCustomer", mock.Anything, id).Return(nil, nil)
+		mocks.returns.On("GetByCustomer", mock.Anything, id).Return([]*domain.Return{{CustomerID: id}}, nil)
+		mocks.reviews.On("GetByCustomer", mock.Anything, id).Return([]*domain.Review{{CustomerID: id, Rating: 4}}, nil)
+		mocks.logs.On("Search", mock.Anything, []string{id, customer.Email}, domain.MaxExportedLogLines).
+			Return([]string{`msg="Request ended successfully" Path:=/api/customers/` + id + `/cart`}, nil)
+		actor := &domain.Actor{CustomerID: id, Role: domain.RoleCustomer}
+
+		// Act
+		export, err := mocks.usecase().Export(context.Background(), actor, id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, customer, export.Customer)
+		assert.Nil(t, export.Cart)
+		assert.NotNil(t, export.Wishlist)
+		assert.Empty(t, export.Orders)
+		assert.NotNil(t, export.Orders)
+		assert.Len(t, export.Returns, 1)
+		assert.Len(t, export.Reviews, 1)
+		assert.Len(t, export.Logs, 1)
+		mocks.assertExpectations(t)
+	})
+
+	t.Run("Error - Another customer's data", func(t *testing.T) {
+		// Arrange
+		mocks := newPrivacyMocks()
+		actor := &domain.Actor{CustomerID: bson.NewObjectID().Hex(), Role: domain.RoleCustomer}
+
+		// Act
+		export, err := mocks.usecase().Export(context.Background(), actor, id)
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, export)
+		mocks.assertExpectations(t)
+	})
+}
+
+func TestPrivacyUsecase_Erase(t *testing.T) {
+	admin := &domain.Actor{CustomerID: bson.NewObjectID().Hex(), Role: domain.RoleAdmin}
+	erasedAt := time.Now()
+
+	tests := []struct {
+		name          string
+		customer      *domain.Customer
+		mockSetup     func(*privacyMocks, *domain.Customer)
+		expectedError error
+	}{
+		{
+			name:     "Success - Records are pseudonymized and the rest deleted",
+			customer: &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com"},
+			mockSetup: func(pm *privacyMocks, customer *domain.Customer) {
+				pm.expectErasure(customer)
+			},
+		},
+		{
+			name:     "Error - Already erased",
+			customer: &domain.Customer{Id: bson.NewObjectID(), Name: domain.AnonymizedCustomerName, AnonymizedAt: &erasedAt},
+			mockSetup: func(pm *privacyMocks, customer *domain.Customer) {
+				pm.customers.On("GetByIDIncludingDeleted", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:     "Error - Open orders",
+			customer: &domain.Customer{Id: bson.NewObjectID(), Name: "Ann", Email: "ann@example.com"},
+			mockSetup: func(pm *privacyMocks, customer *domain.Customer) {
+				pm.customers.On("GetByIDIncludingDeleted", mock.Anything, customer.Id.Hex()).Return(customer, nil)
+				pm.orders.On("CountOpenByCustomer", mock.Anything, customer.Id.Hex()).Return(int64(2), nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mocks := newPrivacyMocks()
+			tt.mockSetup(mocks, tt.customer)
+
+			// Act
+			result, err := mocks.usecase().Erase(context.Background(), admin, tt.customer.Id.Hex())
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(3), result.Orders)
+				assert.Equal(t, int64(1), result.Returns)
+				assert.Equal(t, int64(2), result.Reviews)
+				assert.Equal(t, 4, result.Events)
+			}
+			mocks.assertExpectations(t)
+		})
+	}
+}
@@ -2,18 +2,44 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"strings"
+	"time"
 )
 
 var _ domain.ProductUsecase = (*productUsecaseImpl)(nil)
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
 type productUsecaseImpl struct {
-	productRepo domain.ProductRepository
+	productRepo  domain.ProductRepository
+	cartRepo     domain.CartRepository
+	categoryRepo domain.CategoryRepository
+	searcher     domain.ProductSearcher
+	mediaStore   domain.MediaStore
+	inventory    domain.InventoryUsecase
 }
 
-func NewProductUsecase(productRepo domain.ProductRepository) domain.ProductUsecase {
+func NewProductUsecase(
+	productRepo domain.ProductRepository,
+	cartRepo domain.CartRepository,
+	categoryRepo domain.CategoryRepository,
+	searcher domain.ProductSearcher,
+	mediaStore domain.MediaStore,
+	inventory domain.InventoryUsecase,
+) domain.ProductUsecase {
 	return &productUsecaseImpl{
-		productRepo: productRepo,
+		productRepo:  productRepo,
+		cartRepo:     cartRepo,
+		categoryRepo: categoryRepo,
+		searcher:     searcher,
+		mediaStore:   mediaStore,
+		inventory:    inventory,
 	}
 }
 
@@ -22,6 +48,9 @@ func (pu *productUsecaseImpl) GetAll(ctx context.Context) ([]*domain.Product, er
 	if err != nil {
 		return nil, err
 	}
+	if err := attachBreadcrumbs(ctx, pu.categoryRepo, products...); err != nil {
+		return nil, err
+	}
 	return products, nil
 
 }
@@ -31,22 +60,51 @@ func (pu *productUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.P
 	if err != nil {
 		return nil, err
 	}
+	if err := attachBreadcrumbs(ctx, pu.categoryRepo, product); err != nil {
+		return nil, err
+	}
 	return product, nil
 }
 
 func (pu *productUsecaseImpl) Create(ctx context.Context, product *domain.ProductRequest) (*domain.Product, error) {
+	if err := ensureCategoriesExist(ctx, pu.categoryRepo, product.CategoryIds); err != nil {
+		return nil, err
+	}
 	productCreated, err := pu.productRepo.Create(ctx, product)
 	if err != nil {
 		return nil, err
 	}
+	if err := pu.inventory.RecordInitialStock(ctx, productCreated); err != nil {
+		logger.Error("Failed to record initial stock", "product_id", productCreated.Id.Hex(), "error", err)
+	}
 	return productCreated, nil
 }
-func (pu *productUsecaseImpl) Update(ctx context.Context, id string, productReq *domain.ProductRequest) (*domain.Product, error) {
-	productUpdated, err := pu.productRepo.Update(ctx, id, productReq)
-	if err != nil {
+
+// Update changes the product fields and, through the inventory ledger, its
+// stock. A negative stock leaves the stock alone.
+func (pu *productUsecaseImpl) Update(ctx context.Context, actor *domain.Actor, id string, productReq *domain.ProductRequest) (*domain.Product, error) {
+	if err := ensureCategoriesExist(ctx, pu.categoryRepo, productReq.CategoryIds); err != nil {
 		return nil, err
 	}
-	return productUpdated, nil
+	fields := *productReq
+	fields.Stock = -1
+	var product *domain.Product
+	if fields.SKU != "" || fields.Name != "" || fields.Price > 0 || fields.CategoryIds != nil {
+		var err error
+		if product, err = pu.productRepo.Update(ctx, id, &fields); err != nil {
+			return nil, err
+		}
+	}
+	if productReq.Stock >= 0 {
+		var err error
+		if product, err = pu.inventory.SetStock(ctx, actor, id, "", productReq.Stock, "set by product update"); err != nil {
+			return nil, err
+		}
+	}
+	if product == nil {
+		return pu.productRepo.GetByID(ctx, id)
+	}
+	return product, nil
 }
 
 func (pu *productUsecaseImpl) Delete(ctx context.Context, id string) (*domain.Product, error) {
@@ -54,5 +112,52 @@ func (pu *productUsecaseImpl) Delete(ctx context.Context, id string) (*domain.Pr
 	if err != nil {
 		return nil, err
 	}
+	if productDeleted == nil {
+		return nil, nil
+	}
+	// A deleted product can no longer be bought, so drop it from every cart.
+	if _, err := pu.cartRepo.RemoveProductFromCarts(ctx, id); err != nil {
+		return nil, err
+	}
 	return productDeleted, nil
 }
+
+func (pu *productUsecaseImpl) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Product, error) {
+	products, err := pu.productRepo.GetAllIncludingDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (pu *productUsecaseImpl) Restore(ctx context.Context, id string) (*domain.Product, error) {
+	product, err := pu.productRepo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Purge permanently removes products soft-deleted longer than the retention period.
+func (pu *productUsecaseImpl) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return pu.productRepo.Purge(ctx, time.Now().Add(-retention))
+}
+
+func (pu *productUsecaseImpl) Search(ctx context.Context, query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	query.Page = clampPage(query.Page)
+	if query.Limit < 1 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	if (query.MinPrice != nil && *query.MinPrice < 0) || (query.MaxPrice != nil && *query.MaxPrice < 0) {
+		return nil, fmt.Errorf("%w: prices cannot be negative", domain.ErrInvalidInput)
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, fmt.Errorf("%w: min_price is greater than max_price", domain.ErrInvalidInput)
+	}
+	query.Query = strings.TrimSpace(query.Query)
+
+	return pu.searcher.Search(ctx, query)
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"intern-project-v2/utils"
+	"io"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	thumbnailSize = 320
+	// maxImagePixels rejects images that would take too much memory to
+	// decode, whatever their compressed size.
+	maxImagePixels = 40_000_000
+)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// AddImage stores an uploaded image and a thumbnail of it, then appends the
+// image to the product.
+func (pu *productUsecaseImpl) AddImage(ctx context.Context, id string, content io.Reader) (*domain.Product, error) {
+	product, err := pu.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Images) >= domain.MaxProductImages {
+		return nil, fmt.Errorf("%w: a product can have at most %d images", domain.ErrConflict, domain.MaxProductImages)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, domain.MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > domain.MaxImageBytes {
+		return nil, fmt.Errorf("%w: images are limited to %d MB", domain.ErrInvalidInput, domain.MaxImageBytes>>20)
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported image type %s", domain.ErrInvalidInput, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: image cannot be decoded or is too large", domain.ErrInvalidInput)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: image cannot be decoded", domain.ErrInvalidInput)
+	}
+
+	var thumbnail bytes.Buffer
+	thumbnailType, err := utils.EncodeImage(&thumbnail, utils.Thumbnail(img, thumbnailSize), format)
+	if err != nil {
+		return nil, err
+	}
+
+	imageID := bson.NewObjectID().Hex()
+	productImage := &domain.ProductImage{
+		Id:           imageID,
+		Key:          fmt.Sprintf("products/%s/%s.%s", id, imageID, extension),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.%s", id, imageID, imageExtensions[thumbnailType]),
+		ContentType:  contentType,
+		Width:        config.Width,
+		Height:       config.Height,
+		CreatedAt:    time.Now(),
+	}
+	if productImage.URL, err = pu.mediaStore.Put(ctx, productImage.Key, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+	if productImage.ThumbnailURL, err = pu.mediaStore.Put(ctx, productImage.ThumbnailKey, &thumbnail, thumbnailType); err != nil {
+		pu.deleteImageFiles(ctx, productImage)
+		return nil, err
+	}
+
+	updated, err := pu.productRepo.AddImage(ctx, id, productImage)
+	if err != nil {
+		pu.deleteImageFiles(ctx, productImage)
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (pu *productUsecaseImpl) DeleteImage(ctx context.Context, id string, imageID string) (*domain.Product, error) {
+	product, err := pu.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var productImage *domain.ProductImage
+	for _, candidate := range product.Images {
+		if candidate.Id == imageID {
+			productImage = candidate
+			break
+		}
+	}
+	if productImage == nil {
+		return nil, fmt.Errorf("%w: product %s has no image %s", domain.ErrNotFound, id, imageID)
+	}
+
+	updated, err := pu.productRepo.RemoveImage(ctx, id, imageID)
+	if err != nil {
+		return nil, err
+	}
+	pu.deleteImageFiles(ctx, productImage)
+	return updated, nil
+}
+
+// ReorderImages puts the images of a product in the given order, which must
+// name every image exactly once.
+func (pu *productUsecaseImpl) ReorderImages(ctx context.Context, id string, orderReq *domain.ProductImageOrderRequest) (*domain.Product, error) {
+	product, err := pu.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orderReq.ImageIds) != len(product.Images) {
+		return nil, fmt.Errorf("%w: the order must list all %d images", domain.ErrInvalidInput, len(product.Images))
+	}
+	if len(product.Images) == 0 {
+		return product, nil
+	}
+
+	byID := make(map[string]*domain.ProductImage, len(product.Images))
+	for _, productImage := range product.Images {
+		byID[productImage.Id] = productImage
+	}
+	ordered := make([]*domain.ProductImage, 0, len(orderReq.ImageIds))
+	for _, imageID := range orderReq.ImageIds {
+		productImage, ok := byID[imageID]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown or repeated image %s", domain.ErrInvalidInput, imageID)
+		}
+		delete(byID, imageID)
+		ordered = append(ordered, productImage)
+	}
+	return pu.productRepo.SetImages(ctx, id, ordered)
+}
+
+// deleteImageFiles removes stored files on a best-effort basis; a leftover
+// file is harmless, so failures are only logged.
+func (pu *productUsecaseImpl) deleteImageFiles(ctx context.Context, productImage *domain.ProductImage) {
+	for _, key := range []string{productImage.Key, productImage.ThumbnailKey} {
+		if err := pu.mediaStore.Delete(ctx, key); err != nil {
+			logger.Error("Failed to delete media", "key", key, "error", err)
+		}
+	}
+}
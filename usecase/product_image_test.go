@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"intern-project-v2/domain"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMediaStore struct {
+	mock.Mock
+	stored map[string][]byte
+}
+
+func (m *MockMediaStore) Put(ctx context.Context, key string, content io.Reader, contentType string) (string, error) {
+	args := m.Called(ctx, key, contentType)
+	data, _ := io.ReadAll(content)
+	if m.stored == nil {
+		m.stored = map[string][]byte{}
+	}
+	m.stored[key] = data
+	return "/media/" + key, args.Error(0)
+}
+
+func (m *MockMediaStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func TestProductUsecase_AddImage(t *testing.T) {
+	var upload bytes.Buffer
+	src := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		src.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	assert.NoError(t, png.Encode(&upload, src))
+
+	t.Run("Success - Stores the image and a thumbnail", func(t *testing.T) {
+		productRepo := new(MockProductRepository)
+		store := new(MockMediaStore)
+		productRepo.On("GetByID", mock.Anything, "p1").Return(&domain.Product{}, nil)
+		productRepo.On("AddImage", mock.Anything, "p1", mock.Anything).Return(&domain.Product{}, nil)
+		store.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		usecase := NewProductUsecase(productRepo, nil, nil, nil, store, nil)
+
+		_, err := usecase.AddImage(context.Background(), "p1", bytes.NewReader(upload.Bytes()))
+
+		assert.NoError(t, err)
+		productImage := productRepo.Calls[1].Arguments.Get(2).(*domain.ProductImage)
+		assert.Equal(t, 800, productImage.Width)
+		assert.Equal(t, "image/png", productImage.ContentType)
+		assert.True(t, strings.HasSuffix(productImage.ThumbnailURL, "_thumb.png"))
+
+		thumbnail, err := png.Decode(bytes.NewReader(store.stored[productImage.ThumbnailKey]))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Pt(thumbnailSize, thumbnailSize/2), thumbnail.Bounds().Size())
+	})
+
+	t.Run("Error - Not an image", func(t *testing.T) {
+		productRepo := new(MockProductRepository)
+		store := new(MockMediaStore)
+		productRepo.On("GetByID", mock.Anything, "p1").Return(&domain.Product{}, nil)
+		usecase := NewProductUsecase(productRepo, nil, nil, nil, store, nil)
+
+		_, err := usecase.AddImage(context.Background(), "p1", strings.NewReader("name,price\nshirt,20\n"))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - Cleans up files when the product update fails", func(t *testing.T) {
+		productRepo := new(MockProductRepository)
+		store := new(MockMediaStore)
+		productRepo.On("GetByID", mock.Anything, "p1").Return(&domain.Product{}, nil)
+		productRepo.On("AddImage", mock.Anything, "p1", mock.Anything).Return(nil, domain.ErrNotFound)
+		store.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		store.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		usecase := NewProductUsecase(productRepo, nil, nil, nil, store, nil)
+
+		_, err := usecase.AddImage(context.Background(), "p1", bytes.NewReader(upload.Bytes()))
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		store.AssertNumberOfCalls(t, "Delete", 2)
+	})
+}
@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxImportRows keeps a single import within one reasonable bulk write.
+	maxImportRows = 10000
+	// categorySeparator joins category ids inside one CSV cell.
+	categorySeparator = ";"
+)
+
+var productColumns = []string{"sku", "name", "price", "stock", "category_ids"}
+
+// productRecord is the flat shape of a product in import and export files.
+type productRecord struct {
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	Price       float64  `json:"price"`
+	Stock       int      `json:"stock"`
+	CategoryIds []string `json:"category_ids,omitempty"`
+}
+
+type importRow struct {
+	line   int
+	record *productRecord
+}
+
+// Import validates every row of a CSV or NDJSON file and, unless it is a dry
+// run, upserts the products by SKU. Nothing is written if any row is invalid,
+// so a spreadsheet is either applied completely or not at all. Stock is set
+// after the upsert through the inventory ledger, with "import" as the reason.
+func (pu *productUsecaseImpl) Import(ctx context.Context, actor *domain.Actor, content io.Reader, format string, dryRun bool) (*domain.ProductImportResult, error) {
+	var rows []*importRow
+	var rowErrors []*domain.ProductImportError
+	var err error
+	switch format {
+	case domain.ProductFormatCSV:
+		rows, rowErrors, err = parseProductCSV(content)
+	case domain.ProductFormatNDJSON:
+		rows, rowErrors, err = parseProductNDJSON(content)
+	default:
+		return nil, fmt.Errorf("%w: unsupported import format %q", domain.ErrInvalidInput, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows)+len(rowErrors) > maxImportRows {
+		return nil, fmt.Errorf("%w: imports are limited to %d rows", domain.ErrInvalidInput, maxImportRows)
+	}
+
+	var categories map[string]*domain.Category
+	for _, row := range rows {
+		if len(row.record.CategoryIds) > 0 {
+			if categories, err = loadCategories(ctx, pu.categoryRepo); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	result := &domain.ProductImportResult{
+		DryRun: dryRun,
+		Rows:   len(rows) + len(rowErrors),
+		Errors: rowErrors,
+	}
+	seen := make(map[string]int, len(rows))
+	products := make([]*domain.ProductRequest, 0, len(rows))
+	for _, row := range rows {
+		if err := validateProductRecord(row.record, categories); err != nil {
+			result.Errors = append(result.Errors, rowError(row.line, row.record.SKU, err))
+			continue
+		}
+		if first, ok := seen[row.record.SKU]; ok {
+			result.Errors = append(result.Errors, rowError(row.line, row.record.SKU, fmt.Errorf("duplicate SKU, first seen on line %d", first)))
+			continue
+		}
+		seen[row.record.SKU] = row.line
+		products = append(products, &domain.ProductRequest{
+			SKU:         row.record.SKU,
+			Name:        row.record.Name,
+			Price:       row.record.Price,
+			Stock:       row.record.Stock,
+			CategoryIds: row.record.CategoryIds,
+		})
+	}
+
+	// Stock kept per variant or per warehouse cannot be set from a single
+	// column; such rows must leave the stock as it is.
+	existing, err := pu.productsBySKU(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	var stockChanges []*domain.ProductRequest
+	for _, product := range products {
+		current, ok := existing[product.SKU]
+		if ok && current.Stock == product.Stock || !ok && product.Stock == 0 {
+			continue
+		}
+		if ok && current.HasVariants() {
+			result.Errors = append(result.Errors, rowError(seen[product.SKU], product.SKU, errors.New("stock is kept per variant and cannot be imported")))
+			continue
+		}
+		if ok && holdsStock(current.WarehouseStock) {
+			result.Errors = append(result.Errors, rowError(seen[product.SKU], product.SKU, errors.New("stock is kept in warehouses and cannot be imported")))
+			continue
+		}
+		stockChanges = append(stockChanges, product)
+	}
+
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+	if len(result.Errors) > 0 || dryRun {
+		return result, nil
+	}
+	result.Created, result.Updated, err = pu.productRepo.UpsertBySKU(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	if len(stockChanges) == 0 {
+		return result, nil
+	}
+
+	written, err := pu.productsBySKU(ctx, stockChanges)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range stockChanges {
+		current, ok := written[product.SKU]
+		if !ok {
+			return nil, fmt.Errorf("%w: imported product %s", domain.ErrNotFound, product.SKU)
+		}
+		if _, err := pu.inventory.SetStock(ctx, actor, current.Id.Hex(), "", product.Stock, "import"); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (pu *productUsecaseImpl) productsBySKU(ctx context.Context, products []*domain.ProductRequest) (map[string]*domain.Product, error) {
+	skus := make([]string, 0, len(products))
+	for _, product := range products {
+		skus = append(skus, product.SKU)
+	}
+	found, err := pu.productRepo.GetBySKUs(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+	bySKU := make(map[string]*domain.Product, len(found))
+	for _, product := range found {
+		bySKU[product.SKU] = product
+	}
+	return bySKU, nil
+}
+
+// Export streams every product to w in the same columns Import reads, so an
+// export can be edited and imported back.
+func (pu *productUsecaseImpl) Export(ctx context.Context, w io.Writer, format string) error {
+	switch format {
+	case domain.ProductFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(productColumns); err != nil {
+			return err
+		}
+		err := pu.productRepo.Each(ctx, func(product *domain.Product) error {
+			return writer.Write([]string{
+				product.SKU,
+				product.Name,
+				strconv.FormatFloat(product.Price, 'f', -1, 64),
+				strconv.Itoa(product.Stock),
+				strings.Join(product.CategoryIds, categorySeparator),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	case domain.ProductFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		return pu.productRepo.Each(ctx, func(product *domain.Product) error {
+			return encoder.Encode(&productRecord{
+				SKU:         product.SKU,
+				Name:        product.Name,
+				Price:       product.Price,
+				Stock:       product.Stock,
+				CategoryIds: product.CategoryIds,
+			})
+		})
+	default:
+		return fmt.Errorf("%w: unsupported export format %q", domain.ErrInvalidInput, format)
+	}
+}
+
+// parseProductCSV reads a CSV file whose header names the columns, in any
+// order. Only sku and name are mandatory columns.
+func parseProductCSV(content io.Reader) ([]*importRow, []*domain.ProductImportError, error) {
+	reader := csv.NewReader(content)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the file is empty", domain.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unreadable header: %v", domain.ErrInvalidInput, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"sku", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: missing %s column", domain.ErrInvalidInput, required)
+		}
+	}
+
+	var rows []*importRow
+	var rowErrors []*domain.ProductImportError
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, rowError(parseErr.Line, "", parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+		record := &productRecord{SKU: cell("sku"), Name: cell("name")}
+		if raw := cell("price"); raw != "" {
+			if record.Price, err = strconv.ParseFloat(raw, 64); err != nil {
+				rowErrors = append(rowErrors, rowError(line, record.SKU, fmt.Errorf("invalid price %q", raw)))
+				continue
+			}
+		}
+		if raw := cell("stock"); raw != "" {
+			if record.Stock, err = strconv.Atoi(raw); err != nil {
+				rowErrors = append(rowErrors, rowError(line, record.SKU, fmt.Errorf("invalid stock %q", raw)))
+				continue
+			}
+		}
+		if raw := cell("category_ids"); raw != "" {
+			for _, id := range strings.Split(raw, categorySeparator) {
+				if id = strings.TrimSpace(id); id != "" {
+					record.CategoryIds = append(record.CategoryIds, id)
+				}
+			}
+		}
+		rows = append(rows, &importRow{line: line, record: record})
+	}
+	return rows, rowErrors, nil
+}
+
+// parseProductNDJSON reads one JSON object per line; blank lines are skipped.
+func parseProductNDJSON(content io.Reader) ([]*importRow, []*domain.ProductImportError, error) {
+	scanner := bufio.NewScanner(content)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []*importRow
+	var rowErrors []*domain.ProductImportError
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		var record productRecord
+		if err := decoder.Decode(&record); err != nil {
+			rowErrors = append(rowErrors, rowError(line, "", err))
+			continue
+		}
+		record.SKU = strings.TrimSpace(record.SKU)
+		record.Name = strings.TrimSpace(record.Name)
+		rows = append(rows, &importRow{line: line, record: &record})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return rows, rowErrors, nil
+}
+
+func validateProductRecord(record *productRecord, categories map[string]*domain.Category) error {
+	switch {
+	case record.SKU == "":
+		return errors.New("sku is required")
+	case record.Name == "":
+		return errors.New("name is required")
+	case record.Price <= 0:
+		return errors.New("price must be positive")
+	case record.Stock < 0:
+		return errors.New("stock cannot be negative")
+	}
+	for _, id := range record.CategoryIds {
+		if _, ok := categories[id]; !ok {
+			return fmt.Errorf("category %s does not exist", id)
+		}
+	}
+	return nil
+}
+
+func rowError(line int, sku string, err error) *domain.ProductImportError {
+	return &domain.ProductImportError{Line: line, SKU: sku, Error: err.Error()}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"intern-project-v2/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestProductUsecase_Import(t *testing.T) {
+	admin := &domain.Actor{CustomerID: "admin-1", Email: "admin@example.com", Role: domain.RoleAdmin}
+	category := &domain.Category{Id: bson.NewObjectID(), Name: "Shirts"}
+	imported := &domain.Product{Id: bson.NewObjectID(), SKU: "SH-1"}
+	validCSV := "SKU,Name,Price,Stock,Category_IDs\n" +
+		"SH-1,Oxford Shirt,29.5,10," + category.Id.Hex() + "\n" +
+		"SH-2,\"Linen Shirt, white\",35,0,\n"
+
+	tests := []struct {
+		name            string
+		content         string
+		format          string
+		dryRun          bool
+		mockSetup       func(*MockProductRepository, *MockCategoryRepository, *MockInventoryRepository)
+		expectedCreated int64
+		expectedErrors  []int
+		expectedError   error
+	}{
+		{
+			name:    "Success - CSV rows are upserted in one bulk write and stock goes through the ledger",
+			content: validCSV,
+			format:  domain.ProductFormatCSV,
+			mockSetup: func(pr *MockProductRepository, cr *MockCategoryRepository, ir *MockInventoryRepository) {
+				cr.On("GetAll", mock.Anything).Return([]*domain.Category{category}, nil)
+				pr.On("GetBySKUs", mock.Anything, []string{"SH-1", "SH-2"}).Return([]*domain.Product{}, nil).Once()
+				pr.On("UpsertBySKU", mock.Anything, mock.MatchedBy(func(products []*domain.ProductRequest) bool {
+					return len(products) == 2 && products[1].Name == "Linen Shirt, white" && products[0].Price == 29.5
+				})).Return(int64(2), int64(0), nil).Once()
+				pr.On("GetBySKUs", mock.Anything, []string{"SH-1"}).Return([]*domain.Product{imported}, nil).Once()
+				pr.On("GetByID", mock.Anything, imported.Id.Hex()).Return(imported, nil)
+				pr.On("AdjustStock", mock.Anything, imported.Id.Hex(), 10).Return(&domain.Product{Id: imported.Id, Stock: 10}, nil)
+				ir.On("Record", mock.Anything, mock.MatchedBy(func(movement *domain.StockMovement) bool {
+					return movement.Reason == "import" && movement.Quantity == 10 && movement.Actor == admin.Email
+				})).Return(&domain.StockMovement{}, nil)
+			},
+			expectedCreated: 2,
+		},
+		{
+			name:    "Success - Dry run only validates",
+			content: validCSV,
+			format:  domain.ProductFormatCSV,
+			dryRun:  true,
+			mockSetup: func(pr *MockProductRepository, cr *MockCategoryRepository, ir *MockInventoryRepository) {
+				cr.On("GetAll", mock.Anything).Return([]*domain.Category{category}, nil)
+				pr.On("GetBySKUs", mock.Anything, []string{"SH-1", "SH-2"}).Return([]*domain.Product{}, nil)
+			},
+		},
+		{
+			name: "Success - Row errors are reported and nothing is written",
+			content: "{\"sku\":\"SH-1\",\"name\":\"Oxford\",\"price\":10}\n" +
+				"\n" +
+				"{\"sku\":\"SH-1\",\"name\":\"Again\",\"price\":10}\n" +
+				"{\"sku\":\"SH-3\",\"price\":10}\n" +
+				"{\"sku\":\"SH-4\",\"name\":\"Broken\",\"price\":\"ten\"}\n",
+			format: domain.ProductFormatNDJSON,
+			mockSetup: func(pr *MockProductRepository, cr *MockCategoryRepository, ir *MockInventoryRepository) {
+				pr.On("GetBySKUs", mock.Anything, []string{"SH-1"}).Return([]*domain.Product{}, nil)
+			},
+			expectedErrors: []int{3, 4, 5},
+		},
+		{
+			name: "Success - Stock kept per variant or warehouse is not imported",
+			content: "sku,name,price,stock\n" +
+				"SH-1,Oxford Shirt,29.5,10\n" +
+				"SH-2,Linen Shirt,35,4\n" +
+				"SH-3,Flannel Shirt,40,7\n",
+			format: domain.ProductFormatCSV,
+			mockSetup: func(pr *MockProductRepository, cr *MockCategoryRepository, ir *MockInventoryRepository) {
+				pr.On("GetBySKUs", mock.Anything, []string{"SH-1", "SH-2", "SH-3"}).Return([]*domain.Product{
+					{SKU: "SH-1", Stock: 3, Variants: []*domain.ProductVariant{{Id: "v1", Stock: 3}}},
+					{SKU: "SH-2", Stock: 5, WarehouseStock: map[string]int{"w1": 5}},
+					{SKU: "SH-3", Stock: 7, WarehouseStock: map[string]int{"w1": 7}},
+				}, nil)
+			},
+			expectedErrors: []int{2, 3},
+		},
+		{
+			name:          "Error - Missing required column",
+			content:       "name,price\nShirt,10\n",
+			format:        domain.ProductFormatCSV,
+			mockSetup:     func(pr *MockProductRepository, cr *MockCategoryRepository, ir *MockInventoryRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			productRepo := new(MockProductRepository)
+			categoryRepo := new(MockCategoryRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			tt.mockSetup(productRepo, categoryRepo, inventoryRepo)
+			inventory := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, nil, nil, nil)
+			usecase := NewProductUsecase(productRepo, nil, categoryRepo, nil, nil, inventory)
+
+			// Act
+			result, err := usecase.Import(context.Background(), admin, strings.NewReader(tt.content), tt.format, tt.dryRun)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCreated, result.Created)
+			var lines []int
+			for _, rowErr := range result.Errors {
+				lines = append(lines, rowErr.Line)
+			}
+			assert.Equal(t, tt.expectedErrors, lines)
+			productRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+			if tt.dryRun || len(tt.expectedErrors) > 0 {
+				productRepo.AssertNotCalled(t, "UpsertBySKU", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestProductUsecase_ExportRoundTrip(t *testing.T) {
+	productRepo := new(MockProductRepository)
+	productRepo.On("Each", mock.Anything).Return([]*domain.Product{
+		{SKU: "SH-1", Name: "Oxford Shirt", Price: 29.5, Stock: 10, CategoryIds: []string{"a", "b"}},
+	}, nil)
+	usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+
+	var out bytes.Buffer
+	err := usecase.Export(context.Background(), &out, domain.ProductFormatCSV)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sku,name,price,stock,category_ids\nSH-1,Oxford Shirt,29.5,10,a;b\n", out.String())
+	rows, rowErrors, err := parseProductCSV(&out)
+	assert.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Equal(t, []string{"a", "b"}, rows[0].record.CategoryIds)
+}
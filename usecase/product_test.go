@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/repository/memory"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestProductUsecase_Search(t *testing.T) {
+	products := []*domain.Product{
+		{Id: bson.NewObjectID(), Name: "Blue Cotton Shirt", Price: 20, Stock: 3},
+		{Id: bson.NewObjectID(), Name: "Red Shirt", Price: 45, Stock: 0},
+		{Id: bson.NewObjectID(), Name: "Shirtdress", Price: 120, Stock: 1},
+		{Id: bson.NewObjectID(), Name: "Blue Jeans", Price: 60, Stock: 8},
+	}
+	price := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name          string
+		query         *domain.ProductSearchQuery
+		expectedNames []string
+		expectedTotal int64
+		expectedError error
+	}{
+		{
+			name:          "Success - Ranks exact word matches above prefix matches",
+			query:         &domain.ProductSearchQuery{Query: "blue shirt"},
+			expectedNames: []string{"Blue Cotton Shirt", "Blue Jeans", "Red Shirt", "Shirtdress"},
+			expectedTotal: 4,
+		},
+		{
+			name:          "Success - Price and stock filters",
+			query:         &domain.ProductSearchQuery{Query: "shirt", MinPrice: price(10), MaxPrice: price(100), InStock: true},
+			expectedNames: []string{"Blue Cotton Shirt"},
+			expectedTotal: 1,
+		},
+		{
+			name:          "Success - Pagination",
+			query:         &domain.ProductSearchQuery{Query: "shirt", Page: 2, Limit: 2},
+			expectedNames: []string{"Shirtdress"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "Success - Page past the end is empty",
+			query:         &domain.ProductSearchQuery{Query: "shirt", Page: math.MaxInt, Limit: 100},
+			expectedTotal: 3,
+		},
+		{
+			name:          "Error - Inverted price range",
+			query:         &domain.ProductSearchQuery{MinPrice: price(50), MaxPrice: price(10)},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			productRepo := new(MockProductRepository)
+			productRepo.On("GetAll", mock.Anything).Return(products, nil).Maybe()
+			usecase := NewProductUsecase(productRepo, nil, nil, memory.NewProductSearcher(productRepo), nil, nil)
+
+			// Act
+			result, err := usecase.Search(context.Background(), tt.query)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, result.Total)
+			var names []string
+			for _, hit := range result.Products {
+				names = append(names, hit.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestProductUsecase_SearchFacets(t *testing.T) {
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetAll", mock.Anything).Return([]*domain.Product{
+		{Name: "Shirt A", Price: 10, Stock: 1},
+		{Name: "Shirt B", Price: 30, Stock: 0},
+		{Name: "Shirt C", Price: 300, Stock: 2},
+		{Name: "Hat", Price: 15, Stock: 5},
+	}, nil)
+	usecase := NewProductUsecase(productRepo, nil, nil, memory.NewProductSearcher(productRepo), nil, nil)
+
+	result, err := usecase.Search(context.Background(), &domain.ProductSearchQuery{Query: "shirt", InStock: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	// Facets ignore the in_stock filter so the sidebar can offer both options.
+	assert.Equal(t, int64(2), result.Facets.Availability.InStock)
+	assert.Equal(t, int64(1), result.Facets.Availability.OutOfStock)
+	counts := map[float64]int64{}
+	for _, facet := range result.Facets.Price {
+		counts[facet.Min] = facet.Count
+	}
+	assert.Equal(t, map[float64]int64{0: 1, 25: 1, 50: 0, 100: 0, 250: 1}, counts)
+}
+
+func TestProductUsecase_SetOptions(t *testing.T) {
+	productID := bson.NewObjectID()
+	existing := &domain.ProductVariant{Id: "keep", SKU: "CUSTOM-SKU", Options: map[string]string{"Size": "m"}, Stock: 7}
+	product := &domain.Product{Id: productID, Name: "Oxford Shirt", Variants: []*domain.ProductVariant{existing}}
+
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", mock.Anything, productID.Hex()).Return(product, nil)
+	productRepo.On("SetVariants", mock.Anything, productID.Hex(), product, mock.Anything, mock.Anything).
+		Return(&domain.Product{}, nil)
+	usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+
+	_, err := usecase.SetOptions(context.Background(), productID.Hex(), &domain.ProductOptionsRequest{
+		Options: []*domain.ProductOption{{Name: "Size", Values: []string{"S", "M", "L"}}},
+	})
+
+	assert.NoError(t, err)
+	variants := productRepo.Calls[1].Arguments.Get(4).([]*domain.ProductVariant)
+	var skus []string
+	for _, variant := range variants {
+		skus = append(skus, variant.SKU)
+	}
+	assert.Equal(t, []string{"OXFORD-SHIRT-S", "CUSTOM-SKU", "OXFORD-SHIRT-L"}, skus)
+	assert.Equal(t, "keep", variants[1].Id)
+	assert.Equal(t, 7, variants[1].Stock)
+}
+
+func TestProductUsecase_SetOptionsValidation(t *testing.T) {
+	productRepo := new(MockProductRepository)
+	productRepo.On("GetByID", mock.Anything, "p1").Return(&domain.Product{Name: "Shirt"}, nil)
+	usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+
+	_, err := usecase.SetOptions(context.Background(), "p1", &domain.ProductOptionsRequest{
+		Options: []*domain.ProductOption{{Name: "Size", Values: []string{"S", "s"}}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	productRepo.AssertNotCalled(t, "SetVariants", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProductUsecase_SetOptionsKeepsStock(t *testing.T) {
+	colors := []*domain.ProductOption{{Name: "Color", Values: []string{"Red"}}}
+
+	tests := []struct {
+		name          string
+		product       *domain.Product
+		options       []*domain.ProductOption
+		expectedError error
+	}{
+		{
+			name:          "Error - Single SKU with stock gets options",
+			product:       &domain.Product{Name: "Shirt", Stock: 5},
+			options:       colors,
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:          "Error - Single SKU with warehouse stock gets options",
+			product:       &domain.Product{Name: "Shirt", WarehouseStock: map[string]int{"w1": 2}},
+			options:       colors,
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name: "Error - Removed option value holds stock",
+			product: &domain.Product{Name: "Shirt", Stock: 3, Options: colors, Variants: []*domain.ProductVariant{
+				{Id: "v1", SKU: "SHIRT-RED", Options: map[string]string{"Color": "Red"}, Stock: 3},
+			}},
+			options:       []*domain.ProductOption{{Name: "Color", Values: []string{"Blue"}}},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name: "Error - Removed option merges variants with stock",
+			product: &domain.Product{Name: "Shirt", Stock: 4, Variants: []*domain.ProductVariant{
+				{Id: "v1", SKU: "SHIRT-RED-S", Options: map[string]string{"Color": "Red", "Size": "S"}, Stock: 1},
+				{Id: "v2", SKU: "SHIRT-RED-M", Options: map[string]string{"Color": "Red", "Size": "M"}, Stock: 3},
+			}},
+			options:       colors,
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name: "Error - Back to a single SKU with variant stock",
+			product: &domain.Product{Name: "Shirt", Stock: 1, Variants: []*domain.ProductVariant{
+				{Id: "v1", SKU: "SHIRT-RED", Options: map[string]string{"Color": "Red"}, Stock: 1},
+			}},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name: "Success - Removed option merges variants without stock",
+			product: &domain.Product{Name: "Shirt", Stock: 3, Variants: []*domain.ProductVariant{
+				{Id: "v1", SKU: "SHIRT-RED-S", Options: map[string]string{"Color": "Red", "Size": "S"}},
+				{Id: "v2", SKU: "SHIRT-RED-M", Options: map[string]string{"Color": "Red", "Size": "M"}, Stock: 3},
+			}},
+			options: colors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			productRepo := new(MockProductRepository)
+			productRepo.On("GetByID", mock.Anything, "p1").Return(tt.product, nil)
+			if tt.expectedError == nil {
+				productRepo.On("SetVariants", mock.Anything, "p1", tt.product, mock.Anything, mock.Anything).Return(&domain.Product{}, nil)
+			}
+			usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+
+			// Act
+			_, err := usecase.SetOptions(context.Background(), "p1", &domain.ProductOptionsRequest{Options: tt.options})
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				productRepo.AssertNotCalled(t, "SetVariants", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				variants := productRepo.Calls[1].Arguments.Get(4).([]*domain.ProductVariant)
+				assert.Len(t, variants, 1)
+				assert.Equal(t, 3, variants[0].Stock)
+			}
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"intern-project-v2/domain"
+)
+
+// Reorder copies the lines of a previous order into the customer's cart at
+// today's prices. Discontinued, unavailable and ambiguous lines are skipped
+// and quantities are cut to what is in stock; every such change is reported
+// as an adjustment.
+func (ou *orderUsecaseImpl) Reorder(ctx context.Context, actor *domain.Actor, id string) (*domain.ReorderResult, error) {
+	order, err := ou.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() && order.CustomerId != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+
+	lines := orderLines(order)
+	productIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	products, err := ou.productRepo.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Product, len(products))
+	for _, product := range products {
+		byID[product.Id.Hex()] = product
+	}
+
+	cart, err := ou.cartUsecase.GetCartByCustomerId(ctx, order.CustomerId)
+	if errors.Is(err, domain.ErrNotFound) {
+		cart, err = &domain.Cart{CustomerID: order.CustomerId, Items: []*domain.CartItem{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	inCart := make(map[string]int, len(cart.Items))
+	for _, item := range cart.Items {
+		inCart[lineKey(item.ProductID, item.VariantID)] += item.Quantity
+	}
+
+	result := &domain.ReorderResult{Adjustments: []*domain.ReorderAdjustment{}}
+	var operations []*domain.CartOperation
+	for _, line := range lines {
+		adjustment := &domain.ReorderAdjustment{
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			Requested: line.Quantity,
+		}
+
+		product := byID[line.ProductID]
+		var variant *domain.ProductVariant
+		switch {
+		case product == nil:
+			adjustment.Reason = domain.ReorderDiscontinued
+		case line.VariantID == "" && product.HasVariants():
+			adjustment.Reason = domain.ReorderVariantRequired
+		case line.VariantID != "":
+			if variant = product.Variant(line.VariantID); variant == nil {
+				adjustment.Reason = domain.ReorderDiscontinued
+			}
+		}
+		if adjustment.Reason != "" {
+			result.Adjustments = append(result.Adjustments, adjustment)
+			continue
+		}
+
+		stock := product.Stock
+		if variant != nil {
+			stock = variant.Stock
+		}
+		key := lineKey(line.ProductID, line.VariantID)
+		available := stock - inCart[key]
+		adjustment.Added = min(line.Quantity, max(available, 0))
+		switch {
+		case adjustment.Added == 0:
+			adjustment.Reason = domain.ReorderOutOfStock
+		case adjustment.Added < line.Quantity:
+			adjustment.Reason = domain.ReorderQuantityReduced
+		}
+		if adjustment.Added > 0 {
+			inCart[key] += adjustment.Added
+			operations = append(operations, &domain.CartOperation{
+				Op:        domain.CartOpAdd,
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				Quantity:  adjustment.Added,
+			})
+		}
+
+		if price := product.PriceOf(variant); line.UnitPrice != nil && *line.UnitPrice != price {
+			adjustment.OldPrice, adjustment.NewPrice = line.UnitPrice, &price
+			if adjustment.Reason == "" {
+				adjustment.Reason = domain.ReorderPriceChanged
+			}
+		}
+		if adjustment.Reason != "" {
+			result.Adjustments = append(result.Adjustments, adjustment)
+		}
+	}
+
+	result.Cart = cart
+	if len(operations) > 0 {
+		result.Cart, err = ou.cartUsecase.BatchUpdate(ctx, actor, order.CustomerId, &domain.CartBatchRequest{Operations: operations})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// reorderLine is one line of an order, priced when the order has items.
+type reorderLine struct {
+	ProductID string
+	VariantID string
+	Quantity  int
+	UnitPrice *float64
+}
+
+// orderLines merges the lines of an order by product and variant. Orders
+// placed before order items existed only list product ids, one per unit.
+func orderLines(order *domain.Order) []*reorderLine {
+	var lines []*reorderLine
+	index := make(map[string]*reorderLine)
+	add := func(productID, variantID string, quantity int, unitPrice *float64) {
+		key := lineKey(productID, variantID)
+		if line, ok := index[key]; ok {
+			line.Quantity += quantity
+			return
+		}
+		line := &reorderLine{ProductID: productID, VariantID: variantID, Quantity: quantity, UnitPrice: unitPrice}
+		index[key] = line
+		lines = append(lines, line)
+	}
+
+	if len(order.Items) > 0 {
+		for _, item := range order.Items {
+			unitPrice := item.UnitPrice
+			add(item.ProductID, item.VariantID, item.Quantity, &unitPrice)
+		}
+		return lines
+	}
+	for _, productID := range order.ProductIds {
+		add(productID, "", 1, nil)
+	}
+	return lines
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"time"
+)
+
+var _ domain.ReportUsecase = (*reportUsecaseImpl)(nil)
+
+const (
+	defaultReportDays       = 30
+	defaultTopProductsLimit = 10
+	maxTopProductsLimit     = 100
+	defaultLowStockLimit    = 50
+	maxLowStockLimit        = 500
+	// maxDailyReportDays keeps a daily revenue report to a readable size.
+	maxDailyReportDays = 366
+)
+
+type reportUsecaseImpl struct {
+	reportRepo domain.ReportRepository
+}
+
+func NewReportUsecase(reportRepo domain.ReportRepository) domain.ReportUsecase {
+	return &reportUsecaseImpl{
+		reportRepo: reportRepo,
+	}
+}
+
+func (ru *reportUsecaseImpl) Revenue(ctx context.Context, query *domain.ReportQuery) ([]*domain.RevenuePoint, error) {
+	if err := normalizeReportRange(query); err != nil {
+		return nil, err
+	}
+	switch query.Interval {
+	case "":
+		query.Interval = domain.ReportIntervalDay
+	case domain.ReportIntervalDay, domain.ReportIntervalWeek, domain.ReportIntervalMonth:
+	default:
+		return nil, fmt.Errorf("%w: interval must be day, week or month", domain.ErrInvalidInput)
+	}
+	if query.Interval == domain.ReportIntervalDay && query.To.Sub(query.From) > maxDailyReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: daily reports cover at most %d days, use a weekly or monthly interval", domain.ErrInvalidInput, maxDailyReportDays)
+	}
+	return ru.reportRepo.Revenue(ctx, query)
+}
+
+func (ru *reportUsecaseImpl) TopProducts(ctx context.Context, query *domain.ReportQuery) ([]*domain.TopProduct, error) {
+	if err := normalizeReportRange(query); err != nil {
+		return nil, err
+	}
+	switch query.SortBy {
+	case "":
+		query.SortBy = domain.TopProductsByUnits
+	case domain.TopProductsByUnits, domain.TopProductsByRevenue:
+	default:
+		return nil, fmt.Errorf("%w: by must be units or revenue", domain.ErrInvalidInput)
+	}
+	query.Limit = clampLimit(query.Limit, defaultTopProductsLimit, maxTopProductsLimit)
+	return ru.reportRepo.TopProducts(ctx, query)
+}
+
+func (ru *reportUsecaseImpl) OrderValue(ctx context.Context, query *domain.ReportQuery) (*domain.OrderValueSummary, error) {
+	if err := normalizeReportRange(query); err != nil {
+		return nil, err
+	}
+	return ru.reportRepo.OrderValue(ctx, query)
+}
+
+func (ru *reportUsecaseImpl) CustomerMix(ctx context.Context, query *domain.ReportQuery) (*domain.CustomerMix, error) {
+	if err := normalizeReportRange(query); err != nil {
+		return nil, err
+	}
+	return ru.reportRepo.CustomerMix(ctx, query)
+}
+
+// LowStock lists products at or below threshold units. A negative threshold
+// uses the reorder threshold of each product.
+func (ru *reportUsecaseImpl) LowStock(ctx context.Context, threshold int, limit int) ([]*domain.LowStockItem, error) {
+	return ru.reportRepo.LowStock(ctx, threshold, clampLimit(limit, defaultLowStockLimit, maxLowStockLimit))
+}
+
+// normalizeReportRange defaults a report to the last 30 days and rejects
+// inverted ranges.
+func normalizeReportRange(query *domain.ReportQuery) error {
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -defaultReportDays)
+	}
+	if query.From.After(query.To) {
+		return fmt.Errorf("%w: from is after to", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func clampLimit(limit, defaultLimit, maxLimit int) int {
+	if limit < 1 {
+		return defaultLimit
+	}
+	return min(limit, maxLimit)
+}
+
+// maxPage caps page numbers so the offset of a page, (page-1)*limit, cannot
+// overflow. No listing is paged that deep in practice.
+const maxPage = 10000
+
+func clampPage(page int) int {
+	if page < 1 {
+		return 1
+	}
+	return min(page, maxPage)
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) Revenue(ctx context.Context, query *domain.ReportQuery) ([]*domain.RevenuePoint, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RevenuePoint), args.Error(1)
+}
+
+func (m *MockReportRepository) TopProducts(ctx context.Context, query *domain.ReportQuery) ([]*domain.TopProduct, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TopProduct), args.Error(1)
+}
+
+func (m *MockReportRepository) OrderValue(ctx context.Context, query *domain.ReportQuery) (*domain.OrderValueSummary, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OrderValueSummary), args.Error(1)
+}
+
+func (m *MockReportRepository) CustomerMix(ctx context.Context, query *domain.ReportQuery) (*domain.CustomerMix, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CustomerMix), args.Error(1)
+}
+
+func (m *MockReportRepository) LowStock(ctx context.Context, threshold int, limit int) ([]*domain.LowStockItem, error) {
+	args := m.Called(ctx, threshold, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LowStockItem), args.Error(1)
+}
+
+func TestReportUsecase_Revenue(t *testing.T) {
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         *domain.ReportQuery
+		mockSetup     func(*MockReportRepository)
+		expectedError error
+	}{
+		{
+			name:  "Success - Defaults to a daily report over the last 30 days",
+			query: &domain.ReportQuery{To: to},
+			mockSetup: func(rr *MockReportRepository) {
+				rr.On("Revenue", mock.Anything, mock.MatchedBy(func(query *domain.ReportQuery) bool {
+					return query.Interval == domain.ReportIntervalDay && query.From.Equal(to.AddDate(0, 0, -30))
+				})).Return([]*domain.RevenuePoint{}, nil)
+			},
+		},
+		{
+			name:          "Error - Unknown interval",
+			query:         &domain.ReportQuery{Interval: "hour"},
+			mockSetup:     func(rr *MockReportRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - From after to",
+			query:         &domain.ReportQuery{From: to.AddDate(0, 0, 1), To: to},
+			mockSetup:     func(rr *MockReportRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Daily report over more than a year",
+			query:         &domain.ReportQuery{From: to.AddDate(-2, 0, 0), To: to, Interval: domain.ReportIntervalDay},
+			mockSetup:     func(rr *MockReportRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reportRepo := new(MockReportRepository)
+			tt.mockSetup(reportRepo)
+			usecase := NewReportUsecase(reportRepo)
+
+			// Act
+			points, err := usecase.Revenue(context.Background(), tt.query)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, points)
+				reportRepo.AssertNotCalled(t, "Revenue", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			reportRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportUsecase_TopProducts(t *testing.T) {
+	// Arrange
+	reportRepo := new(MockReportRepository)
+	reportRepo.On("TopProducts", mock.Anything, mock.MatchedBy(func(query *domain.ReportQuery) bool {
+		return query.SortBy == domain.TopProductsByUnits && query.Limit == maxTopProductsLimit
+	})).Return([]*domain.TopProduct{}, nil)
+	usecase := NewReportUsecase(reportRepo)
+
+	// Act
+	_, err := usecase.TopProducts(context.Background(), &domain.ReportQuery{Limit: 1000})
+	_, invalidErr := usecase.TopProducts(context.Background(), &domain.ReportQuery{SortBy: "margin"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, invalidErr, domain.ErrInvalidInput)
+	reportRepo.AssertExpectations(t)
+}
+
+func TestReportUsecase_LowStock(t *testing.T) {
+	// Arrange
+	reportRepo := new(MockReportRepository)
+	reportRepo.On("LowStock", mock.Anything, -1, defaultLowStockLimit).Return([]*domain.LowStockItem{}, nil)
+	usecase := NewReportUsecase(reportRepo)
+
+	// Act
+	_, err := usecase.LowStock(context.Background(), -1, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	reportRepo.AssertExpectations(t)
+}
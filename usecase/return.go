@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"math"
+	"time"
+)
+
+var _ domain.ReturnUsecase = (*returnUsecaseImpl)(nil)
+
+type returnUsecaseImpl struct {
+	returnRepo domain.ReturnRepository
+	orderRepo  domain.OrderRepository
+	gateway    domain.PaymentGateway
+	inventory  domain.InventoryUsecase
+}
+
+func NewReturnUsecase(
+	returnRepo domain.ReturnRepository,
+	orderRepo domain.OrderRepository,
+	gateway domain.PaymentGateway,
+	inventory domain.InventoryUsecase,
+) domain.ReturnUsecase {
+	return &returnUsecaseImpl{
+		returnRepo: returnRepo,
+		orderRepo:  orderRepo,
+		gateway:    gateway,
+		inventory:  inventory,
+	}
+}
+
+func (ru *returnUsecaseImpl) RequestReturn(ctx context.Context, actor *domain.Actor, orderID string, req *domain.ReturnRequest) (*domain.Return, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", domain.ErrInvalidInput)
+	}
+
+	order, err := ru.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() && order.CustomerId != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	if order.Status == domain.OrderStatusRefunded {
+		return nil, fmt.Errorf("%w: order is already fully refunded", domain.ErrConflict)
+	}
+
+	returnable, err := ru.returnableQuantities(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*domain.ReturnItem, 0, len(req.Items))
+	total := 0.0
+	for _, itemReq := range req.Items {
+		if itemReq.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity for product %s must be positive", domain.ErrInvalidInput, itemReq.ProductID)
+		}
+		line := returnable[lineKey(itemReq.ProductID, itemReq.VariantID)]
+		if line == nil {
+			line = &returnableLine{}
+		}
+		if line.quantity < itemReq.Quantity {
+			return nil, fmt.Errorf("%w: only %d of product %s can be returned", domain.ErrInvalidInput, line.quantity, itemReq.ProductID)
+		}
+		if line.unitPrice == nil {
+			// Refunding at today's catalog price could pay out more than the
+			// customer paid.
+			return nil, fmt.Errorf("%w: order %s does not record the price paid for product %s", domain.ErrConflict, orderID, itemReq.ProductID)
+		}
+		line.quantity -= itemReq.Quantity
+
+		subtotal := *line.unitPrice * float64(itemReq.Quantity)
+		items = append(items, &domain.ReturnItem{
+			ProductID: itemReq.ProductID,
+			VariantID: itemReq.VariantID,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: *line.unitPrice,
+			Subtotal:  subtotal,
+		})
+		total += subtotal
+	}
+
+	now := time.Now()
+	ret := &domain.Return{
+		OrderID:      orderID,
+		CustomerID:   order.CustomerId,
+		Items:        items,
+		Reason:       req.Reason,
+		Status:       domain.ReturnStatusRequested,
+		RefundAmount: capRefund(total, order),
+		History: []*domain.ReturnEvent{
+			{Status: domain.ReturnStatusRequested, Actor: actor.Email, Note: req.Reason, At: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	created, err := ru.returnRepo.Create(ctx, ret)
+	if err != nil {
+		return nil, err
+	}
+	// The quantities were checked against the returns read above. If another
+	// return was requested since, its claim moved the version on first and
+	// this one is withdrawn, so no unit is ever returned twice.
+	if err := ru.orderRepo.ClaimReturn(ctx, orderID, order.ReturnsVersion); err != nil {
+		if deleteErr := ru.returnRepo.Delete(ctx, created.Id.Hex()); deleteErr != nil {
+			logger.Error("Failed to withdraw return that lost its claim", "return_id", created.Id.Hex(), "error", deleteErr)
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+func (ru *returnUsecaseImpl) GetByID(ctx context.Context, actor *domain.Actor, id string) (*domain.Return, error) {
+	ret, err := ru.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() && ret.CustomerID != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	return ret, nil
+}
+
+func (ru *returnUsecaseImpl) GetByOrderID(ctx context.Context, actor *domain.Actor, orderID string) ([]*domain.Return, error) {
+	order, err := ru.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() && order.CustomerId != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	return ru.returnRepo.GetByOrderID(ctx, orderID)
+}
+
+func (ru *returnUsecaseImpl) GetAll(ctx context.Context, status string) ([]*domain.Return, error) {
+	return ru.returnRepo.GetAll(ctx, status)
+}
+
+// refundClaimTimeout is how long a return may stay refunding before another
+// approval assumes the one that claimed it has died and takes over.
+const refundClaimTimeout = 5 * time.Minute
+
+// Approve restocks the returned products and refunds the customer. The return
+// is first claimed by moving it to refunding, so concurrent approvals cannot
+// both refund it. A failed step moves it back to approved, and approving it
+// again retries only the steps that have not completed: items are restocked
+// once, and the return ID is the idempotency key of the refund.
+func (ru *returnUsecaseImpl) Approve(ctx context.Context, actor *domain.Actor, id string, decision *domain.ReturnDecision) (*domain.Return, error) {
+	ret, err := ru.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ret.Status {
+	case domain.ReturnStatusRequested:
+		ru.record(ret, domain.ReturnStatusApproved, actor.Email, decision.Note)
+		if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRequested); err != nil {
+			return nil, err
+		}
+	case domain.ReturnStatusApproved:
+		logger.Info("Retrying approval of return", "return_id", id)
+	case domain.ReturnStatusRefunding:
+		if time.Since(ret.UpdatedAt) < refundClaimTimeout {
+			return nil, fmt.Errorf("%w: return is already being refunded", domain.ErrConflict)
+		}
+		logger.Warn("Taking over stale refund of return", "return_id", id, "claimed_at", ret.UpdatedAt)
+		ru.record(ret, domain.ReturnStatusApproved, actor.Email, "refund claim expired")
+		if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRefunding); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: return is already %s", domain.ErrConflict, ret.Status)
+	}
+
+	ru.record(ret, domain.ReturnStatusRefunding, actor.Email, "")
+	if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusApproved); err != nil {
+		return nil, err
+	}
+
+	if !ret.Restocked {
+		if err := ru.inventory.RecordReturn(ctx, actor, ret); err != nil {
+			ru.release(ctx, ret, actor, "restock failed: "+err.Error())
+			return nil, err
+		}
+		ret.Restocked = true
+		if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRefunding); err != nil {
+			logger.Error("Items restocked but return was not updated", "return_id", id, "error", err)
+			return nil, err
+		}
+	}
+
+	if ret.RefundID == "" {
+		order, err := ru.orderRepo.GetByID(ctx, ret.OrderID)
+		if err != nil {
+			ru.release(ctx, ret, actor, "order not found")
+			return nil, err
+		}
+		amount := capRefund(ret.RefundAmount, order)
+		if amount <= 0 {
+			ru.release(ctx, ret, actor, "nothing left to refund")
+			return nil, fmt.Errorf("%w: nothing left to refund on order %s", domain.ErrConflict, ret.OrderID)
+		}
+
+		refundID, err := ru.gateway.Refund(ctx, ret.Id.Hex(), ret.OrderID, amount)
+		if err != nil {
+			ru.release(ctx, ret, actor, "refund failed: "+err.Error())
+			return nil, err
+		}
+		ret.RefundAmount = amount
+		ret.RefundID = refundID
+		if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRefunding); err != nil {
+			logger.Error("Refund issued but return was not updated", "return_id", id, "refund_id", refundID, "error", err)
+			return nil, err
+		}
+	}
+
+	if _, err := ru.orderRepo.ApplyRefund(ctx, ret.OrderID, ret.RefundID, ret.RefundAmount); err != nil {
+		logger.Error("Refund issued but order was not updated", "return_id", id, "refund_id", ret.RefundID, "error", err)
+		ru.release(ctx, ret, actor, "order not updated: "+err.Error())
+		return nil, err
+	}
+
+	ru.record(ret, domain.ReturnStatusRefunded, actor.Email, "refund "+ret.RefundID)
+	if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRefunding); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// release moves a claimed return back to approved after a failed step, so it
+// can be approved again.
+func (ru *returnUsecaseImpl) release(ctx context.Context, ret *domain.Return, actor *domain.Actor, note string) {
+	ru.record(ret, domain.ReturnStatusApproved, actor.Email, note)
+	if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRefunding); err != nil {
+		logger.Error("Failed to release return after a failed approval", "return_id", ret.Id.Hex(), "error", err)
+	}
+}
+
+func (ru *returnUsecaseImpl) Reject(ctx context.Context, actor *domain.Actor, id string, decision *domain.ReturnDecision) (*domain.Return, error) {
+	ret, err := ru.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != domain.ReturnStatusRequested {
+		return nil, fmt.Errorf("%w: return is already %s", domain.ErrConflict, ret.Status)
+	}
+
+	ru.record(ret, domain.ReturnStatusRejected, actor.Email, decision.Note)
+	if err := ru.returnRepo.Replace(ctx, ret, domain.ReturnStatusRequested); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// returnableLine is what is left to return of one order line. unitPrice is
+// nil for orders placed without items, which are refunded at the current
+// product price.
+type returnableLine struct {
+	quantity  int
+	unitPrice *float64
+}
+
+func lineKey(productID, variantID string) string {
+	return productID + "/" + variantID
+}
+
+// returnableQuantities counts how many units of each order line are not yet
+// covered by a pending or completed return.
+func (ru *returnUsecaseImpl) returnableQuantities(ctx context.Context, order *domain.Order) (map[string]*returnableLine, error) {
+	returnable := make(map[string]*returnableLine)
+	if len(order.Items) > 0 {
+		for _, item := range order.Items {
+			key := lineKey(item.ProductID, item.VariantID)
+			if returnable[key] == nil {
+				unitPrice := item.UnitPrice
+				returnable[key] = &returnableLine{unitPrice: &unitPrice}
+			}
+			returnable[key].quantity += item.Quantity
+		}
+	} else {
+		for _, productID := range order.ProductIds {
+			key := lineKey(productID, "")
+			if returnable[key] == nil {
+				returnable[key] = &returnableLine{}
+			}
+			returnable[key].quantity++
+		}
+	}
+
+	existing, err := ru.returnRepo.GetByOrderID(ctx, order.Id.Hex())
+	if err != nil {
+		return nil, err
+	}
+	for _, ret := range existing {
+		if ret.Status == domain.ReturnStatusRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			if line := returnable[lineKey(item.ProductID, item.VariantID)]; line != nil {
+				line.quantity -= item.Quantity
+			}
+		}
+	}
+	return returnable, nil
+}
+
+func (ru *returnUsecaseImpl) record(ret *domain.Return, status, actor, note string) {
+	now := time.Now()
+	ret.Status = status
+	ret.UpdatedAt = now
+	ret.History = append(ret.History, &domain.ReturnEvent{
+		Status: status,
+		Actor:  actor,
+		Note:   note,
+		At:     now,
+	})
+}
+
+// capRefund never lets a refund exceed what is still unrefunded on the order.
+func capRefund(amount float64, order *domain.Order) float64 {
+	remaining := order.TotalAmount - order.RefundedAmount
+	return math.Max(0, math.Min(amount, remaining))
+}
@@ -0,0 +1,633 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"intern-project-v2/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) GetAll(ctx context.Context) ([]*domain.Order, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByID(ctx context.Context, id string) (*domain.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, order *domain.OrderRequest) (*domain.Order, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Update(ctx context.Context, id string, orderReq *domain.OrderRequest) (*domain.Order, error) {
+	args := m.Called(ctx, id, orderReq)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Delete(ctx context.Context, id string) (*domain.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ApplyRefund(ctx context.Context, id string, refundID string, amount float64) (*domain.Order, error) {
+	args := m.Called(ctx, id, refundID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Order, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Restore(ctx context.Context, id string) (*domain.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountOpenByCustomer(ctx context.Context, customerID string) (int64, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) HasPurchased(ctx context.Context, customerID string, productID string) (bool, error) {
+	args := m.Called(ctx, customerID, productID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByCustomer(ctx context.Context, query *domain.OrderHistoryQuery) ([]*domain.Order, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOrderRepository) SetAllocations(ctx context.Context, id string, allocations []*domain.StockAllocation) error {
+	args := m.Called(ctx, id, allocations)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) ClaimReturn(ctx context.Context, id string, returnsVersion int) error {
+	args := m.Called(ctx, id, returnsVersion)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error) {
+	args := m.Called(ctx, customerID, pseudonym)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetAllByCustomer(ctx context.Context, customerID string) ([]*domain.Order, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Order), args.Error(1)
+}
+
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) GetAll(ctx context.Context) ([]*domain.Product, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByID(ctx context.Context, id string) (*domain.Product, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Create(ctx context.Context, product *domain.ProductRequest) (*domain.Product, error) {
+	args := m.Called(ctx, product)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, id string, productReq *domain.ProductRequest) (*domain.Product, error) {
+	args := m.Called(ctx, id, productReq)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, id string) (*domain.Product, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*domain.Product, error) {
+	args := m.Called(ctx, id, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetAllIncludingDeleted(ctx context.Context) ([]*domain.Product, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, id string) (*domain.Product, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Product, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByCategoryIDs(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	args := m.Called(ctx, categoryIDs)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RemoveCategory(ctx context.Context, categoryID string) (int64, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProductRepository) SetVariants(ctx context.Context, id string, current *domain.Product, options []*domain.ProductOption, variants []*domain.ProductVariant) (*domain.Product, error) {
+	args := m.Called(ctx, id, current, options, variants)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateVariant(ctx context.Context, id string, variant *domain.ProductVariant) (*domain.Product, error) {
+	args := m.Called(ctx, id, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AddImage(ctx context.Context, id string, image *domain.ProductImage) (*domain.Product, error) {
+	args := m.Called(ctx, id, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RemoveImage(ctx context.Context, id string, imageID string) (*domain.Product, error) {
+	args := m.Called(ctx, id, imageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) SetImages(ctx context.Context, id string, images []*domain.ProductImage) (*domain.Product, error) {
+	args := m.Called(ctx, id, images)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySKUs(ctx context.Context, skus []string) ([]*domain.Product, error) {
+	args := m.Called(ctx, skus)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) UpsertBySKU(ctx context.Context, products []*domain.ProductRequest) (int64, int64, error) {
+	args := m.Called(ctx, products)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductRepository) Each(ctx context.Context, fn func(*domain.Product) error) error {
+	args := m.Called(ctx)
+	if products, ok := args.Get(0).([]*domain.Product); ok {
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustRating(ctx context.Context, id string, sumDelta int, countDelta int) error {
+	args := m.Called(ctx, id, sumDelta, countDelta)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) SetReorderThreshold(ctx context.Context, id string, threshold *int) (*domain.Product, error) {
+	args := m.Called(ctx, id, threshold)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustWarehouseStock(ctx context.Context, id string, variantID string, warehouseID string, delta int) (*domain.Product, error) {
+	args := m.Called(ctx, id, variantID, warehouseID, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) TransferStock(ctx context.Context, id string, variantID string, from string, to string, quantity int) (*domain.Product, error) {
+	args := m.Called(ctx, id, variantID, from, to, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustVariantStock(ctx context.Context, id string, variantID string, delta int) (*domain.Product, error) {
+	args := m.Called(ctx, id, variantID, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+type MockReturnRepository struct {
+	mock.Mock
+}
+
+func (m *MockReturnRepository) Create(ctx context.Context, ret *domain.Return) (*domain.Return, error) {
+	args := m.Called(ctx, ret)
+	if fn, ok := args.Get(0).(func(context.Context, *domain.Return) *domain.Return); ok {
+		return fn(ctx, ret), args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Return), args.Error(1)
+}
+
+func (m *MockReturnRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockReturnRepository) GetByID(ctx context.Context, id string) (*domain.Return, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Return), args.Error(1)
+}
+
+func (m *MockReturnRepository) GetByOrderID(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]*domain.Return), args.Error(1)
+}
+
+func (m *MockReturnRepository) GetAll(ctx context.Context, status string) ([]*domain.Return, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]*domain.Return), args.Error(1)
+}
+
+func (m *MockReturnRepository) Replace(ctx context.Context, ret *domain.Return, expectedStatus string) error {
+	args := m.Called(ctx, ret, expectedStatus)
+	return args.Error(0)
+}
+
+func (m *MockReturnRepository) GetByCustomer(ctx context.Context, customerID string) ([]*domain.Return, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Return), args.Error(1)
+}
+
+func (m *MockReturnRepository) AnonymizeCustomer(ctx context.Context, customerID string, email string, pseudonym string) (int64, error) {
+	args := m.Called(ctx, customerID, email, pseudonym)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockPaymentGateway struct {
+	mock.Mock
+}
+
+func (m *MockPaymentGateway) Refund(ctx context.Context, idempotencyKey string, orderID string, amount float64) (string, error) {
+	args := m.Called(ctx, idempotencyKey, orderID, amount)
+	return args.String(0), args.Error(1)
+}
+
+func TestReturnUsecase_RequestReturn(t *testing.T) {
+	orderID := bson.NewObjectID()
+	returnID := bson.NewObjectID()
+	customer := &domain.Actor{CustomerID: "customer-1", Email: "john@example.com", Role: domain.RoleCustomer}
+	order := &domain.Order{
+		Id:          orderID,
+		CustomerId:  "customer-1",
+		ProductIds:  []string{"p1", "p1", "p2"},
+		Items:       []*domain.OrderItem{{ProductID: "p1", Quantity: 2, UnitPrice: 100}, {ProductID: "p2", Quantity: 1, UnitPrice: 50}},
+		TotalAmount: 250,
+		Status:      domain.OrderStatusPending,
+	}
+	// Orders placed before plain product ids were stored as items have no
+	// prices to refund at.
+	unpriced := &domain.Order{
+		Id:          orderID,
+		CustomerId:  "customer-1",
+		ProductIds:  []string{"p1", "p1", "p2"},
+		TotalAmount: 250,
+		Status:      domain.OrderStatusPending,
+	}
+
+	tests := []struct {
+		name          string
+		actor         *domain.Actor
+		req           *domain.ReturnRequest
+		mockSetup     func(*MockReturnRepository, *MockOrderRepository)
+		expectedTotal float64
+		expectedError error
+	}{
+		{
+			name:  "Success - Return part of an order",
+			actor: customer,
+			req:   &domain.ReturnRequest{Items: []*domain.ReturnItemRequest{{ProductID: "p1", Quantity: 1}}, Reason: "Too small"},
+			mockSetup: func(rr *MockReturnRepository, or *MockOrderRepository) {
+				or.On("GetByID", mock.Anything, orderID.Hex()).Return(order, nil)
+				rr.On("GetByOrderID", mock.Anything, orderID.Hex()).Return([]*domain.Return{}, nil)
+				rr.On("Create", mock.Anything, mock.Anything).Return(func(_ context.Context, ret *domain.Return) *domain.Return { return ret }, nil)
+				or.On("ClaimReturn", mock.Anything, orderID.Hex(), 0).Return(nil)
+			},
+			expectedTotal: 100,
+		},
+		{
+			name:  "Error - Another return was requested in the meantime",
+			actor: customer,
+			req:   &domain.ReturnRequest{Items: []*domain.ReturnItemRequest{{ProductID: "p1", Quantity: 1}}},
+			mockSetup: func(rr *MockReturnRepository, or *MockOrderRepository) {
+				or.On("GetByID", mock.Anything, orderID.Hex()).Return(order, nil)
+				rr.On("GetByOrderID", mock.Anything, orderID.Hex()).Return([]*domain.Return{}, nil)
+				rr.On("Create", mock.Anything, mock.Anything).Return(func(_ context.Context, ret *domain.Return) *domain.Return {
+					ret.Id = returnID
+					return ret
+				}, nil)
+				or.On("ClaimReturn", mock.Anything, orderID.Hex(), 0).Return(domain.ErrConflict)
+				rr.On("Delete", mock.Anything, returnID.Hex()).Return(nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:  "Error - Order without the prices paid",
+			actor: customer,
+			req:   &domain.ReturnRequest{Items: []*domain.ReturnItemRequest{{ProductID: "p1", Quantity: 1}}},
+			mockSetup: func(rr *MockReturnRepository, or *MockOrderRepository) {
+				or.On("GetByID", mock.Anything, orderID.Hex()).Return(unpriced, nil)
+				rr.On("GetByOrderID", mock.Anything, orderID.Hex()).Return([]*domain.Return{}, nil)
+			},
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:  "Error - Quantity already covered by a pending return",
+			actor: customer,
+			req:   &domain.ReturnRequest{Items: []*domain.ReturnItemRequest{{ProductID: "p1", Quantity: 2}}},
+			mockSetup: func(rr *MockReturnRepository, or *MockOrderRepository) {
+				or.On("GetByID", mock.Anything, orderID.Hex()).Return(order, nil)
+				rr.On("GetByOrderID", mock.Anything, orderID.Hex()).Return([]*domain.Return{
+					{Status: domain.ReturnStatusRequested, Items: []*domain.ReturnItem{{ProductID: "p1", Quantity: 1}}},
+				}, nil)
+			},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:  "Error - Order belongs to another customer",
+			actor: &domain.Actor{CustomerID: "customer-2", Role: domain.RoleCustomer},
+			req:   &domain.ReturnRequest{Items: []*domain.ReturnItemRequest{{ProductID: "p1", Quantity: 1}}},
+			mockSetup: func(rr *MockReturnRepository, or *MockOrderRepository) {
+				or.On("GetByID", mock.Anything, orderID.Hex()).Return(order, nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			returnRepo := new(MockReturnRepository)
+			orderRepo := new(MockOrderRepository)
+			tt.mockSetup(returnRepo, orderRepo)
+
+			usecase := NewReturnUsecase(returnRepo, orderRepo, new(MockPaymentGateway), nil)
+
+			// Act
+			result, err := usecase.RequestReturn(context.Background(), tt.actor, orderID.Hex(), tt.req)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.ReturnStatusRequested, result.Status)
+				assert.Equal(t, tt.expectedTotal, result.RefundAmount)
+				assert.Len(t, result.History, 1)
+			}
+
+			returnRepo.AssertExpectations(t)
+			orderRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReturnUsecase_Approve(t *testing.T) {
+	admin := &domain.Actor{CustomerID: "admin-1", Email: "admin@example.com", Role: domain.RoleAdmin}
+	order := &domain.Order{Id: bson.NewObjectID(), TotalAmount: 250, RefundedAmount: 200}
+
+	t.Run("Success - Restocks and refunds at most the remaining amount", func(t *testing.T) {
+		returnRepo := new(MockReturnRepository)
+		orderRepo := new(MockOrderRepository)
+		productRepo := new(MockProductRepository)
+		gateway := new(MockPaymentGateway)
+
+		ret := &domain.Return{
+			Id:           bson.NewObjectID(),
+			OrderID:      order.Id.Hex(),
+			Items:        []*domain.ReturnItem{{ProductID: "p1", Quantity: 1, UnitPrice: 100, Subtotal: 100}},
+			Status:       domain.ReturnStatusRequested,
+			RefundAmount: 100,
+		}
+		returnRepo.On("GetByID", mock.Anything, ret.Id.Hex()).Return(ret, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusRequested).Return(nil).Once()
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusApproved).Return(nil).Once()
+		productRepo.On("AdjustStock", mock.Anything, "p1", 1).Return(&domain.Product{Stock: 3}, nil)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("Record", mock.Anything, mock.MatchedBy(func(movement *domain.StockMovement) bool {
+			return movement.Type == domain.StockMovementReturn && movement.Reference == ret.Id.Hex() && movement.StockAfter == 3
+		})).Return(&domain.StockMovement{}, nil)
+		orderRepo.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+		gateway.On("Refund", mock.Anything, ret.Id.Hex(), order.Id.Hex(), 50.0).Return("rf_1", nil)
+		orderRepo.On("ApplyRefund", mock.Anything, order.Id.Hex(), "rf_1", 50.0).Return(order, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusRefunding).Return(nil).Times(3)
+
+		inventory := NewInventoryUsecase(inventoryRepo, productRepo, nil, nil, nil, nil, nil)
+		usecase := NewReturnUsecase(returnRepo, orderRepo, gateway, inventory)
+		result, err := usecase.Approve(context.Background(), admin, ret.Id.Hex(), &domain.ReturnDecision{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ReturnStatusRefunded, result.Status)
+		assert.Equal(t, 50.0, result.RefundAmount)
+		assert.Equal(t, "rf_1", result.RefundID)
+		assert.True(t, result.Restocked)
+		assert.Len(t, result.History, 3)
+		returnRepo.AssertExpectations(t)
+		productRepo.AssertExpectations(t)
+		inventoryRepo.AssertExpectations(t)
+		gateway.AssertExpectations(t)
+	})
+
+	t.Run("Success - Retry after the refund was issued only updates the order", func(t *testing.T) {
+		returnRepo := new(MockReturnRepository)
+		orderRepo := new(MockOrderRepository)
+		gateway := new(MockPaymentGateway)
+
+		ret := &domain.Return{
+			Id:           bson.NewObjectID(),
+			OrderID:      order.Id.Hex(),
+			Status:       domain.ReturnStatusApproved,
+			RefundAmount: 50,
+			RefundID:     "rf_1",
+			Restocked:    true,
+		}
+		returnRepo.On("GetByID", mock.Anything, ret.Id.Hex()).Return(ret, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusApproved).Return(nil).Once()
+		orderRepo.On("ApplyRefund", mock.Anything, order.Id.Hex(), "rf_1", 50.0).Return(order, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusRefunding).Return(nil).Once()
+
+		usecase := NewReturnUsecase(returnRepo, orderRepo, gateway, nil)
+		result, err := usecase.Approve(context.Background(), admin, ret.Id.Hex(), &domain.ReturnDecision{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ReturnStatusRefunded, result.Status)
+		returnRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+		gateway.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - Failed refund releases the return to approved", func(t *testing.T) {
+		returnRepo := new(MockReturnRepository)
+		orderRepo := new(MockOrderRepository)
+		gateway := new(MockPaymentGateway)
+
+		ret := &domain.Return{
+			Id:           bson.NewObjectID(),
+			OrderID:      order.Id.Hex(),
+			Status:       domain.ReturnStatusApproved,
+			RefundAmount: 10,
+			Restocked:    true,
+		}
+		returnRepo.On("GetByID", mock.Anything, ret.Id.Hex()).Return(ret, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusApproved).Return(nil).Once()
+		orderRepo.On("GetByID", mock.Anything, order.Id.Hex()).Return(order, nil)
+		gateway.On("Refund", mock.Anything, ret.Id.Hex(), order.Id.Hex(), 10.0).Return("", errors.New("gateway down"))
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusRefunding).Return(nil).Once()
+
+		usecase := NewReturnUsecase(returnRepo, orderRepo, gateway, nil)
+		result, err := usecase.Approve(context.Background(), admin, ret.Id.Hex(), &domain.ReturnDecision{})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, domain.ReturnStatusApproved, ret.Status)
+		returnRepo.AssertExpectations(t)
+		orderRepo.AssertNotCalled(t, "ApplyRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - Return claimed by another approval", func(t *testing.T) {
+		returnRepo := new(MockReturnRepository)
+		gateway := new(MockPaymentGateway)
+
+		ret := &domain.Return{
+			Id:        bson.NewObjectID(),
+			OrderID:   order.Id.Hex(),
+			Status:    domain.ReturnStatusApproved,
+			Restocked: true,
+		}
+		returnRepo.On("GetByID", mock.Anything, ret.Id.Hex()).Return(ret, nil)
+		returnRepo.On("Replace", mock.Anything, ret, domain.ReturnStatusApproved).Return(domain.ErrConflict).Once()
+
+		usecase := NewReturnUsecase(returnRepo, new(MockOrderRepository), gateway, nil)
+		result, err := usecase.Approve(context.Background(), admin, ret.Id.Hex(), &domain.ReturnDecision{})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, result)
+		gateway.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - Refund in progress", func(t *testing.T) {
+		returnRepo := new(MockReturnRepository)
+
+		ret := &domain.Return{
+			Id:        bson.NewObjectID(),
+			OrderID:   order.Id.Hex(),
+			Status:    domain.ReturnStatusRefunding,
+			UpdatedAt: time.Now(),
+		}
+		returnRepo.On("GetByID", mock.Anything, ret.Id.Hex()).Return(ret, nil)
+
+		usecase := NewReturnUsecase(returnRepo, new(MockOrderRepository), new(MockPaymentGateway), nil)
+		result, err := usecase.Approve(context.Background(), admin, ret.Id.Hex(), &domain.ReturnDecision{})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, result)
+		returnRepo.AssertNotCalled(t, "Replace", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"strings"
+	"time"
+)
+
+var _ domain.ReviewUsecase = (*reviewUsecaseImpl)(nil)
+
+const (
+	maxReviewTitle = 120
+	maxReviewBody  = 5000
+)
+
+type reviewUsecaseImpl struct {
+	reviewRepo  domain.ReviewRepository
+	productRepo domain.ProductRepository
+	orderRepo   domain.OrderRepository
+}
+
+func NewReviewUsecase(
+	reviewRepo domain.ReviewRepository,
+	productRepo domain.ProductRepository,
+	orderRepo domain.OrderRepository,
+) domain.ReviewUsecase {
+	return &reviewUsecaseImpl{
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+	}
+}
+
+// GetByProduct lists the published reviews of a product, newest first.
+func (ru *reviewUsecaseImpl) GetByProduct(ctx context.Context, productID string) ([]*domain.Review, error) {
+	if _, err := ru.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return ru.reviewRepo.GetByProduct(ctx, productID, domain.ReviewStatusPublished)
+}
+
+func (ru *reviewUsecaseImpl) GetAll(ctx context.Context, status string) ([]*domain.Review, error) {
+	if status != "" && status != domain.ReviewStatusPublished && status != domain.ReviewStatusHidden {
+		return nil, fmt.Errorf("%w: unknown review status %q", domain.ErrInvalidInput, status)
+	}
+	return ru.reviewRepo.GetAll(ctx, status)
+}
+
+// Submit posts the caller's review of a product, or edits it if they already
+// reviewed the product. The review is marked as a verified purchase when one
+// of the customer's orders contains the product.
+func (ru *reviewUsecaseImpl) Submit(ctx context.Context, actor *domain.Actor, productID string, reviewReq *domain.ReviewRequest) (*domain.Review, error) {
+	if actor.CustomerID == "" {
+		return nil, fmt.Errorf("%w: only customers can review products", domain.ErrForbidden)
+	}
+	if err := validateReview(reviewReq); err != nil {
+		return nil, err
+	}
+	if _, err := ru.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	existing, err := ru.reviewRepo.GetByCustomerAndProduct(ctx, actor.CustomerID, productID)
+	if err == nil {
+		return ru.edit(ctx, existing, reviewReq)
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	verified, err := ru.orderRepo.HasPurchased(ctx, actor.CustomerID, productID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	review, err := ru.reviewRepo.Create(ctx, &domain.Review{
+		ProductID:  productID,
+		CustomerID: actor.CustomerID,
+		Rating:     reviewReq.Rating,
+		Title:      strings.TrimSpace(reviewReq.Title),
+		Body:       strings.TrimSpace(reviewReq.Body),
+		Verified:   verified,
+		Status:     domain.ReviewStatusPublished,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ru.adjustRating(ctx, nil, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (ru *reviewUsecaseImpl) Update(ctx context.Context, actor *domain.Actor, id string, reviewReq *domain.ReviewRequest) (*domain.Review, error) {
+	if err := validateReview(reviewReq); err != nil {
+		return nil, err
+	}
+	review, err := ru.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.CustomerID != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	return ru.edit(ctx, review, reviewReq)
+}
+
+// Delete removes a review. Customers may delete their own reviews, admins any.
+func (ru *reviewUsecaseImpl) Delete(ctx context.Context, actor *domain.Actor, id string) (*domain.Review, error) {
+	review, err := ru.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() && review.CustomerID != actor.CustomerID {
+		return nil, domain.ErrForbidden
+	}
+	deleted, err := ru.reviewRepo.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ru.adjustRating(ctx, deleted, nil); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// Moderate publishes or hides a review. Hidden reviews stop counting towards
+// the product rating until they are published again.
+func (ru *reviewUsecaseImpl) Moderate(ctx context.Context, actor *domain.Actor, id string, moderation *domain.ReviewModeration) (*domain.Review, error) {
+	if moderation.Status != domain.ReviewStatusPublished && moderation.Status != domain.ReviewStatusHidden {
+		return nil, fmt.Errorf("%w: status must be %s or %s", domain.ErrInvalidInput, domain.ReviewStatusPublished, domain.ReviewStatusHidden)
+	}
+	review, err := ru.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *review
+	review.Status = moderation.Status
+	review.ModerationNote = strings.TrimSpace(moderation.Note)
+	review.ModeratedBy = actor.Email
+	review.UpdatedAt = time.Now()
+	if review, err = ru.reviewRepo.Replace(ctx, review); err != nil {
+		return nil, err
+	}
+	if err := ru.adjustRating(ctx, &before, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// edit applies a new rating and text to an existing review. A review that a
+// moderator hid stays hidden.
+func (ru *reviewUsecaseImpl) edit(ctx context.Context, review *domain.Review, reviewReq *domain.ReviewRequest) (*domain.Review, error) {
+	before := *review
+	review.Rating = reviewReq.Rating
+	review.Title = strings.TrimSpace(reviewReq.Title)
+	review.Body = strings.TrimSpace(reviewReq.Body)
+	review.UpdatedAt = time.Now()
+	if !review.Verified {
+		verified, err := ru.orderRepo.HasPurchased(ctx, review.CustomerID, review.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		review.Verified = verified
+	}
+	review, err := ru.reviewRepo.Replace(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	if err := ru.adjustRating(ctx, &before, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// adjustRating applies the difference between two versions of a review to
+// the product rating; either version may be nil.
+func (ru *reviewUsecaseImpl) adjustRating(ctx context.Context, before, after *domain.Review) error {
+	sumBefore, countBefore := ratingContribution(before)
+	sumAfter, countAfter := ratingContribution(after)
+	if sumBefore == sumAfter && countBefore == countAfter {
+		return nil
+	}
+	review := after
+	if review == nil {
+		review = before
+	}
+	return ru.productRepo.AdjustRating(ctx, review.ProductID, sumAfter-sumBefore, countAfter-countBefore)
+}
+
+func ratingContribution(review *domain.Review) (sum int, count int) {
+	if review == nil || !review.Published() {
+		return 0, 0
+	}
+	return review.Rating, 1
+}
+
+func validateReview(reviewReq *domain.ReviewRequest) error {
+	switch {
+	case reviewReq.Rating < domain.MinRating || reviewReq.Rating > domain.MaxRating:
+		return fmt.Errorf("%w: rating must be between %d and %d", domain.ErrInvalidInput, domain.MinRating, domain.MaxRating)
+	case len(strings.TrimSpace(reviewReq.Title)) > maxReviewTitle:
+		return fmt.Errorf("%w: title is limited to %d characters", domain.ErrInvalidInput, maxReviewTitle)
+	case len(strings.TrimSpace(reviewReq.Body)) > maxReviewBody:
+		return fmt.Errorf("%w: review text is limited to %d characters", domain.ErrInvalidInput, maxReviewBody)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	args := m.Called(ctx, review)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByID(ctx context.Context, id string) (*domain.Review, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByCustomerAndProduct(ctx context.Context, customerID string, productID string) (*domain.Review, error) {
+	args := m.Called(ctx, customerID, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByProduct(ctx context.Context, productID string, status string) ([]*domain.Review, error) {
+	args := m.Called(ctx, productID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetAll(ctx context.Context, status string) ([]*domain.Review, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Replace(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	args := m.Called(ctx, review)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Delete(ctx context.Context, id string) (*domain.Review, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByCustomer(ctx context.Context, customerID string) ([]*domain.Review, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) AnonymizeCustomer(ctx context.Context, customerID string, pseudonym string) (int64, error) {
+	args := m.Called(ctx, customerID, pseudonym)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestReviewUsecase_Submit(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+	actor := &domain.Actor{CustomerID: customerID, Role: domain.RoleCustomer}
+	productID := bson.NewObjectID().Hex()
+
+	tests := []struct {
+		name             string
+		request          *domain.ReviewRequest
+		mockSetup        func(*MockReviewRepository, *MockProductRepository, *MockOrderRepository)
+		expectedVerified bool
+		expectedError    error
+	}{
+		{
+			name:    "Success - First review of a purchased product is verified",
+			request: &domain.ReviewRequest{Rating: 4, Title: " Good fit "},
+			mockSetup: func(rr *MockReviewRepository, pr *MockProductRepository, or *MockOrderRepository) {
+				pr.On("GetByID", mock.Anything, productID).Return(&domain.Product{}, nil)
+				rr.On("GetByCustomerAndProduct", mock.Anything, customerID, productID).Return(nil, domain.ErrNotFound)
+				or.On("HasPurchased", mock.Anything, customerID, productID).Return(true, nil)
+				rr.On("Create", mock.Anything, mock.MatchedBy(func(review *domain.Review) bool {
+					return review.Title == "Good fit" && review.Status == domain.ReviewStatusPublished
+				})).Return(&domain.Review{ProductID: productID, Rating: 4, Verified: true, Status: domain.ReviewStatusPublished}, nil)
+				pr.On("AdjustRating", mock.Anything, productID, 4, 1).Return(nil)
+			},
+			expectedVerified: true,
+		},
+		{
+			name:    "Success - Posting again edits the review and moves the rating by the difference",
+			request: &domain.ReviewRequest{Rating: 2},
+			mockSetup: func(rr *MockReviewRepository, pr *MockProductRepository, or *MockOrderRepository) {
+				existing := &domain.Review{ProductID: productID, CustomerID: customerID, Rating: 5, Verified: true, Status: domain.ReviewStatusPublished}
+				pr.On("GetByID", mock.Anything, productID).Return(&domain.Product{}, nil)
+				rr.On("GetByCustomerAndProduct", mock.Anything, customerID, productID).Return(existing, nil)
+				rr.On("Replace", mock.Anything, existing).Return(existing, nil)
+				pr.On("AdjustRating", mock.Anything, productID, -3, 0).Return(nil)
+			},
+			expectedVerified: true,
+		},
+		{
+			name:          "Error - Rating out of range",
+			request:       &domain.ReviewRequest{Rating: 6},
+			mockSetup:     func(rr *MockReviewRepository, pr *MockProductRepository, or *MockOrderRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reviewRepo := new(MockReviewRepository)
+			productRepo := new(MockProductRepository)
+			orderRepo := new(MockOrderRepository)
+			tt.mockSetup(reviewRepo, productRepo, orderRepo)
+			usecase := NewReviewUsecase(reviewRepo, productRepo, orderRepo)
+
+			// Act
+			review, err := usecase.Submit(context.Background(), actor, productID, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, review)
+				productRepo.AssertNotCalled(t, "AdjustRating", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVerified, review.Verified)
+			reviewRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+			orderRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReviewUsecase_Moderate(t *testing.T) {
+	productID := bson.NewObjectID().Hex()
+	admin := &domain.Actor{Email: "admin@example.com", Role: domain.RoleAdmin}
+
+	t.Run("Success - Hiding a review removes it from the rating", func(t *testing.T) {
+		// Arrange
+		review := &domain.Review{Id: bson.NewObjectID(), ProductID: productID, Rating: 3, Status: domain.ReviewStatusPublished}
+		reviewRepo := new(MockReviewRepository)
+		productRepo := new(MockProductRepository)
+		reviewRepo.On("GetByID", mock.Anything, review.Id.Hex()).Return(review, nil)
+		reviewRepo.On("Replace", mock.Anything, review).Return(review, nil)
+		productRepo.On("AdjustRating", mock.Anything, productID, -3, -1).Return(nil)
+		usecase := NewReviewUsecase(reviewRepo, productRepo, nil)
+
+		// Act
+		moderated, err := usecase.Moderate(context.Background(), admin, review.Id.Hex(), &domain.ReviewModeration{Status: domain.ReviewStatusHidden, Note: "spam"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ReviewStatusHidden, moderated.Status)
+		assert.Equal(t, admin.Email, moderated.ModeratedBy)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error - Unknown status", func(t *testing.T) {
+		// Arrange
+		usecase := NewReviewUsecase(new(MockReviewRepository), new(MockProductRepository), nil)
+
+		// Act
+		moderated, err := usecase.Moderate(context.Background(), admin, bson.NewObjectID().Hex(), &domain.ReviewModeration{Status: "deleted"})
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Nil(t, moderated)
+	})
+}
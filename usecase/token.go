@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"net/url"
+	"time"
+)
+
+// issueToken stores a new token for the customer and returns the link to
+// email, with the token in it, and when it expires.
+func issueToken(ctx context.Context, tokenRepo domain.AuthTokenRepository, policy *domain.AuthPolicy, cust *domain.Customer, purpose string) (string, time.Time, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", time.Time{}, err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	link, ttl := policy.PasswordResetURL, policy.ResetTokenTTL
+	if purpose == domain.TokenEmailVerification {
+		link, ttl = policy.VerificationURL, policy.VerificationTokenTTL
+	}
+	now := time.Now()
+	token := &domain.AuthToken{
+		CustomerID: cust.Id.Hex(),
+		Purpose:    purpose,
+		Hash:       hashToken(plain),
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+	}
+	if err := tokenRepo.Create(ctx, token); err != nil {
+		return "", time.Time{}, err
+	}
+	return withToken(link, plain), token.ExpiresAt, nil
+}
+
+// consumeToken uses up a token. Unknown, used and expired tokens are all
+// rejected the same way.
+func consumeToken(ctx context.Context, tokenRepo domain.AuthTokenRepository, purpose string, plain string, now time.Time) (*domain.AuthToken, error) {
+	if plain == "" {
+		return nil, fmt.Errorf("%w: token is required", domain.ErrInvalidInput)
+	}
+	token, err := tokenRepo.Consume(ctx, purpose, hashToken(plain), now)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: the link is invalid, used or expired", domain.ErrInvalidInput)
+	}
+	return token, err
+}
+
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func withToken(link string, token string) string {
+	target, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+	query := target.Query()
+	query.Set("token", token)
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// sessionTime is the current time at the precision of token timestamps, so
+// a token issued right after a password change is not rejected.
+func sessionTime() time.Time {
+	return time.Now().Truncate(time.Second)
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxVariants caps how many variants a set of options may generate.
+const maxVariants = 100
+
+// SetOptions replaces the options of a product and regenerates one variant per
+// combination of option values. Empty options turn the product back into a
+// single SKU. Stock is only changed through the inventory ledger, so changes
+// that would drop a variant, or the single SKU, while it holds stock are
+// refused; the stock has to be moved or written off first.
+func (pu *productUsecaseImpl) SetOptions(ctx context.Context, id string, optionsReq *domain.ProductOptionsRequest) (*domain.Product, error) {
+	product, err := pu.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	productOptions, err := normalizeOptions(optionsReq.Options)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Variants) == 0 && len(productOptions) > 0 && (product.Stock != 0 || holdsStock(product.WarehouseStock)) {
+		return nil, fmt.Errorf("%w: product %s has %d units in stock; set its stock to zero before adding options", domain.ErrConflict, id, product.Stock)
+	}
+
+	existing := make(map[string]*domain.ProductVariant, len(product.Variants))
+	for _, variant := range product.Variants {
+		if len(productOptions) == 0 {
+			if err := checkDroppable(variant); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Removing an option can make variants collide; only one of them is
+		// kept, preferably the one holding stock.
+		key := variantKey(productOptions, variant.Options)
+		dropped := variant
+		if kept, ok := existing[key]; !ok || checkDroppable(kept) == nil {
+			dropped, existing[key] = kept, variant
+		}
+		if dropped != nil {
+			if err := checkDroppable(dropped); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var variants []*domain.ProductVariant
+	for _, combination := range combinations(productOptions) {
+		key := variantKey(productOptions, combination)
+		if variant, ok := existing[key]; ok {
+			variant.Options = combination
+			variants = append(variants, variant)
+			delete(existing, key)
+			continue
+		}
+		variants = append(variants, &domain.ProductVariant{
+			Id:      bson.NewObjectID().Hex(),
+			SKU:     generateSKU(product.Name, productOptions, combination),
+			Options: combination,
+		})
+	}
+	// Variants whose option values were removed.
+	for _, variant := range existing {
+		if err := checkDroppable(variant); err != nil {
+			return nil, err
+		}
+	}
+	return pu.productRepo.SetVariants(ctx, id, product, productOptions, variants)
+}
+
+// checkDroppable refuses to drop a variant that still holds stock.
+func checkDroppable(variant *domain.ProductVariant) error {
+	if variant.Stock != 0 || holdsStock(variant.WarehouseStock) {
+		return fmt.Errorf("%w: variant %s has %d units in stock; set its stock to zero before removing it", domain.ErrConflict, variant.SKU, variant.Stock)
+	}
+	return nil
+}
+
+func holdsStock(warehouseStock map[string]int) bool {
+	for _, quantity := range warehouseStock {
+		if quantity != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateVariant changes the SKU and price of a variant in place. Stock changes
+// go through the inventory ledger.
+func (pu *productUsecaseImpl) UpdateVariant(ctx context.Context, actor *domain.Actor, id string, variantID string, variantReq *domain.ProductVariantRequest) (*domain.Product, error) {
+	product, err := pu.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current := product.Variant(variantID)
+	if current == nil {
+		return nil, fmt.Errorf("%w: product %s has no variant %s", domain.ErrNotFound, id, variantID)
+	}
+
+	variant := *current
+	if sku := strings.TrimSpace(variantReq.SKU); sku != "" {
+		for _, other := range product.Variants {
+			if other.Id != variantID && strings.EqualFold(other.SKU, sku) {
+				return nil, fmt.Errorf("%w: SKU %s is already used by another variant", domain.ErrConflict, sku)
+			}
+		}
+		variant.SKU = sku
+	}
+	if variantReq.Price != nil {
+		if *variantReq.Price < 0 {
+			return nil, fmt.Errorf("%w: price cannot be negative", domain.ErrInvalidInput)
+		}
+		variant.Price = variantReq.Price
+	}
+	if variantReq.Stock != nil && *variantReq.Stock < 0 {
+		return nil, fmt.Errorf("%w: stock cannot be negative", domain.ErrInvalidInput)
+	}
+	updated, err := pu.productRepo.UpdateVariant(ctx, id, &variant)
+	if err != nil {
+		return nil, err
+	}
+	if variantReq.Stock != nil {
+		return pu.inventory.SetStock(ctx, actor, id, variantID, *variantReq.Stock, "set by variant update")
+	}
+	return updated, nil
+}
+
+// normalizeOptions trims option names and values and rejects empty or
+// duplicate entries.
+func normalizeOptions(productOptions []*domain.ProductOption) ([]*domain.ProductOption, error) {
+	normalized := make([]*domain.ProductOption, 0, len(productOptions))
+	names := make(map[string]bool, len(productOptions))
+	count := 1
+	for _, option := range productOptions {
+		name := strings.TrimSpace(option.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: option name is required", domain.ErrInvalidInput)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: duplicate option %s", domain.ErrInvalidInput, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		values := make([]string, 0, len(option.Values))
+		seen := make(map[string]bool, len(option.Values))
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == "" || seen[strings.ToLower(value)] {
+				return nil, fmt.Errorf("%w: option %s has an empty or duplicate value", domain.ErrInvalidInput, name)
+			}
+			seen[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: option %s needs at least one value", domain.ErrInvalidInput, name)
+		}
+
+		count *= len(values)
+		if count > maxVariants {
+			return nil, fmt.Errorf("%w: options would generate more than %d variants", domain.ErrInvalidInput, maxVariants)
+		}
+		normalized = append(normalized, &domain.ProductOption{Name: name, Values: values})
+	}
+	return normalized, nil
+}
+
+// combinations returns every combination of option values, varying the last
+// option fastest.
+func combinations(productOptions []*domain.ProductOption) []map[string]string {
+	if len(productOptions) == 0 {
+		return nil
+	}
+	result := []map[string]string{{}}
+	for _, option := range productOptions {
+		next := make([]map[string]string, 0, len(result)*len(option.Values))
+		for _, partial := range result {
+			for _, value := range option.Values {
+				combination := make(map[string]string, len(partial)+1)
+				for k, v := range partial {
+					combination[k] = v
+				}
+				combination[option.Name] = value
+				next = append(next, combination)
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// variantKey identifies a combination of option values case-insensitively,
+// so renaming "red" to "Red" keeps the existing variant.
+func variantKey(productOptions []*domain.ProductOption, values map[string]string) string {
+	parts := make([]string, 0, len(productOptions))
+	for _, option := range productOptions {
+		value := ""
+		for name, v := range values {
+			if strings.EqualFold(name, option.Name) {
+				value = v
+				break
+			}
+		}
+		parts = append(parts, strings.ToLower(value))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func generateSKU(productName string, productOptions []*domain.ProductOption, combination map[string]string) string {
+	parts := []string{slugify(productName)}
+	for _, option := range productOptions {
+		parts = append(parts, slugify(combination[option.Name]))
+	}
+	return strings.ToUpper(strings.Join(parts, "-"))
+}
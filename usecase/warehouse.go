@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"intern-project-v2/domain"
+	"strings"
+	"time"
+)
+
+var _ domain.WarehouseUsecase = (*warehouseUsecaseImpl)(nil)
+
+type warehouseUsecaseImpl struct {
+	warehouseRepo domain.WarehouseRepository
+}
+
+func NewWarehouseUsecase(warehouseRepo domain.WarehouseRepository) domain.WarehouseUsecase {
+	return &warehouseUsecaseImpl{
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (wu *warehouseUsecaseImpl) GetAll(ctx context.Context) ([]*domain.Warehouse, error) {
+	return wu.warehouseRepo.GetAll(ctx)
+}
+
+func (wu *warehouseUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.Warehouse, error) {
+	return wu.warehouseRepo.GetByID(ctx, id)
+}
+
+// Create adds a warehouse. New warehouses are active unless the request says
+// otherwise.
+func (wu *warehouseUsecaseImpl) Create(ctx context.Context, warehouseReq *domain.WarehouseRequest) (*domain.Warehouse, error) {
+	warehouse := &domain.Warehouse{Active: true, CreatedAt: time.Now()}
+	if err := applyWarehouse(warehouse, warehouseReq); err != nil {
+		return nil, err
+	}
+	return wu.warehouseRepo.Create(ctx, warehouse)
+}
+
+// Update replaces the code, name and location of a warehouse. Active is only
+// changed when given. Deactivating a warehouse keeps its stock where it is.
+func (wu *warehouseUsecaseImpl) Update(ctx context.Context, id string, warehouseReq *domain.WarehouseRequest) (*domain.Warehouse, error) {
+	warehouse, err := wu.warehouseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWarehouse(warehouse, warehouseReq); err != nil {
+		return nil, err
+	}
+	return wu.warehouseRepo.Update(ctx, warehouse)
+}
+
+func applyWarehouse(warehouse *domain.Warehouse, warehouseReq *domain.WarehouseRequest) error {
+	code := strings.ToUpper(strings.TrimSpace(warehouseReq.Code))
+	name := strings.TrimSpace(warehouseReq.Name)
+	if code == "" || name == "" {
+		return fmt.Errorf("%w: code and name are required", domain.ErrInvalidInput)
+	}
+	if warehouseReq.Location != nil && !validGeoPoint(warehouseReq.Location) {
+		return fmt.Errorf("%w: location is out of range", domain.ErrInvalidInput)
+	}
+	warehouse.Code = code
+	warehouse.Name = name
+	warehouse.Location = warehouseReq.Location
+	if warehouseReq.Active != nil {
+		warehouse.Active = *warehouseReq.Active
+	}
+	return nil
+}
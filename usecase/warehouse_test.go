@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockWarehouseRepository struct {
+	mock.Mock
+}
+
+func (m *MockWarehouseRepository) GetAll(ctx context.Context) ([]*domain.Warehouse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) GetByID(ctx context.Context, id string) (*domain.Warehouse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) Create(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	args := m.Called(ctx, warehouse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) Update(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	args := m.Called(ctx, warehouse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Warehouse), args.Error(1)
+}
+
+func TestWarehouseUsecase_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *domain.WarehouseRequest
+		mockSetup     func(*MockWarehouseRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Code is normalised and the warehouse starts active",
+			request: &domain.WarehouseRequest{Code: " bkk ", Name: "Bangkok", Location: &domain.GeoPoint{Latitude: 13.75, Longitude: 100.5}},
+			mockSetup: func(wr *MockWarehouseRepository) {
+				wr.On("Create", mock.Anything, mock.MatchedBy(func(warehouse *domain.Warehouse) bool {
+					return warehouse.Code == "BKK" && warehouse.Active
+				})).Return(&domain.Warehouse{Id: bson.NewObjectID(), Code: "BKK"}, nil)
+			},
+		},
+		{
+			name:          "Error - Location out of range",
+			request:       &domain.WarehouseRequest{Code: "BKK", Name: "Bangkok", Location: &domain.GeoPoint{Latitude: 113.75, Longitude: 100.5}},
+			mockSetup:     func(wr *MockWarehouseRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Missing name",
+			request:       &domain.WarehouseRequest{Code: "BKK"},
+			mockSetup:     func(wr *MockWarehouseRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:    "Error - Code already in use",
+			request: &domain.WarehouseRequest{Code: "BKK", Name: "Bangkok"},
+			mockSetup: func(wr *MockWarehouseRepository) {
+				wr.On("Create", mock.Anything, mock.Anything).Return(nil, domain.ErrConflict)
+			},
+			expectedError: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			warehouseRepo := new(MockWarehouseRepository)
+			tt.mockSetup(warehouseRepo)
+			usecase := NewWarehouseUsecase(warehouseRepo)
+
+			// Act
+			result, err := usecase.Create(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+			}
+			warehouseRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	bangkok := &domain.Warehouse{Id: bson.NewObjectID(), Active: true, Location: &domain.GeoPoint{Latitude: 13.75, Longitude: 100.5}}
+	chiangMai := &domain.Warehouse{Id: bson.NewObjectID(), Active: true, Location: &domain.GeoPoint{Latitude: 18.8, Longitude: 98.98}}
+	closed := &domain.Warehouse{Id: bson.NewObjectID(), Active: false}
+	warehouses := []*domain.Warehouse{bangkok, chiangMai, closed}
+	product := &domain.Product{Id: bson.NewObjectID(), Stock: 20, WarehouseStock: map[string]int{
+		bangkok.Id.Hex():   4,
+		chiangMai.Id.Hex(): 9,
+		closed.Id.Hex():    5,
+	}}
+	nearBangkok := &domain.GeoPoint{Latitude: 13.7, Longitude: 100.6}
+
+	tests := []struct {
+		name          string
+		strategy      string
+		shipTo        *domain.GeoPoint
+		quantity      int
+		expected      map[string]int
+		expectedError error
+	}{
+		{
+			name:     "Nearest warehouse that can fill the line",
+			strategy: domain.AllocationNearest,
+			shipTo:   nearBangkok,
+			quantity: 4,
+			expected: map[string]int{bangkok.Id.Hex(): 4},
+		},
+		{
+			name:     "Nearest skips a warehouse that is too small",
+			strategy: domain.AllocationNearest,
+			shipTo:   nearBangkok,
+			quantity: 6,
+			expected: map[string]int{chiangMai.Id.Hex(): 6},
+		},
+		{
+			name:     "Most stock ignores distance",
+			strategy: domain.AllocationMostStock,
+			shipTo:   nearBangkok,
+			quantity: 2,
+			expected: map[string]int{chiangMai.Id.Hex(): 2},
+		},
+		{
+			name:     "Split uses unassigned stock last",
+			strategy: domain.AllocationSplit,
+			shipTo:   nearBangkok,
+			quantity: 15,
+			expected: map[string]int{bangkok.Id.Hex(): 4, chiangMai.Id.Hex(): 9, "": 2},
+		},
+		{
+			name:          "No single warehouse holds enough",
+			strategy:      domain.AllocationMostStock,
+			quantity:      12,
+			expectedError: domain.ErrConflict,
+		},
+		{
+			name:          "Inactive warehouses are not shipped from",
+			strategy:      domain.AllocationSplit,
+			quantity:      16,
+			expectedError: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			allocations, err := allocate(tt.strategy, tt.shipTo, warehouses, product, "", tt.quantity)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			got := make(map[string]int)
+			for _, allocation := range allocations {
+				got[allocation.WarehouseID] += allocation.Quantity
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
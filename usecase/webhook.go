@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"net/url"
+	"slices"
+	"time"
+)
+
+var _ domain.WebhookUsecase = (*webhookUsecaseImpl)(nil)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+	webhookBatchSize     = 50
+	webhookRetryBase     = 30 * time.Second
+	webhookRetryMax      = 6 * time.Hour
+)
+
+type webhookUsecaseImpl struct {
+	webhookRepo domain.WebhookRepository
+	sender      domain.WebhookSender
+}
+
+func NewWebhookUsecase(webhookRepo domain.WebhookRepository, sender domain.WebhookSender) domain.WebhookUsecase {
+	return &webhookUsecaseImpl{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+	}
+}
+
+// GetAll lists the subscriptions without their secrets.
+func (wu *webhookUsecaseImpl) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subscriptions, err := wu.webhookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (wu *webhookUsecaseImpl) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	subscription, err := wu.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// Create adds a subscription. Without a secret one is generated; either way
+// the secret is returned this once so the partner can verify signatures.
+func (wu *webhookUsecaseImpl) Create(ctx context.Context, subscriptionReq *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	subscription := &domain.WebhookSubscription{Active: true, CreatedAt: time.Now()}
+	if err := applySubscription(subscription, subscriptionReq); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+	return wu.webhookRepo.Create(ctx, subscription)
+}
+
+// Update replaces the URL and event types of a subscription. The secret is
+// only replaced, and shown, when the request has one.
+func (wu *webhookUsecaseImpl) Update(ctx context.Context, id string, subscriptionReq *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	subscription, err := wu.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySubscription(subscription, subscriptionReq); err != nil {
+		return nil, err
+	}
+	updated, err := wu.webhookRepo.Update(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptionReq.Secret == "" {
+		updated.Secret = ""
+	}
+	return updated, nil
+}
+
+func (wu *webhookUsecaseImpl) Delete(ctx context.Context, id string) error {
+	return wu.webhookRepo.Delete(ctx, id)
+}
+
+func (wu *webhookUsecaseImpl) GetDeliveries(ctx context.Context, query *domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryResult, error) {
+	if query.Status != "" && !slices.Contains(domain.WebhookDeliveryStatuses, query.Status) {
+		return nil, fmt.Errorf("%w: unknown delivery status %q", domain.ErrInvalidInput, query.Status)
+	}
+	if _, err := wu.webhookRepo.GetByID(ctx, query.SubscriptionID); err != nil {
+		return nil, err
+	}
+	query.Page = clampPage(query.Page)
+	query.Limit = clampLimit(query.Limit, defaultDeliveryLimit, maxDeliveryLimit)
+
+	deliveries, total, err := wu.webhookRepo.GetDeliveries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WebhookDeliveryResult{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+	}, nil
+}
+
+// SendTest sends a webhook.test event to a subscription right away, whether
+// or not it is active, and returns the logged delivery. A failed test is
+// not retried.
+func (wu *webhookUsecaseImpl) SendTest(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	subscription, err := wu.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	event, err := domain.NewEvent(domain.EventWebhookTest, id, map[string]string{"subscription_id": id})
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := wu.webhookRepo.AddDelivery(ctx, newDelivery(subscription, event))
+	if err != nil {
+		return nil, err
+	}
+	wu.attempt(ctx, subscription, delivery, false)
+	if err := wu.webhookRepo.SaveDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Retry puts a dead delivery back in the queue with fresh attempts.
+func (wu *webhookUsecaseImpl) Retry(ctx context.Context, subscriptionID string, deliveryID string) (*domain.WebhookDelivery, error) {
+	delivery, err := wu.webhookRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return nil, domain.ErrNotFound
+	}
+	if delivery.Status != domain.WebhookDeliveryDead {
+		return nil, fmt.Errorf("%w: only dead deliveries can be retried", domain.ErrConflict)
+	}
+	now := time.Now()
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	if err := wu.webhookRepo.SaveDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue queues a delivery of an event for every active subscription to its
+// type. It is the sink the outbox relay hands events to; an event relayed
+// again is not queued twice.
+func (wu *webhookUsecaseImpl) Enqueue(ctx context.Context, event *domain.Event) error {
+	subscriptions, err := wu.webhookRepo.GetSubscribed(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		if _, err := wu.webhookRepo.AddDelivery(ctx, newDelivery(subscription, event)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverPending makes one attempt at every due delivery and returns how
+// many went through.
+func (wu *webhookUsecaseImpl) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := wu.webhookRepo.DueDeliveries(ctx, time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	subscriptions := make(map[string]*domain.WebhookSubscription)
+	delivered := 0
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = wu.webhookRepo.GetByID(ctx, delivery.SubscriptionID)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return delivered, err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+		if subscription == nil || !subscription.Active {
+			delivery.Status = domain.WebhookDeliveryDead
+			delivery.NextAttemptAt = nil
+			delivery.LastError = "subscription was deleted or deactivated"
+		} else {
+			wu.attempt(ctx, subscription, delivery, true)
+		}
+		if err := wu.webhookRepo.SaveDelivery(ctx, delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == domain.WebhookDeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends a delivery once and records the outcome on it. Failures are
+// scheduled for a retry when retry is set, until the attempts run out and
+// the delivery is dead.
+func (wu *webhookUsecaseImpl) attempt(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery, retry bool) {
+	delivery.Attempts++
+	statusCode, err := wu.sender.Send(ctx, subscription, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil && (statusCode < 200 || statusCode >= 300) {
+		err = fmt.Errorf("receiver answered with status %d", statusCode)
+	}
+	if err == nil {
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if !retry || delivery.Attempts >= domain.MaxWebhookAttempts {
+		delivery.Status = domain.WebhookDeliveryDead
+		delivery.NextAttemptAt = nil
+		logger.Warn("Webhook delivery is dead", "subscription_id", subscription.Id.Hex(), "event_id", delivery.EventID, "attempts", delivery.Attempts, "error", err)
+		return
+	}
+	next := time.Now().Add(webhookRetryDelay(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+	logger.Warn("Webhook delivery failed, will retry", "subscription_id", subscription.Id.Hex(), "event_id", delivery.EventID, "attempts", delivery.Attempts, "next_attempt_at", next, "error", err)
+}
+
+// webhookRetryDelay doubles from 30 seconds with every failed attempt, up to
+// six hours.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMax)
+}
+
+func newDelivery(subscription *domain.WebhookSubscription, event *domain.Event) *domain.WebhookDelivery {
+	now := time.Now()
+	return &domain.WebhookDelivery{
+		SubscriptionID: subscription.Id.Hex(),
+		EventID:        event.Id.Hex(),
+		EventType:      event.Type,
+		Payload:        event.Payload,
+		OccurredAt:     event.OccurredAt,
+		Status:         domain.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+	}
+}
+
+func applySubscription(subscription *domain.WebhookSubscription, subscriptionReq *domain.WebhookSubscriptionRequest) error {
+	target, err := url.Parse(subscriptionReq.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidInput)
+	}
+	if len(subscriptionReq.EventTypes) == 0 {
+		return fmt.Errorf("%w: subscribe to at least one event type", domain.ErrInvalidInput)
+	}
+	for _, eventType := range subscriptionReq.EventTypes {
+		if !slices.Contains(domain.EventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidInput, eventType)
+		}
+	}
+	subscription.URL = target.String()
+	subscription.EventTypes = slices.Compact(slices.Sorted(slices.Values(subscriptionReq.EventTypes)))
+	if subscriptionReq.Secret != "" {
+		subscription.Secret = subscriptionReq.Secret
+	}
+	if subscriptionReq.Active != nil {
+		subscription.Active = *subscriptionReq.Active
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetSubscribed(ctx context.Context, eventType string) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, delivery)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(ctx context.Context, query *domain.WebhookDeliveryQuery) ([]*domain.WebhookDelivery, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteDeliveries(ctx context.Context, eventIDs []string) (int64, error) {
+	args := m.Called(ctx, eventIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestWebhookUsecase_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *domain.WebhookSubscriptionRequest
+		mockSetup     func(*MockWebhookRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success - Secret is generated and event types are deduplicated",
+			request: &domain.WebhookSubscriptionRequest{URL: "https://partner.example.com/hooks", EventTypes: []string{domain.EventOrderPlaced, domain.EventCartAbandoned, domain.EventOrderPlaced}},
+			mockSetup: func(wr *MockWebhookRepository) {
+				wr.On("Create", mock.Anything, mock.MatchedBy(func(subscription *domain.WebhookSubscription) bool {
+					return len(subscription.Secret) > len("whsec_") && subscription.Active && len(subscription.EventTypes) == 2
+				})).Return(&domain.WebhookSubscription{Id: bson.NewObjectID(), Secret: "whsec_generated"}, nil)
+			},
+		},
+		{
+			name:          "Error - URL is not http",
+			request:       &domain.WebhookSubscriptionRequest{URL: "ftp://partner.example.com", EventTypes: []string{domain.EventOrderPlaced}},
+			mockSetup:     func(wr *MockWebhookRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - No event types",
+			request:       &domain.WebhookSubscriptionRequest{URL: "https://partner.example.com/hooks"},
+			mockSetup:     func(wr *MockWebhookRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Unknown event type",
+			request:       &domain.WebhookSubscriptionRequest{URL: "https://partner.example.com/hooks", EventTypes: []string{"order.shipped"}},
+			mockSetup:     func(wr *MockWebhookRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			webhookRepo := new(MockWebhookRepository)
+			tt.mockSetup(webhookRepo)
+			usecase := NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender())
+
+			// Act
+			result, err := usecase.Create(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.Secret)
+			}
+			webhookRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookUsecase_SendTest(t *testing.T) {
+	// Arrange
+	subscription := &domain.WebhookSubscription{Id: bson.NewObjectID(), Secret: "whsec_test", EventTypes: []string{domain.EventOrderPlaced}}
+	var verified bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		verified = r.Header.Get(webhook.HeaderEvent) == domain.EventWebhookTest &&
+			webhook.Verify(subscription.Secret, r.Header.Get(webhook.HeaderSignature), timestamp, body, 5*time.Minute)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	subscription.URL = receiver.URL
+
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("GetByID", mock.Anything, subscription.Id.Hex()).Return(subscription, nil)
+	queued := &domain.WebhookDelivery{
+		Id:             bson.NewObjectID(),
+		SubscriptionID: subscription.Id.Hex(),
+		EventID:        bson.NewObjectID().Hex(),
+		EventType:      domain.EventWebhookTest,
+		Payload:        []byte(`{"subscription_id":"` + subscription.Id.Hex() + `"}`),
+		Status:         domain.WebhookDeliveryPending,
+	}
+	webhookRepo.On("AddDelivery", mock.Anything, mock.MatchedBy(func(delivery *domain.WebhookDelivery) bool {
+		return delivery.EventType == domain.EventWebhookTest
+	})).Return(queued, nil)
+	webhookRepo.On("SaveDelivery", mock.Anything, mock.Anything).Return(nil)
+	usecase := NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender())
+
+	// Act
+	delivery, err := usecase.SendTest(context.Background(), subscription.Id.Hex())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, verified, "receiver should get a correctly signed test event")
+	assert.Equal(t, domain.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+	assert.Equal(t, 1, delivery.Attempts)
+	webhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookUsecase_DeliverPending(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name            string
+		attempts        int
+		active          bool
+		expectedStatus  string
+		expectRetryTime bool
+	}{
+		{
+			name:            "Failure - Retry is scheduled with backoff",
+			attempts:        2,
+			active:          true,
+			expectedStatus:  domain.WebhookDeliveryPending,
+			expectRetryTime: true,
+		},
+		{
+			name:           "Failure - Last attempt makes the delivery dead",
+			attempts:       domain.MaxWebhookAttempts - 1,
+			active:         true,
+			expectedStatus: domain.WebhookDeliveryDead,
+		},
+		{
+			name:           "Inactive subscription - Delivery is dead without sending",
+			attempts:       0,
+			active:         false,
+			expectedStatus: domain.WebhookDeliveryDead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			subscription := &domain.WebhookSubscription{Id: bson.NewObjectID(), URL: receiver.URL, Secret: "whsec_test", Active: tt.active}
+			now := time.Now()
+			delivery := &domain.WebhookDelivery{
+				Id:             bson.NewObjectID(),
+				SubscriptionID: subscription.Id.Hex(),
+				EventID:        bson.NewObjectID().Hex(),
+				EventType:      domain.EventOrderPlaced,
+				Payload:        []byte(`{"order_id":"1"}`),
+				Status:         domain.WebhookDeliveryPending,
+				Attempts:       tt.attempts,
+				NextAttemptAt:  &now,
+			}
+			webhookRepo := new(MockWebhookRepository)
+			webhookRepo.On("DueDeliveries", mock.Anything, mock.Anything, webhookBatchSize).Return([]*domain.WebhookDelivery{delivery}, nil)
+			webhookRepo.On("GetByID", mock.Anything, subscription.Id.Hex()).Return(subscription, nil)
+			webhookRepo.On("SaveDelivery", mock.Anything, delivery).Return(nil)
+			usecase := NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender())
+
+			// Act
+			delivered, err := usecase.DeliverPending(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 0, delivered)
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			if tt.expectRetryTime {
+				assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+				assert.WithinDuration(t, time.Now().Add(webhookRetryDelay(tt.attempts+1)), *delivery.NextAttemptAt, time.Minute)
+			} else {
+				assert.Nil(t, delivery.NextAttemptAt)
+			}
+			webhookRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, webhookRetryDelay(3))
+	assert.Equal(t, 6*time.Hour, webhookRetryDelay(20))
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"slices"
+	"time"
+)
+
+var _ domain.WishlistUsecase = (*wishlistUsecaseImpl)(nil)
+
+type wishlistUsecaseImpl struct {
+	wishlistRepo domain.WishlistRepository
+	productRepo  domain.ProductRepository
+	customerRepo domain.CustomerRepository
+	cartUsecase  domain.CartUsecase
+	notifier     domain.BackInStockNotifier
+}
+
+func NewWishlistUsecase(
+	wishlistRepo domain.WishlistRepository,
+	productRepo domain.ProductRepository,
+	customerRepo domain.CustomerRepository,
+	cartUsecase domain.CartUsecase,
+	notifier domain.BackInStockNotifier,
+) domain.WishlistUsecase {
+	return &wishlistUsecaseImpl{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		customerRepo: customerRepo,
+		cartUsecase:  cartUsecase,
+		notifier:     notifier,
+	}
+}
+
+func (wu *wishlistUsecaseImpl) Get(ctx context.Context, actor *domain.Actor, customerID string) (*domain.Wishlist, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	wishlist, err := wu.wishlistRepo.GetByCustomerID(ctx, customerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		wishlist, err = &domain.Wishlist{CustomerID: customerID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wu.describe(ctx, wishlist)
+}
+
+func (wu *wishlistUsecaseImpl) AddItem(ctx context.Context, actor *domain.Actor, customerID string, itemReq *domain.WishlistItemRequest) (*domain.Wishlist, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	if err := ensureCustomerExists(ctx, wu.customerRepo, customerID); err != nil {
+		return nil, err
+	}
+	product, err := wu.productRepo.GetByID(ctx, itemReq.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A product with variants may be saved without picking one yet.
+	item := &domain.WishlistItem{
+		ProductID:         itemReq.ProductID,
+		NotifyBackInStock: itemReq.NotifyBackInStock,
+		AddedAt:           time.Now(),
+	}
+	if itemReq.VariantID != "" {
+		variant, err := resolveVariant(product, itemReq.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		item.VariantID = variant.Id
+		item.SKU = variant.SKU
+	}
+
+	wishlist, err := wu.wishlistRepo.AddItem(ctx, customerID, item)
+	if err != nil {
+		return nil, err
+	}
+	return wu.describe(ctx, wishlist)
+}
+
+func (wu *wishlistUsecaseImpl) RemoveItem(ctx context.Context, actor *domain.Actor, customerID string, productID string, variantID string) (*domain.Wishlist, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	wishlist, err := wu.wishlistRepo.RemoveItem(ctx, customerID, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return wu.describe(ctx, wishlist)
+}
+
+// MoveToCart adds a wishlisted product to the cart and takes it off the
+// wishlist. A line saved without a variant can be moved by naming the
+// variant to buy. The cart is written first, so a failure never loses the
+// item.
+func (wu *wishlistUsecaseImpl) MoveToCart(ctx context.Context, actor *domain.Actor, customerID string, moveReq *domain.WishlistMoveRequest) (*domain.WishlistTransfer, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	wishlist, err := wu.wishlistRepo.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	item := findWishlistItem(wishlist, moveReq.ProductID, moveReq.VariantID)
+	if item == nil {
+		return nil, fmt.Errorf("%w: product %s is not in the wishlist", domain.ErrNotFound, moveReq.ProductID)
+	}
+	quantity := moveReq.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	cart, err := wu.cartUsecase.BatchUpdate(ctx, actor, customerID, &domain.CartBatchRequest{
+		Operations: []*domain.CartOperation{{
+			Op:        domain.CartOpAdd,
+			ProductID: moveReq.ProductID,
+			VariantID: moveReq.VariantID,
+			Quantity:  quantity,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	wishlist, err = wu.wishlistRepo.RemoveItem(ctx, customerID, item.ProductID, item.VariantID)
+	if err != nil {
+		logger.Error("Moved item to cart but could not remove it from the wishlist", "customer_id", customerID, "product_id", item.ProductID, "error", err)
+		return nil, err
+	}
+	if wishlist, err = wu.describe(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return &domain.WishlistTransfer{Cart: cart, Wishlist: wishlist}, nil
+}
+
+// SaveForLater moves a cart line to the wishlist. The wishlist is written
+// first, so a failure never loses the item.
+func (wu *wishlistUsecaseImpl) SaveForLater(ctx context.Context, actor *domain.Actor, customerID string, moveReq *domain.WishlistMoveRequest) (*domain.WishlistTransfer, error) {
+	if !actor.IsAdmin() && actor.CustomerID != customerID {
+		return nil, domain.ErrForbidden
+	}
+	cart, err := wu.cartUsecase.GetCartByCustomerId(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	var line *domain.CartItem
+	for _, item := range cart.Items {
+		if item.ProductID == moveReq.ProductID && item.VariantID == moveReq.VariantID {
+			line = item
+			break
+		}
+	}
+	if line == nil {
+		return nil, fmt.Errorf("%w: product %s is not in the cart", domain.ErrNotFound, moveReq.ProductID)
+	}
+
+	wishlist, err := wu.wishlistRepo.AddItem(ctx, customerID, &domain.WishlistItem{
+		ProductID: line.ProductID,
+		VariantID: line.VariantID,
+		SKU:       line.SKU,
+		AddedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	cart, err = wu.cartUsecase.BatchUpdate(ctx, actor, customerID, &domain.CartBatchRequest{
+		Operations: []*domain.CartOperation{{Op: domain.CartOpUpdate, ProductID: line.ProductID, VariantID: line.VariantID}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if wishlist, err = wu.describe(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return &domain.WishlistTransfer{Cart: cart, Wishlist: wishlist}, nil
+}
+
+// Restocked notifies every customer watching a line that came back in stock.
+// Notification failures are logged and never fail the stock change.
+func (wu *wishlistUsecaseImpl) Restocked(ctx context.Context, restock *domain.Restock) {
+	productID := restock.Product.Id.Hex()
+	wishlists, err := wu.wishlistRepo.GetWatching(ctx, productID)
+	if err != nil {
+		logger.Error("Failed to find wishlists for restocked product", "product_id", productID, "error", err)
+		return
+	}
+	for _, wishlist := range wishlists {
+		for _, item := range wishlist.Items {
+			if item.ProductID != productID || !item.NotifyBackInStock {
+				continue
+			}
+			if item.VariantID == "" && !restock.ProductRestocked {
+				continue
+			}
+			if item.VariantID != "" && !slices.Contains(restock.VariantIDs, item.VariantID) {
+				continue
+			}
+			if err := wu.notifier.NotifyBackInStock(ctx, wishlist.CustomerID, restock.Product, item); err != nil {
+				logger.Error("Failed to send back-in-stock notification", "customer_id", wishlist.CustomerID, "product_id", productID, "error", err)
+			}
+		}
+	}
+}
+
+// describe fills in the catalog details of every wishlist line. Products that
+// were deleted since stay on the list, marked unavailable.
+func (wu *wishlistUsecaseImpl) describe(ctx context.Context, wishlist *domain.Wishlist) (*domain.Wishlist, error) {
+	if wishlist.Items == nil {
+		wishlist.Items = []*domain.WishlistItem{}
+	}
+	productIDs := make([]string, 0, len(wishlist.Items))
+	for _, item := range wishlist.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if len(productIDs) == 0 {
+		return wishlist, nil
+	}
+	products, err := wu.productRepo.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Product, len(products))
+	for _, product := range products {
+		byID[product.Id.Hex()] = product
+	}
+
+	for _, item := range wishlist.Items {
+		product := byID[item.ProductID]
+		if product == nil {
+			continue
+		}
+		variant := product.Variant(item.VariantID)
+		if item.VariantID != "" && variant == nil {
+			continue
+		}
+		item.ProductName = product.Name
+		item.Price = product.PriceOf(variant)
+		item.Available = true
+		item.InStock = product.Stock > 0
+		if variant != nil {
+			item.InStock = variant.Stock > 0
+		}
+	}
+	return wishlist, nil
+}
+
+// findWishlistItem prefers the exact line and falls back to the line saved
+// without a variant.
+func findWishlistItem(wishlist *domain.Wishlist, productID, variantID string) *domain.WishlistItem {
+	var generic *domain.WishlistItem
+	for _, item := range wishlist.Items {
+		if item.ProductID != productID {
+			continue
+		}
+		if item.VariantID == variantID {
+			return item
+		}
+		if item.VariantID == "" {
+			generic = item
+		}
+	}
+	return generic
+}
+
+// restockedBy reports whether adding quantity units of a product, or of one
+// of its variants, brought it back from zero. product is the state after the
+// change.
+func restockedBy(product *domain.Product, variantID string, quantity int) *domain.Restock {
+	if product == nil {
+		return nil
+	}
+	restock := &domain.Restock{
+		Product:          product,
+		ProductRestocked: product.Stock > 0 && product.Stock <= quantity,
+	}
+	if variant := product.Variant(variantID); variant != nil && variant.Stock > 0 && variant.Stock <= quantity {
+		restock.VariantIDs = []string{variant.Id}
+	}
+	if !restock.ProductRestocked && len(restock.VariantIDs) == 0 {
+		return nil
+	}
+	return restock
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) GetByCustomerID(ctx context.Context, customerID string) (*domain.Wishlist, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) AddItem(ctx context.Context, customerID string, item *domain.WishlistItem) (*domain.Wishlist, error) {
+	args := m.Called(ctx, customerID, item)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) RemoveItem(ctx context.Context, customerID string, productID string, variantID string) (*domain.Wishlist, error) {
+	args := m.Called(ctx, customerID, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetWatching(ctx context.Context, productID string) ([]*domain.Wishlist, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) Delete(ctx context.Context, customerID string) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+type MockBackInStockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockBackInStockNotifier) NotifyBackInStock(ctx context.Context, customerID string, product *domain.Product, item *domain.WishlistItem) error {
+	args := m.Called(ctx, customerID, product, item)
+	return args.Error(0)
+}
+
+func TestWishlistUsecase_Restocked(t *testing.T) {
+	after := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 5, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 3},
+		{Id: "v-l", SKU: "SHIRT-L", Stock: 2},
+	}}
+	productID := after.Id.Hex()
+	wishlists := []*domain.Wishlist{
+		{CustomerID: "medium", Items: []*domain.WishlistItem{{ProductID: productID, VariantID: "v-m", NotifyBackInStock: true}}},
+		{CustomerID: "large", Items: []*domain.WishlistItem{{ProductID: productID, VariantID: "v-l", NotifyBackInStock: true}}},
+		{CustomerID: "any", Items: []*domain.WishlistItem{{ProductID: productID, NotifyBackInStock: true}}},
+		{CustomerID: "silent", Items: []*domain.WishlistItem{{ProductID: productID, VariantID: "v-m"}}},
+	}
+
+	// Arrange
+	wishlistRepo := new(MockWishlistRepository)
+	notifier := new(MockBackInStockNotifier)
+	wishlistRepo.On("GetWatching", mock.Anything, productID).Return(wishlists, nil)
+	notifier.On("NotifyBackInStock", mock.Anything, mock.Anything, after, mock.Anything).Return(nil)
+	usecase := NewWishlistUsecase(wishlistRepo, nil, nil, nil, notifier)
+
+	// Act
+	restock := restockedBy(after, "v-m", 3)
+	usecase.Restocked(context.Background(), restock)
+
+	// Assert
+	assert.Equal(t, []string{"v-m"}, restock.VariantIDs)
+	assert.False(t, restock.ProductRestocked)
+	notifier.AssertNumberOfCalls(t, "NotifyBackInStock", 1)
+	notifier.AssertCalled(t, "NotifyBackInStock", mock.Anything, "medium", after, mock.Anything)
+	assert.Nil(t, restockedBy(after, "v-l", 1))
+}
+
+func TestWishlistUsecase_MoveToCart(t *testing.T) {
+	customerID := bson.NewObjectID().Hex()
+	owner := &domain.Actor{CustomerID: customerID, Role: domain.RoleCustomer}
+	shirt := &domain.Product{Id: bson.NewObjectID(), Name: "Shirt", Price: 20, Stock: 3, Variants: []*domain.ProductVariant{
+		{Id: "v-m", SKU: "SHIRT-M", Stock: 3},
+	}}
+	shirtID := shirt.Id.Hex()
+	wishlist := &domain.Wishlist{CustomerID: customerID, Items: []*domain.WishlistItem{{ProductID: shirtID}}}
+
+	tests := []struct {
+		name          string
+		actor         *domain.Actor
+		request       *domain.WishlistMoveRequest
+		expectedError error
+	}{
+		{
+			name:    "Success - Line saved without a variant moves as the chosen variant",
+			actor:   owner,
+			request: &domain.WishlistMoveRequest{ProductID: shirtID, VariantID: "v-m", Quantity: 2},
+		},
+		{
+			name:          "Error - Variant must be chosen",
+			actor:         owner,
+			request:       &domain.WishlistMoveRequest{ProductID: shirtID},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:          "Error - Product is not in the wishlist",
+			actor:         owner,
+			request:       &domain.WishlistMoveRequest{ProductID: bson.NewObjectID().Hex()},
+			expectedError: domain.ErrNotFound,
+		},
+		{
+			name:          "Error - Another customer's wishlist",
+			actor:         &domain.Actor{CustomerID: "someone-else", Role: domain.RoleCustomer},
+			request:       &domain.WishlistMoveRequest{ProductID: shirtID, VariantID: "v-m"},
+			expectedError: domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			wishlistRepo := new(MockWishlistRepository)
+			cartRepo := new(MockCartRepository)
+			productRepo := new(MockProductRepository)
+			customerRepo := new(MockCustomerRepository)
+			wishlistRepo.On("GetByCustomerID", mock.Anything, customerID).Return(wishlist, nil).Maybe()
+			wishlistRepo.On("RemoveItem", mock.Anything, customerID, shirtID, "").Return(&domain.Wishlist{CustomerID: customerID}, nil).Maybe()
+			customerRepo.On("GetByID", mock.Anything, customerID).Return(&domain.Customer{}, nil).Maybe()
+			cartRepo.On("GetCartByCustomerId", mock.Anything, customerID).Return(nil, domain.ErrNotFound).Maybe()
+			cartRepo.On("Save", mock.Anything, mock.Anything).Return(func(ctx context.Context, cart *domain.Cart) *domain.Cart {
+				return cart
+			}, nil).Maybe()
+			productRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*domain.Product{shirt}, nil).Maybe()
+			cartUsecase := NewCartUsecase(cartRepo, productRepo, customerRepo, nil, nil)
+			usecase := NewWishlistUsecase(wishlistRepo, productRepo, customerRepo, cartUsecase, nil)
+
+			// Act
+			transfer, err := usecase.MoveToCart(context.Background(), tt.actor, customerID, tt.request)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, transfer)
+				wishlistRepo.AssertNotCalled(t, "RemoveItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, transfer.Cart.Items, 1)
+			assert.Equal(t, 2, transfer.Cart.Items[0].Quantity)
+			assert.Equal(t, "SHIRT-M", transfer.Cart.Items[0].SKU)
+			assert.Empty(t, transfer.Wishlist.Items)
+		})
+	}
+}
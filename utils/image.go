@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// Registers the GIF decoder with image.Decode.
+	_ "image/gif"
+)
+
+// Thumbnail scales img down so that neither side exceeds maxSide, keeping the
+// aspect ratio. Each destination pixel averages the source pixels it covers,
+// which avoids the aliasing of nearest-neighbour sampling. Images that already
+// fit are returned unchanged.
+func Thumbnail(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	dstWidth, dstHeight := maxSide, maxSide
+	if width > height {
+		dstHeight = max(1, height*maxSide/width)
+	} else {
+		dstWidth = max(1, width*maxSide/height)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, max((y+1)*height/dstHeight, y*height/dstHeight+1)
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, max((x+1)*width/dstWidth, x*width/dstWidth+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					offset := src.PixOffset(sx, sy)
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// EncodeImage writes img as PNG when the source format carries transparency
+// and as JPEG otherwise. It returns the content type written.
+func EncodeImage(w io.Writer, img image.Image, format string) (string, error) {
+	if format == "png" || format == "gif" {
+		return "image/png", png.Encode(w, img)
+	}
+	return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA key accepted for signing.
+const minRSABits = 2048
+
+// SigningKey is a private key tokens are signed with. RSA keys sign with
+// RS256 and Ed25519 keys with EdDSA. A key signs from ActiveFrom until a key
+// with a later ActiveFrom takes over, and verifies tokens until RetireAt;
+// a zero RetireAt never retires it.
+type SigningKey struct {
+	ID         string
+	Private    crypto.Signer
+	ActiveFrom time.Time
+	RetireAt   time.Time
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if _, ok := k.Private.(ed25519.PrivateKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func (k *SigningKey) retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// KeySet holds every key tokens are signed or verified with. To rotate,
+// add a key with ActiveFrom in the future: it is published in the JWKS right
+// away, so other services know it before it signs anything. Give the old key
+// a RetireAt at least a token lifetime after that, so the tokens it signed
+// stay valid until they expire.
+type KeySet struct {
+	keys []*SigningKey
+}
+
+// NewKeySet checks the keys. Each needs a unique ID and an RSA key of at
+// least 2048 bits or an Ed25519 key.
+func NewKeySet(keys ...*SigningKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no JWT signing key is configured")
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("JWT signing key without an id")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate JWT signing key id %q", key.ID)
+		}
+		seen[key.ID] = true
+		switch private := key.Private.(type) {
+		case ed25519.PrivateKey:
+		case *rsa.PrivateKey:
+			if private.N.BitLen() < minRSABits {
+				return nil, fmt.Errorf("JWT signing key %q: RSA keys need at least %d bits", key.ID, minRSABits)
+			}
+		default:
+			return nil, fmt.Errorf("JWT signing key %q: only RSA and Ed25519 keys are supported", key.ID)
+		}
+	}
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom) })
+	return &KeySet{keys: sorted}, nil
+}
+
+// Signer returns the key that signs tokens at now: the one activated last.
+func (ks *KeySet) Signer(now time.Time) (*SigningKey, error) {
+	var signer *SigningKey
+	for _, key := range ks.keys {
+		if key.ActiveFrom.After(now) {
+			break
+		}
+		if !key.retired(now) {
+			signer = key
+		}
+	}
+	if signer == nil {
+		return nil, errors.New("no JWT signing key is active")
+	}
+	return signer, nil
+}
+
+// Next returns the key that takes over signing after now, if one is
+// scheduled.
+func (ks *KeySet) Next(now time.Time) *SigningKey {
+	for _, key := range ks.keys {
+		if key.ActiveFrom.After(now) {
+			return key
+		}
+	}
+	return nil
+}
+
+// verifier returns the public key of an unretired key.
+func (ks *KeySet) verifier(id string, now time.Time) (*SigningKey, crypto.PublicKey, bool) {
+	for _, key := range ks.keys {
+		if key.ID == id && !key.retired(now) {
+			return key, key.Private.Public(), true
+		}
+	}
+	return nil, nil, false
+}
+
+// JWK is the public part of a signing key, as published in a JWKS.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517).
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every unretired key, including those that
+// do not sign yet.
+func (ks *KeySet) JWKS(now time.Time) *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if key.retired(now) {
+			continue
+		}
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.method().Alg()}
+		switch public := key.Private.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// ParsePrivateKeyPEM reads a PKCS#8 RSA or Ed25519 key, or a PKCS#1 RSA
+// key, as written by openssl genpkey and openssl genrsa.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
@@ -1,52 +1,141 @@
 package utils
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// keySet holds the keys tokens are signed and verified with. It is set once
+// at startup by SetKeySet.
+var keySet atomic.Pointer[KeySet]
 
+// SetKeySet sets the keys tokens are signed and verified with.
+func SetKeySet(keys *KeySet) {
+	keySet.Store(keys)
+}
+
+// Claims of a login token. MFA is set when the login was completed with a
+// second factor. Purpose is only set on the short-lived tokens of a step in
+// between, such as an MFA challenge, which are not accepted as logins.
 type Claims struct {
-	Email string `json:"email"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	MFA     bool   `json:"mfa,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(email string) (string, error) {
+var (
+	errWrongPurpose = errors.New("token is not valid for this purpose")
+	errNoKeySet     = errors.New("no JWT signing key is configured")
+)
+
+func GenerateJWT(customerID, email, role string, mfa bool) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
 		Email: email,
+		Role:  role,
+		MFA:   mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   customerID,
 			Issuer:    "Order Management",
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
+	return sign(claims)
 }
 
 func ParseJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := parse(tokenString, claims)
 	if err != nil {
 		return nil, err
 	}
 	if !token.Valid {
 		return nil, jwt.ErrSignatureInvalid
 	}
+	if claims.Purpose != "" {
+		return nil, errWrongPurpose
+	}
 	return claims, nil
 }
 
+// GeneratePurposeJWT issues a token for one step of a flow, valid for ttl.
+func GeneratePurposeJWT(customerID, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   customerID,
+			Issuer:    "Order Management",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return sign(claims)
+}
+
+// ParsePurposeJWT checks a token issued by GeneratePurposeJWT for the purpose
+// and returns the customer id it was issued to.
+func ParsePurposeJWT(tokenString, purpose string) (string, error) {
+	claims := &Claims{}
+	token, err := parse(tokenString, claims, jwt.WithExpirationRequired())
+	if err != nil {
+		return "", err
+	}
+	if !token.Valid {
+		return "", jwt.ErrSignatureInvalid
+	}
+	if claims.Purpose != purpose {
+		return "", errWrongPurpose
+	}
+	return claims.Subject, nil
+}
+
+// sign signs claims with the active key and names the key in the kid
+// header.
+func sign(claims *Claims) (string, error) {
+	keys := keySet.Load()
+	if keys == nil {
+		return "", errNoKeySet
+	}
+	key, err := keys.Signer(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// parse verifies a token with the key named in its kid header. The key must
+// not be retired and must sign with the algorithm of the token, so a token
+// cannot pick a weaker algorithm than the key's.
+func parse(tokenString string, claims *Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	keys := keySet.Load()
+	if keys == nil {
+		return nil, errNoKeySet
+	}
+	opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, public, ok := keys.verifier(kid, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method().Alg() {
+			return nil, fmt.Errorf("signing key %q does not sign with %s", kid, token.Method.Alg())
+		}
+		return public, nil
+	}, opts...)
+}
+
 func ValidateJWT(tokenString string) (string, error) {
 	claims, err := ParseJWT(tokenString)
 	if err != nil {
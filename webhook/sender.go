@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"intern-project-v2/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ domain.WebhookSender = (*HTTPSender)(nil)
+
+// Headers sent with every delivery. The id is the event id and stays the
+// same across retries, so receivers can drop duplicates with it.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const sendTimeout = 10 * time.Second
+
+// Body is what receivers get posted.
+type Body struct {
+	Id         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256, keyed with
+// the subscription secret, of the timestamp, a dot and the body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature the way receivers should: in constant time and
+// only for timestamps within tolerance of now, to stop replays.
+func Verify(secret string, signature string, timestamp int64, body []byte, tolerance time.Duration) bool {
+	sent := time.Unix(timestamp, 0)
+	if time.Since(sent) > tolerance || time.Until(sent) > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// HTTPSender posts signed deliveries to subscription URLs.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender() *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: sendTimeout},
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(&Body{
+		Id:         delivery.EventID,
+		Type:       delivery.EventType,
+		OccurredAt: delivery.OccurredAt,
+		Data:       delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
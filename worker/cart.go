@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"intern-project-v2/logger"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultCartIdleHours = 24
+
+// AbandonedCartDetector reports carts left idle for longer than idle.
+type AbandonedCartDetector interface {
+	DetectAbandoned(ctx context.Context, idle time.Duration) (int, error)
+}
+
+// CartIdleFromEnv reads CART_ABANDONED_AFTER_HOURS, defaulting to 24 hours.
+func CartIdleFromEnv() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("CART_ABANDONED_AFTER_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = defaultCartIdleHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// StartAbandonedCartJob looks for abandoned carts on each tick until ctx is
+// cancelled.
+func StartAbandonedCartJob(ctx context.Context, interval, idle time.Duration, detector AbandonedCartDetector) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reported, err := detector.DetectAbandoned(ctx, idle)
+				if err != nil {
+					logger.Error("Abandoned cart job failed", "error", err)
+					continue
+				}
+				if reported > 0 {
+					logger.Info("Abandoned cart job reported carts", "count", reported)
+				}
+			}
+		}
+	}()
+}
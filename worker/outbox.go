@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"intern-project-v2/domain"
+	"intern-project-v2/logger"
+	"time"
+)
+
+const (
+	outboxBatchSize = 100
+	maxRetryDelay   = time.Hour
+)
+
+// OutboxRelay delivers the events in the outbox to every sink. An event is
+// marked published once all sinks took it; when any sink fails the whole
+// event is retried later with exponential backoff, so sinks may see it more
+// than once. After domain.MaxEventAttempts it stays in the outbox as dead.
+type OutboxRelay struct {
+	outbox domain.OutboxRepository
+	sinks  []domain.EventSink
+}
+
+func NewOutboxRelay(outbox domain.OutboxRepository, sinks ...domain.EventSink) *OutboxRelay {
+	return &OutboxRelay{
+		outbox: outbox,
+		sinks:  sinks,
+	}
+}
+
+// RelayOnce delivers one batch of due events and returns how many were
+// published.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.outbox.Pending(ctx, now, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, event := range events {
+		if err := r.deliver(ctx, event); err != nil {
+			attempts := event.Attempts + 1
+			logger.Warn("Event delivery failed", "event_id", event.Id.Hex(), "type", event.Type, "attempt", attempts, "error", err)
+			if attempts >= domain.MaxEventAttempts {
+				logger.Error("Event gave up after too many attempts", "event_id", event.Id.Hex(), "type", event.Type)
+			}
+			if markErr := r.outbox.MarkFailed(ctx, event.Id.Hex(), err.Error(), now.Add(retryDelay(attempts))); markErr != nil {
+				return published, markErr
+			}
+			continue
+		}
+		if err := r.outbox.MarkPublished(ctx, event.Id.Hex()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+func (r *OutboxRelay) deliver(ctx context.Context, event *domain.Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			logger.Warn("Sink rejected event", "sink", sink.Name(), "event_id", event.Id.Hex(), "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// retryDelay doubles from a second with every failed attempt, up to an hour.
+func retryDelay(attempts int) time.Duration {
+	if attempts > 12 {
+		return maxRetryDelay
+	}
+	return min(time.Second<<attempts, maxRetryDelay)
+}
+
+// StartOutboxRelay relays the outbox on each tick until ctx is cancelled.
+func StartOutboxRelay(ctx context.Context, interval time.Duration, relay *OutboxRelay) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				published, err := relay.RelayOnce(ctx)
+				if err != nil {
+					logger.Error("Outbox relay failed", "error", err)
+					continue
+				}
+				if published > 0 {
+					logger.Info("Outbox relay published events", "count", published)
+				}
+			}
+		}
+	}()
+}
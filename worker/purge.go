@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"intern-project-v2/logger"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultRetentionDays = 30
+
+// Purger permanently removes records soft-deleted longer than the retention.
+type Purger interface {
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// RetentionFromEnv reads SOFT_DELETE_RETENTION_DAYS, defaulting to 30 days.
+func RetentionFromEnv() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("SOFT_DELETE_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartPurgeJob runs every purger on each tick until ctx is cancelled.
+func StartPurgeJob(ctx context.Context, interval, retention time.Duration, purgers map[string]Purger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for name, purger := range purgers {
+					purged, err := purger.Purge(ctx, retention)
+					if err != nil {
+						logger.Error("Purge job failed", "collection", name, "error", err)
+						continue
+					}
+					if purged > 0 {
+						logger.Info("Purge job removed soft-deleted records", "collection", name, "count", purged)
+					}
+				}
+			}
+		}
+	}()
+}
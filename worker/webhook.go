@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"intern-project-v2/logger"
+	"time"
+)
+
+// WebhookDispatcher sends the webhook deliveries that are due.
+type WebhookDispatcher interface {
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+// StartWebhookJob sends due webhook deliveries on each tick until ctx is
+// cancelled.
+func StartWebhookJob(ctx context.Context, interval time.Duration, dispatcher WebhookDispatcher) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				delivered, err := dispatcher.DeliverPending(ctx)
+				if err != nil {
+					logger.Error("Webhook job failed", "error", err)
+					continue
+				}
+				if delivered > 0 {
+					logger.Info("Webhook job delivered events", "count", delivered)
+				}
+			}
+		}
+	}()
+}